@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"order-service/pkg/catalog"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/repository"
+	service "order-service/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+func newCatalogService(db *gorm.DB) *service.CatalogService {
+	return service.NewCatalogService(
+		db,
+		repository.NewMedicineRepository(db),
+		repository.NewTherapeuticCategoryRepository(db),
+		repository.NewMedicinePriceRepository(db),
+	)
+}
+
+// importCatalog imports a CSV or XLSX catalog file. It prints the changes per row and
+// exits with 1 when any row is invalid, in which case nothing is imported.
+func importCatalog(args []string, db *gorm.DB) int {
+	flags := flag.NewFlagSet("import-catalog", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only print the changes the import would make")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: import-catalog [--dry-run] FILE.csv|FILE.xlsx")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	format, err := catalog.FormatFromFilename(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-catalog: %v\n", err)
+		return 2
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-catalog: %v\n", err)
+		return 2
+	}
+	defer file.Close()
+
+	// imports run as the system; price changes they make have no user to attribute them to
+	res, err := newCatalogService(db).ImportCatalog(contextUtils.WithSystem(context.Background()), format, file, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import-catalog: %v\n", err)
+		return 2
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tSKU\tACTION\tCHANGES")
+	for _, row := range res.Rows {
+		fmt.Fprintf(w, "%d\t%s\t%s\t", row.Line, row.SKU, row.Action)
+		for i, change := range row.Changes {
+			if i > 0 {
+				fmt.Fprint(w, ", ")
+			}
+			fmt.Fprintf(w, "%s: %s -> %s", change.Field, formatValue(change.From), formatValue(change.To))
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+
+	if len(res.Errors) > 0 {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "LINE\tSKU\tERROR")
+		for _, rowErr := range res.Errors {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", rowErr.Line, rowErr.SKU, rowErr.Message)
+		}
+		w.Flush()
+	}
+
+	summary := res.Summary
+	fmt.Printf("%d rows: %d to create, %d to update, %d unchanged, %d with errors\n",
+		summary.Rows, summary.Created, summary.Updated, summary.Unchanged, summary.Errors)
+	switch {
+	case res.Applied:
+		fmt.Println("catalog imported")
+	case len(res.Errors) > 0:
+		fmt.Println("nothing imported because of the errors above")
+		return 1
+	default:
+		fmt.Println("dry run, nothing imported")
+	}
+	return 0
+}
+
+// exportCatalog writes the catalog to a CSV or XLSX file chosen by the file extension.
+func exportCatalog(args []string, db *gorm.DB) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: export-catalog FILE.csv|FILE.xlsx")
+		return 2
+	}
+	format, err := catalog.FormatFromFilename(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-catalog: %v\n", err)
+		return 2
+	}
+	file, err := os.Create(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export-catalog: %v\n", err)
+		return 2
+	}
+	if err := newCatalogService(db).ExportCatalog(context.Background(), file, format); err != nil {
+		file.Close()
+		fmt.Fprintf(os.Stderr, "export-catalog: %v\n", err)
+		return 2
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "export-catalog: %v\n", err)
+		return 2
+	}
+	fmt.Printf("catalog written to %s\n", args[0])
+	return 0
+}
+
+func formatValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "(empty)"
+	case *string:
+		if value == nil {
+			return "(empty)"
+		}
+		return *value
+	case *float64:
+		if value == nil {
+			return "(empty)"
+		}
+		return fmt.Sprintf("%g", *value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+// Run executes the maintenance subcommand named by args[0] and returns the process exit code.
+func Run(args []string, db *gorm.DB) int {
+	switch args[0] {
+	case "reconcile-stock":
+		return reconcileStock(db)
+	case "import-catalog":
+		return importCatalog(args[1:], db)
+	case "export-catalog":
+		return exportCatalog(args[1:], db)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		fmt.Fprintln(os.Stderr, "available commands: reconcile-stock, import-catalog, export-catalog")
+		return 2
+	}
+}
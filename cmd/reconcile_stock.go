@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"order-service/pkg/repository"
+	service "order-service/pkg/services"
+
+	"gorm.io/gorm"
+)
+
+// reconcileStock prints every medicine whose stock disagrees with the ledger or its
+// batches. It exits with 1 when drift is found so it can be used in scheduled checks.
+func reconcileStock(db *gorm.DB) int {
+	inventoryService := service.NewInventoryService(
+		db,
+		repository.NewMedicineRepository(db),
+		repository.NewMedicineBatchRepository(db),
+		repository.NewStockMovementRepository(db),
+	)
+
+	report, err := inventoryService.ReconcileStock(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconcile-stock: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("checked %d medicines at %s\n", report.Medicines, report.CheckedAt)
+	if len(report.Drifts) == 0 {
+		fmt.Println("no drift found")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MEDICINE\tID\tSTOCK\tLEDGER STOCK\tBATCHES\tRESERVED\tLEDGER RESERVED")
+	for _, drift := range report.Drifts {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n",
+			drift.MedicineName, drift.MedicineID,
+			drift.Stock, drift.LedgerStock, drift.BatchQuantity,
+			drift.Reserved, drift.LedgerReserved)
+	}
+	w.Flush()
+	fmt.Printf("%d medicines drifted\n", len(report.Drifts))
+	return 1
+}
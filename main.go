@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"embed"
 	"encoding/json"
@@ -10,16 +11,29 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"time"
 
-	// "user-service/cmd"
+	"order-service/cmd"
+	"order-service/pkg/alerts"
+	"order-service/pkg/cache"
 	"order-service/pkg/clients"
+	"order-service/pkg/clinical"
 	"order-service/pkg/config"
 	dbpkg "order-service/pkg/db"
+	"order-service/pkg/events"
 	"order-service/pkg/handlers"
+	"order-service/pkg/jobs"
 	"order-service/pkg/jwt"
+	"order-service/pkg/notifications"
+	"order-service/pkg/payments"
+	"order-service/pkg/realtime"
+	"order-service/pkg/receipt"
 	"order-service/pkg/repository"
 	"order-service/pkg/routes"
+	"order-service/pkg/scheduler"
 	service "order-service/pkg/services"
+	"order-service/pkg/tax"
+	"order-service/pkg/webhooks"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -67,7 +81,6 @@ func main() {
 	if err != nil {
 		log.Fatalf("cannot get *sql.DB from gorm: %v", err)
 	}
-	// cmd.InitCmd()
 
 	// Run migrations on start if enabled
 	if config.Get("MIGRATE_ON_START", "true") == "true" {
@@ -76,9 +89,51 @@ func main() {
 		}
 	}
 
+	// maintenance subcommands, e.g. `order-service reconcile-stock`, run instead of the server
+	if len(os.Args) > 1 {
+		os.Exit(cmd.Run(os.Args[1:], gormDB))
+	}
+
 	userServiceUrl := config.Get("USER_SERVICE_URL", "http://localhost:8000")
 	userClient := clients.NewUserClient(userServiceUrl)
+	cachedUserClient := clients.NewCachedUserClient(
+		userClient,
+		cache.NewMemoryStore(time.Duration(config.GetInt("USER_CACHE_SWEEP_INTERVAL", 600))*time.Second),
+		clients.CacheConfig{
+			TTL:         time.Duration(config.GetInt("USER_CACHE_TTL", 300)) * time.Second,
+			StaleTTL:    time.Duration(config.GetInt("USER_CACHE_STALE_TTL", 3600)) * time.Second,
+			NegativeTTL: time.Duration(config.GetInt("USER_CACHE_NEGATIVE_TTL", 60)) * time.Second,
+		},
+	)
 	appointmentClient := clients.NewAppointmentClient(config.Get("APPOINTMENT_SERVICE_URL", "http://localhost:8001"))
+	clinicalChecker, err := clinical.NewChecker(config.Get("CLINICAL_INTERACTIONS_FILE", ""))
+	if err != nil {
+		log.Fatalf("failed to load interaction table: %v", err)
+	}
+	seller := tax.Seller{
+		Name:    config.Get("SELLER_NAME", ""),
+		TaxID:   config.Get("SELLER_TAX_ID", ""),
+		Branch:  config.Get("SELLER_BRANCH", tax.HeadOfficeBranch),
+		Address: config.Get("SELLER_ADDRESS", ""),
+	}
+	if !tax.ValidTaxID(seller.TaxID) {
+		log.Printf("SELLER_TAX_ID is not a valid 13-digit tax ID; tax invoices will not meet Revenue Department requirements")
+	}
+	var receiptRenderer *receipt.Renderer
+	receiptFonts, err := receipt.LoadFonts(
+		config.Get("RECEIPT_FONT_FILE", "/usr/share/fonts/truetype/tlwg/Loma.ttf"),
+		config.Get("RECEIPT_FONT_BOLD_FILE", "/usr/share/fonts/truetype/tlwg/Loma-Bold.ttf"),
+	)
+	if err != nil {
+		log.Printf("PDF receipts are disabled: %v", err)
+	} else {
+		receiptRenderer = receipt.NewRenderer(receiptFonts)
+	}
+	// without a gateway, payments are taken and refunded at the counter
+	var paymentProvider payments.Provider = payments.NewManualProvider()
+	if url := config.Get("PAYMENT_PROVIDER_URL", ""); url != "" {
+		paymentProvider = payments.NewHTTPProvider(url, config.Get("PAYMENT_PROVIDER_API_KEY", ""))
+	}
 	jwtService := jwt.NewJwtService(
 		config.Get("JWT_SECRET", "secret"),
 		config.GetInt("JWT_TTL", 3600),
@@ -87,22 +142,120 @@ func main() {
 	// Initialize Order Service dependencies
 	orderRepository := repository.NewOrderRepository(gormDB)
 	orderItemRepository := repository.NewOrderItemRepository(gormDB)
+	orderRequestedItemRepository := repository.NewOrderRequestedItemRepository(gormDB)
 	medicineRepository := repository.NewMedicineRepository(gormDB)
+	medicineBatchRepository := repository.NewMedicineBatchRepository(gormDB)
+	medicineUnitRepository := repository.NewMedicineUnitRepository(gormDB)
+	therapeuticCategoryRepository := repository.NewTherapeuticCategoryRepository(gormDB)
+	medicinePriceRepository := repository.NewMedicinePriceRepository(gormDB)
+	stockMovementRepository := repository.NewStockMovementRepository(gormDB)
 	deliveryRepository := repository.NewDeliveryRepository(gormDB)
 	deliveryInformationRepository := repository.NewDeliveryInformationRepository(gormDB)
+	coverageRuleRepository := repository.NewCoverageRuleRepository(gormDB)
+	cancellationRuleRepository := repository.NewCancellationRuleRepository(gormDB)
+	webhookSubscriptionRepository := repository.NewWebhookSubscriptionRepository(gormDB)
+	webhookDeliveryRepository := repository.NewWebhookDeliveryRepository(gormDB)
+	notificationPreferenceRepository := repository.NewNotificationPreferenceRepository(gormDB)
+	notificationLogRepository := repository.NewNotificationLogRepository(gormDB)
+	promotionRepository := repository.NewPromotionRepository(gormDB)
+	orderDiscountRepository := repository.NewOrderDiscountRepository(gormDB)
+	taxInvoiceRepository := repository.NewTaxInvoiceRepository(gormDB)
 
 	// Initialize Services
 	orderService := service.NewOrderService(
 		gormDB,
 		orderRepository,
 		orderItemRepository,
+		orderRequestedItemRepository,
 		medicineRepository,
+		medicineUnitRepository,
+		medicinePriceRepository,
+		orderDiscountRepository,
+		taxInvoiceRepository,
 		deliveryRepository,
 		deliveryInformationRepository,
-		userClient,
+		cachedUserClient,
 		appointmentClient,
+		clinicalChecker,
+		seller,
+		service.ReceiptConfig{
+			Renderer: receiptRenderer,
+			Cache:    cache.NewMemoryStore(time.Duration(config.GetInt("RECEIPT_CACHE_SWEEP_INTERVAL", 600)) * time.Second),
+			CacheTTL: time.Duration(config.GetInt("RECEIPT_CACHE_TTL", 3600)) * time.Second,
+		},
+		paymentProvider,
+	)
+	medicineService := service.NewMedicineService(
+		gormDB,
+		medicineRepository,
+		medicineBatchRepository,
+		medicineUnitRepository,
+		therapeuticCategoryRepository,
+		medicinePriceRepository,
+	)
+	inventoryService := service.NewInventoryService(
+		gormDB,
+		medicineRepository,
+		medicineBatchRepository,
+		stockMovementRepository,
+	)
+	catalogService := service.NewCatalogService(
+		gormDB,
+		medicineRepository,
+		therapeuticCategoryRepository,
+		medicinePriceRepository,
+	)
+	coverageService := service.NewCoverageService(
+		coverageRuleRepository,
+		medicineRepository,
+	)
+	cancellationService := service.NewCancellationService(cancellationRuleRepository)
+	webhookService := service.NewWebhookService(
+		gormDB,
+		webhookSubscriptionRepository,
+		webhookDeliveryRepository,
+		webhooks.NewSender(),
+		service.WebhookConfig{
+			MaxAttempts:          config.GetInt("WEBHOOK_MAX_ATTEMPTS", 10),
+			DisableAfterFailures: config.GetInt("WEBHOOK_DISABLE_AFTER_FAILURES", 25),
+		},
+	)
+	// Channels without a provider configured go to the development sink
+	sinkChannel := func(name string) notifications.Channel {
+		if config.Get("NOTIFICATION_SINK", "log") == "file" {
+			return notifications.NewFileChannel(name, config.Get("NOTIFICATION_SINK_FILE", "notifications.jsonl"))
+		}
+		return notifications.NewLogChannel(name)
+	}
+	notificationChannels := map[string]notifications.Channel{
+		notifications.ChannelEmail: sinkChannel(notifications.ChannelEmail),
+		notifications.ChannelSMS:   sinkChannel(notifications.ChannelSMS),
+		notifications.ChannelLINE:  sinkChannel(notifications.ChannelLINE),
+	}
+	if host := config.Get("SMTP_HOST", ""); host != "" {
+		notificationChannels[notifications.ChannelEmail] = notifications.NewSMTPChannel(
+			host,
+			config.GetInt("SMTP_PORT", 587),
+			config.Get("SMTP_USERNAME", ""),
+			config.Get("SMTP_PASSWORD", ""),
+			config.Get("SMTP_FROM", "no-reply@pharmacy.local"),
+		)
+	}
+	if url := config.Get("SMS_GATEWAY_URL", ""); url != "" {
+		notificationChannels[notifications.ChannelSMS] = notifications.NewSMSChannel(url, config.Get("SMS_GATEWAY_API_KEY", ""), config.Get("SMS_SENDER", "Pharmacy"))
+	}
+	if token := config.Get("LINE_CHANNEL_ACCESS_TOKEN", ""); token != "" {
+		notificationChannels[notifications.ChannelLINE] = notifications.NewLINEChannel(token)
+	}
+	notificationService := service.NewNotificationService(
+		notificationPreferenceRepository,
+		notificationLogRepository,
+		notificationChannels,
+	)
+	promotionService := service.NewPromotionService(
+		promotionRepository,
+		medicineRepository,
 	)
-	medicineService := service.NewMedicineService(medicineRepository)
 	deliveryService := service.NewDeliveryService(
 		gormDB,
 		deliveryRepository,
@@ -111,9 +264,89 @@ func main() {
 		userClient,
 	)
 
+	// Every replica streams the order events written by any of them to its own clients
+	orderStreamService := service.NewOrderStreamService(
+		repository.NewOutboxEventRepository(gormDB),
+		realtime.NewHub(),
+		time.Duration(config.GetInt("ORDER_STREAM_POLL_INTERVAL_MS", 1000))*time.Millisecond,
+		time.Duration(config.GetInt("ORDER_STREAM_HEARTBEAT_INTERVAL", 15))*time.Second,
+	)
+	go orderStreamService.Start(context.Background())
+
+	// Scheduled jobs run on the one replica holding the scheduler lock
+	jobScheduler := scheduler.New(sqlDB, int64(config.GetInt("SCHEDULER_LOCK_KEY", 4815162342)), time.Duration(config.GetInt("SCHEDULER_LEADER_CHECK_INTERVAL", 15))*time.Second)
+	if interval := config.GetInt("LOW_STOCK_CHECK_INTERVAL", 900); interval > 0 {
+		notifiers := alerts.MultiNotifier{alerts.NewLogNotifier()}
+		if url := config.Get("LOW_STOCK_WEBHOOK_URL", ""); url != "" {
+			notifiers = append(notifiers, alerts.NewWebhookNotifier(url))
+		}
+		lowStockJob := jobs.NewLowStockJob(medicineRepository, notifiers)
+		jobScheduler.Add(scheduler.Job{Name: "low stock", Interval: time.Duration(interval) * time.Second, Run: lowStockJob.RunOnce})
+	}
+	if interval := config.GetInt("ORDER_EXPIRY_CHECK_INTERVAL", 300); interval > 0 {
+		orderExpiryJob := jobs.NewOrderExpiryJob(
+			orderService,
+			time.Duration(config.GetInt("PENDING_ORDER_TTL_DAYS", 7))*24*time.Hour,
+			time.Duration(config.GetInt("APPROVED_ORDER_TTL_HOURS", 48))*time.Hour,
+			time.Duration(config.GetInt("CHANGES_REQUESTED_ORDER_TTL_DAYS", 7))*24*time.Hour,
+		)
+		jobScheduler.Add(scheduler.Job{Name: "order expiry", Interval: time.Duration(interval) * time.Second, Run: orderExpiryJob.RunOnce})
+	}
+	if interval := config.GetInt("REFUND_PAYOUT_INTERVAL", 60); interval > 0 {
+		refundPayoutJob := jobs.NewRefundPayoutJob(
+			orderService,
+			time.Duration(config.GetInt("REFUND_PAYOUT_RETRY_AFTER", 60))*time.Second,
+			config.GetInt("REFUND_PAYOUT_BATCH_SIZE", 50),
+		)
+		jobScheduler.Add(scheduler.Job{Name: "refund payout", Interval: time.Duration(interval) * time.Second, Run: refundPayoutJob.RunOnce})
+	}
+	var eventPublisher events.Publisher
+	switch publisher := config.Get("OUTBOX_PUBLISHER", "stdout"); publisher {
+	case "stdout":
+		eventPublisher = events.NewStdoutPublisher()
+	case "webhook":
+		url := config.Get("OUTBOX_WEBHOOK_URL", "")
+		if url == "" {
+			log.Fatal("OUTBOX_WEBHOOK_URL is required when OUTBOX_PUBLISHER is webhook")
+		}
+		eventPublisher = events.NewWebhookPublisher(url)
+	case "nats":
+		natsPublisher, err := events.NewNATSPublisher(config.Get("NATS_URL", "nats://localhost:4222"), config.Get("OUTBOX_NATS_SUBJECT_PREFIX", "pharmacy"))
+		if err != nil {
+			log.Fatalf("failed to set up event publisher: %v", err)
+		}
+		defer natsPublisher.Close()
+		eventPublisher = natsPublisher
+	default:
+		log.Fatalf("unknown OUTBOX_PUBLISHER %q", publisher)
+	}
+	// partner webhooks and user notifications are fed from the outbox alongside the publisher
+	if interval := config.GetInt("OUTBOX_RELAY_INTERVAL", 5); interval > 0 {
+		outboxRelayJob := jobs.NewOutboxRelayJob(
+			gormDB,
+			events.MultiPublisher{eventPublisher, webhookService, notificationService},
+			config.GetInt("OUTBOX_BATCH_SIZE", 100),
+			config.GetInt("OUTBOX_MAX_ATTEMPTS", 10),
+		)
+		jobScheduler.Add(scheduler.Job{Name: "outbox relay", Interval: time.Duration(interval) * time.Second, Run: outboxRelayJob.RunOnce})
+	}
+	if interval := config.GetInt("WEBHOOK_DELIVERY_INTERVAL", 5); interval > 0 {
+		webhookDeliveryJob := jobs.NewWebhookDeliveryJob(webhookService, config.GetInt("WEBHOOK_BATCH_SIZE", 50))
+		jobScheduler.Add(scheduler.Job{Name: "webhook delivery", Interval: time.Duration(interval) * time.Second, Run: webhookDeliveryJob.RunOnce})
+	}
+	go jobScheduler.Start(context.Background())
+
 	// Initialize Handlers
 	orderHandler := handlers.NewOrderHandler(orderService)
+	orderStreamHandler := handlers.NewOrderStreamHandler(orderStreamService)
 	medicineHandler := handlers.NewMedicineHandler(medicineService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+	coverageHandler := handlers.NewCoverageHandler(coverageService)
+	cancellationHandler := handlers.NewCancellationHandler(cancellationService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
 	deliveryInfoHandler := handlers.NewDeliveryInfoHandler(deliveryService)
 	validate := validator.New()
 
@@ -148,7 +381,7 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	routes.SetupRoutes(app, orderHandler, medicineHandler, deliveryInfoHandler, jwtService)
+	routes.SetupRoutes(app, orderHandler, orderStreamHandler, medicineHandler, inventoryHandler, catalogHandler, coverageHandler, cancellationHandler, webhookHandler, notificationHandler, promotionHandler, deliveryInfoHandler, jwtService)
 
 	port := config.Get("APP_PORT", "8000")
 	fmt.Println("Server is running on port " + port)
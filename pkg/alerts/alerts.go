@@ -0,0 +1,44 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// LowStockAlert is raised when a medicine's available stock falls to its reorder point.
+type LowStockAlert struct {
+	MedicineID      string    `json:"medicine_id"`
+	MedicineName    string    `json:"medicine_name"`
+	Unit            string    `json:"unit"`
+	Stock           float64   `json:"stock"`
+	Reserved        float64   `json:"reserved"`
+	Available       float64   `json:"available"`
+	ReorderPoint    float64   `json:"reorder_point"`
+	ReorderQuantity *float64  `json:"reorder_quantity,omitempty"`
+	DetectedAt      time.Time `json:"detected_at"`
+}
+
+// Notifier delivers alerts to a sink.
+type Notifier interface {
+	NotifyLowStock(ctx context.Context, alert LowStockAlert) error
+}
+
+// MultiNotifier fans an alert out to every sink. All sinks are tried; their errors
+// are joined.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) NotifyLowStock(ctx context.Context, alert LowStockAlert) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.NotifyLowStock(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func formatQuantity(quantity float64, unit string) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64) + " " + unit
+}
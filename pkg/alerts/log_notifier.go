@@ -0,0 +1,25 @@
+package alerts
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier writes alerts to the standard logger.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) NotifyLowStock(ctx context.Context, alert LowStockAlert) error {
+	reorder := "no reorder quantity set"
+	if alert.ReorderQuantity != nil {
+		reorder = "reorder " + formatQuantity(*alert.ReorderQuantity, alert.Unit)
+	}
+	log.Printf("low stock: %s (%s) available %s, reorder point %s; %s",
+		alert.MedicineName, alert.MedicineID,
+		formatQuantity(alert.Available, alert.Unit), formatQuantity(alert.ReorderPoint, alert.Unit),
+		reorder)
+	return nil
+}
@@ -0,0 +1,56 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookNotifier posts each alert as JSON to a URL.
+type WebhookNotifier struct {
+	url string
+	hc  *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url: url,
+		hc: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+}
+
+type webhookPayload struct {
+	Event string        `json:"event"`
+	Alert LowStockAlert `json:"alert"`
+}
+
+func (n *WebhookNotifier) NotifyLowStock(ctx context.Context, alert LowStockAlert) error {
+	body, err := json.Marshal(webhookPayload{Event: "medicine.low_stock", Alert: alert})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send low-stock webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("low-stock webhook returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -36,6 +36,12 @@ func New(code Code, msg string, err error) *Error {
 	return &Error{Code: code, Msg: msg, Err: err}
 }
 
+// WithFields attaches structured details that are returned to the client alongside the message.
+func (e *Error) WithFields(fields map[string]any) *Error {
+	e.Fields = fields
+	return e
+}
+
 func IsCode(err error, code Code) bool {
 	var ae *Error
 	if errors.As(err, &ae) {
@@ -66,5 +72,9 @@ func WriteError(c *fiber.Ctx, err error) error {
 			status = fiber.StatusInternalServerError
 		}
 	}
-	return c.Status(status).JSON(fiber.Map{"error": msg})
+	body := fiber.Map{"error": msg}
+	if ae != nil && len(ae.Fields) > 0 {
+		body["details"] = ae.Fields
+	}
+	return c.Status(status).JSON(body)
 }
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Entry is a cached value together with its freshness metadata.
+// A Negative entry records that the upstream returned no value for the key.
+type Entry struct {
+	Value      []byte
+	Negative   bool
+	ExpiresAt  time.Time
+	StaleUntil time.Time
+}
+
+// Fresh reports whether the entry can be served without asking the upstream.
+func (e *Entry) Fresh(now time.Time) bool {
+	return now.Before(e.ExpiresAt)
+}
+
+// Usable reports whether the entry can still be served as a stale fallback.
+func (e *Entry) Usable(now time.Time) bool {
+	return now.Before(e.StaleUntil)
+}
+
+// Store is the pluggable backend used by cached clients.
+type Store interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry Entry)
+	Delete(key string)
+}
+
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]Entry
+	now   func() time.Time
+}
+
+// NewMemoryStore creates an in-process store. When sweepInterval is positive a
+// background goroutine periodically drops entries that are past their stale window.
+func NewMemoryStore(sweepInterval time.Duration) *MemoryStore {
+	s := &MemoryStore{
+		items: make(map[string]Entry),
+		now:   time.Now,
+	}
+	if sweepInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(sweepInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				s.Sweep()
+			}
+		}()
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mu.RLock()
+	entry, ok := s.items[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if !entry.Usable(s.now()) {
+		s.Delete(key)
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (s *MemoryStore) Set(key string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[key] = entry
+}
+
+func (s *MemoryStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+}
+
+// Sweep removes every entry that can no longer be served, even as stale.
+func (s *MemoryStore) Sweep() {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, entry := range s.items {
+		if !entry.Usable(now) {
+			delete(s.items, key)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryFreshness(t *testing.T) {
+	base := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	entry := Entry{ExpiresAt: base.Add(time.Minute), StaleUntil: base.Add(time.Hour)}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		fresh  bool
+		usable bool
+	}{
+		{"before expiry", base, true, true},
+		{"at expiry", base.Add(time.Minute), false, true},
+		{"stale", base.Add(30 * time.Minute), false, true},
+		{"past the stale window", base.Add(time.Hour), false, false},
+	}
+	for _, tt := range tests {
+		if got := entry.Fresh(tt.now); got != tt.fresh {
+			t.Errorf("%s: Fresh = %t, want %t", tt.name, got, tt.fresh)
+		}
+		if got := entry.Usable(tt.now); got != tt.usable {
+			t.Errorf("%s: Usable = %t, want %t", tt.name, got, tt.usable)
+		}
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(0)
+	store.now = func() time.Time { return now }
+
+	store.Set("fresh", Entry{Value: []byte("a"), ExpiresAt: now.Add(time.Minute), StaleUntil: now.Add(time.Hour)})
+	store.Set("stale", Entry{Value: []byte("b"), ExpiresAt: now.Add(-time.Minute), StaleUntil: now.Add(time.Hour)})
+	store.Set("gone", Entry{Value: []byte("c"), ExpiresAt: now.Add(-time.Hour), StaleUntil: now.Add(-time.Minute)})
+	store.Set("missing", Entry{Negative: true, ExpiresAt: now.Add(time.Minute), StaleUntil: now.Add(time.Minute)})
+
+	tests := []struct {
+		key   string
+		found bool
+		fresh bool
+		value string
+	}{
+		{key: "fresh", found: true, fresh: true, value: "a"},
+		{key: "stale", found: true, value: "b"},
+		{key: "gone"},
+		{key: "missing", found: true, fresh: true},
+		{key: "never set"},
+	}
+	for _, tt := range tests {
+		entry, ok := store.Get(tt.key)
+		if ok != tt.found {
+			t.Errorf("Get(%q) found = %t, want %t", tt.key, ok, tt.found)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if entry.Fresh(now) != tt.fresh {
+			t.Errorf("Get(%q) fresh = %t, want %t", tt.key, entry.Fresh(now), tt.fresh)
+		}
+		if string(entry.Value) != tt.value {
+			t.Errorf("Get(%q) value = %q, want %q", tt.key, entry.Value, tt.value)
+		}
+	}
+	if _, ok := store.items["gone"]; ok {
+		t.Error("Get kept an entry past its stale window")
+	}
+
+	store.Delete("fresh")
+	if _, ok := store.Get("fresh"); ok {
+		t.Error("Delete kept the entry")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(0)
+	store.now = func() time.Time { return now }
+
+	store.Set("stale", Entry{ExpiresAt: now.Add(-time.Minute), StaleUntil: now.Add(time.Minute)})
+	store.Set("gone", Entry{ExpiresAt: now.Add(-time.Hour), StaleUntil: now})
+	store.Sweep()
+
+	if _, ok := store.items["stale"]; !ok {
+		t.Error("Sweep dropped an entry that can still be served stale")
+	}
+	if _, ok := store.items["gone"]; ok {
+		t.Error("Sweep kept an entry past its stale window")
+	}
+}
@@ -0,0 +1,378 @@
+// Package catalog reads and writes the medicine catalog as CSV or XLSX spreadsheets.
+// Files have one header row naming the columns and one medicine per row; medicines
+// are matched by SKU.
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+const (
+	ColumnSKU                 = "sku"
+	ColumnName                = "name"
+	ColumnGenericName         = "generic_name"
+	ColumnBrandName           = "brand_name"
+	ColumnNameTh              = "name_th"
+	ColumnATCCode             = "atc_code"
+	ColumnStrengthValue       = "strength_value"
+	ColumnStrengthUnit        = "strength_unit"
+	ColumnUnit                = "unit"
+	ColumnPrice               = "price"
+	ColumnClassification      = "classification"
+	ColumnVATTreatment        = "vat_treatment"
+	ColumnMaxQuantityPerOrder = "max_quantity_per_order"
+	ColumnReorderPoint        = "reorder_point"
+	ColumnReorderQuantity     = "reorder_quantity"
+)
+
+// Columns lists every column in the order they are exported.
+var Columns = []string{
+	ColumnSKU,
+	ColumnName,
+	ColumnGenericName,
+	ColumnBrandName,
+	ColumnNameTh,
+	ColumnATCCode,
+	ColumnStrengthValue,
+	ColumnStrengthUnit,
+	ColumnUnit,
+	ColumnPrice,
+	ColumnClassification,
+	ColumnVATTreatment,
+	ColumnMaxQuantityPerOrder,
+	ColumnReorderPoint,
+	ColumnReorderQuantity,
+}
+
+// requiredColumns must be present in every imported file. Other columns may be left
+// out, in which case the matching fields of existing medicines are not changed.
+var requiredColumns = []string{ColumnSKU, ColumnName, ColumnUnit, ColumnPrice}
+
+const sheetName = "medicines"
+
+// Row is one medicine of a catalog file. Optional fields are nil when the cell is empty.
+type Row struct {
+	// Line is the 1-based line or spreadsheet row the medicine was read from.
+	Line                int
+	SKU                 string
+	Name                string
+	GenericName         *string
+	BrandName           *string
+	NameTh              *string
+	ATCCode             *string
+	StrengthValue       *float64
+	StrengthUnit        *string
+	Unit                string
+	Price               float64
+	Classification      string
+	VATTreatment        string
+	MaxQuantityPerOrder *float64
+	ReorderPoint        *float64
+	ReorderQuantity     *float64
+
+	columns map[string]bool
+}
+
+// Has reports whether the file the row was read from has the column. Rows built in code
+// have every column.
+func (r *Row) Has(column string) bool {
+	return r.columns == nil || r.columns[column]
+}
+
+// RowError is a problem with one row, or with one cell when Column is set.
+type RowError struct {
+	Line    int    `json:"line"`
+	SKU     string `json:"sku,omitempty"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// FormatFromFilename picks the format from the file extension.
+func FormatFromFilename(name string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatXLSX:
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("unsupported catalog format %q, use csv or xlsx", s)
+	}
+}
+
+// Read parses a catalog file. Rows with invalid cells are reported as row errors and
+// left out of the returned rows; an error is only returned when the file as a whole
+// cannot be read.
+func Read(r io.Reader, format Format) ([]Row, []RowError, error) {
+	records, err := readRecords(r, format)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, errors.New("file is empty")
+	}
+
+	header := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		column := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if column == "" {
+			continue
+		}
+		if !isColumn(column) {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		if _, ok := header[column]; ok {
+			return nil, nil, fmt.Errorf("column %q appears more than once", column)
+		}
+		header[column] = i
+	}
+	for _, column := range requiredColumns {
+		if _, ok := header[column]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", column)
+		}
+	}
+	columns := make(map[string]bool, len(header))
+	for column := range header {
+		columns[column] = true
+	}
+
+	var rows []Row
+	var rowErrors []RowError
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		p := rowParser{record: record, header: header, row: Row{Line: i + 2, columns: columns}}
+		p.row.SKU = p.required(ColumnSKU)
+		p.row.Name = p.required(ColumnName)
+		p.row.GenericName = p.text(ColumnGenericName)
+		p.row.BrandName = p.text(ColumnBrandName)
+		p.row.NameTh = p.text(ColumnNameTh)
+		if code := p.text(ColumnATCCode); code != nil {
+			upper := strings.ToUpper(*code)
+			p.row.ATCCode = &upper
+		}
+		p.row.StrengthValue = p.number(ColumnStrengthValue)
+		p.row.StrengthUnit = p.text(ColumnStrengthUnit)
+		p.row.Unit = p.required(ColumnUnit)
+		if price := p.number(ColumnPrice); price != nil {
+			p.row.Price = *price
+		} else if p.cell(ColumnPrice) == "" {
+			p.fail(ColumnPrice, "is required")
+		}
+		if classification := p.text(ColumnClassification); classification != nil {
+			p.row.Classification = strings.ToLower(*classification)
+		}
+		if treatment := p.text(ColumnVATTreatment); treatment != nil {
+			p.row.VATTreatment = strings.ToLower(*treatment)
+		}
+		p.row.MaxQuantityPerOrder = p.number(ColumnMaxQuantityPerOrder)
+		p.row.ReorderPoint = p.number(ColumnReorderPoint)
+		p.row.ReorderQuantity = p.number(ColumnReorderQuantity)
+
+		if len(p.errors) > 0 {
+			rowErrors = append(rowErrors, p.errors...)
+			continue
+		}
+		rows = append(rows, p.row)
+	}
+	return rows, rowErrors, nil
+}
+
+// Write writes the rows with a header row of every column.
+func Write(w io.Writer, format Format, rows []Row) error {
+	records := make([][]string, 0, len(rows)+1)
+	records = append(records, Columns)
+	for i := range rows {
+		records = append(records, rows[i].record())
+	}
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		return cw.Error()
+	case FormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+		if err := f.SetSheetName(f.GetSheetName(0), sheetName); err != nil {
+			return err
+		}
+		for i, record := range records {
+			cells := make([]interface{}, len(record))
+			for j, value := range record {
+				cells[j] = value
+			}
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			if err != nil {
+				return err
+			}
+			if err := f.SetSheetRow(sheetName, cell, &cells); err != nil {
+				return err
+			}
+		}
+		return f.Write(w)
+	default:
+		return fmt.Errorf("unsupported catalog format %q", format)
+	}
+}
+
+func (r *Row) record() []string {
+	return []string{
+		r.SKU,
+		r.Name,
+		formatText(r.GenericName),
+		formatText(r.BrandName),
+		formatText(r.NameTh),
+		formatText(r.ATCCode),
+		formatNumber(r.StrengthValue),
+		formatText(r.StrengthUnit),
+		r.Unit,
+		strconv.FormatFloat(r.Price, 'f', -1, 64),
+		r.Classification,
+		r.VATTreatment,
+		formatNumber(r.MaxQuantityPerOrder),
+		formatNumber(r.ReorderPoint),
+		formatNumber(r.ReorderQuantity),
+	}
+}
+
+func readRecords(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("read csv: %w", err)
+		}
+		return records, nil
+	case FormatXLSX:
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("read xlsx: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		records, err := f.GetRows(sheets[0])
+		if err != nil {
+			return nil, fmt.Errorf("read xlsx: %w", err)
+		}
+		return records, nil
+	default:
+		return nil, fmt.Errorf("unsupported catalog format %q", format)
+	}
+}
+
+type rowParser struct {
+	record []string
+	header map[string]int
+	row    Row
+	errors []RowError
+}
+
+func (p *rowParser) cell(column string) string {
+	i, ok := p.header[column]
+	if !ok || i >= len(p.record) {
+		return ""
+	}
+	return strings.TrimSpace(p.record[i])
+}
+
+func (p *rowParser) fail(column, message string) {
+	p.errors = append(p.errors, RowError{
+		Line:    p.row.Line,
+		SKU:     p.cell(ColumnSKU),
+		Column:  column,
+		Message: column + " " + message,
+	})
+}
+
+func (p *rowParser) required(column string) string {
+	value := p.cell(column)
+	if value == "" {
+		p.fail(column, "is required")
+	}
+	return value
+}
+
+func (p *rowParser) text(column string) *string {
+	value := p.cell(column)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func (p *rowParser) number(column string) *float64 {
+	value := p.cell(column)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+	if err != nil {
+		p.fail(column, "must be a number")
+		return nil
+	}
+	if n < 0 {
+		p.fail(column, "must not be negative")
+		return nil
+	}
+	return &n
+}
+
+func isColumn(name string) bool {
+	for _, column := range Columns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func formatText(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatNumber(n *float64) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*n, 'f', -1, 64)
+}
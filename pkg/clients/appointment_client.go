@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	client_dto "order-service/pkg/clients/dto"
@@ -12,6 +13,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrNotFound is returned when the appointment service has no such resource.
+var ErrNotFound = errors.New("not found")
+
 type AppointmentClient struct {
 	baseUrl string
 	hc      *http.Client
@@ -71,6 +75,9 @@ func (c *AppointmentClient) doRequest(ctx context.Context, method, path string,
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, ErrNotFound)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -93,3 +100,23 @@ func (c *AppointmentClient) GetLatestAppointmentByPatientID(ctx context.Context,
 
 	return &appointment, nil
 }
+
+func (c *AppointmentClient) GetAppointmentByID(ctx context.Context, appointmentID uuid.UUID) (*client_dto.GetAppointmentResponseDto, error) {
+	var appointment client_dto.GetAppointmentResponseDto
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/appointments/"+appointmentID.String(), nil, &appointment); err != nil {
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+// GetMyAppointmentHistory lists the appointments of the patient whose token the request
+// carries.
+func (c *AppointmentClient) GetMyAppointmentHistory(ctx context.Context) ([]client_dto.GetAppointmentResponseDto, error) {
+	var appointments []client_dto.GetAppointmentResponseDto
+	if err := c.doRequest(ctx, http.MethodGet, "/v1/patient/history", nil, &appointments); err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
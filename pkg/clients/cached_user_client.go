@@ -0,0 +1,160 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"order-service/pkg/cache"
+	client_dto "order-service/pkg/clients/dto"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const patientCachePrefix = "patient:"
+
+type CacheConfig struct {
+	TTL         time.Duration // how long an entry is served without asking the user service
+	StaleTTL    time.Duration // how long past TTL an entry may be served while the user service is down
+	NegativeTTL time.Duration // how long an unknown ID is remembered as missing
+}
+
+// CachedProfile wraps a profile served from the cache. Stale is set when the
+// user service could not be reached and an expired entry was served instead.
+type CachedProfile[T any] struct {
+	Profile T
+	Stale   bool
+}
+
+// CachedUserClient puts a TTL cache with request coalescing in front of UserClient.
+// Methods that are not cached fall through to the embedded client.
+type CachedUserClient struct {
+	*UserClient
+	store cache.Store
+	cfg   CacheConfig
+	group singleflight.Group
+	now   func() time.Time
+}
+
+func NewCachedUserClient(client *UserClient, store cache.Store, cfg CacheConfig) *CachedUserClient {
+	return &CachedUserClient{
+		UserClient: client,
+		store:      store,
+		cfg:        cfg,
+		now:        time.Now,
+	}
+}
+
+// GetPatientProfiles returns the profiles that could be resolved, keyed by patient ID.
+// A non-nil error means the user service failed; the map still holds every profile
+// that could be served from the cache, possibly marked stale.
+func (c *CachedUserClient) GetPatientProfiles(ctx context.Context, patientIDs []string) (map[string]CachedProfile[client_dto.GetPatientProfileResponseDto], error) {
+	return lookupProfiles(ctx, c, patientCachePrefix, patientIDs, c.UserClient.GetPatientByIds,
+		func(p client_dto.GetPatientProfileResponseDto) string { return p.ID })
+}
+
+func lookupProfiles[T any](
+	ctx context.Context,
+	c *CachedUserClient,
+	prefix string,
+	ids []string,
+	fetch func(context.Context, []string) (*[]T, error),
+	idOf func(T) string,
+) (map[string]CachedProfile[T], error) {
+	now := c.now()
+	result := make(map[string]CachedProfile[T], len(ids))
+	expired := make(map[string]*cache.Entry)
+	seen := make(map[string]bool, len(ids))
+	missing := []string{}
+
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		entry, ok := c.store.Get(prefix + id)
+		if ok && entry.Fresh(now) {
+			if entry.Negative {
+				continue
+			}
+			var profile T
+			if err := json.Unmarshal(entry.Value, &profile); err == nil {
+				result[id] = CachedProfile[T]{Profile: profile}
+				continue
+			}
+		}
+		if ok {
+			expired[id] = entry
+		}
+		missing = append(missing, id)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	// identical batches requested concurrently share a single upstream call
+	sort.Strings(missing)
+	v, err, _ := c.group.Do(prefix+strings.Join(missing, ","), func() (interface{}, error) {
+		profiles, err := fetch(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		fetched := make(map[string]T)
+		if profiles != nil {
+			for _, profile := range *profiles {
+				fetched[idOf(profile)] = profile
+			}
+		}
+		storeProfiles(c, prefix, missing, fetched)
+		return fetched, nil
+	})
+	if err != nil {
+		// serve whatever we still have while the user service is unavailable
+		for _, id := range missing {
+			entry, ok := expired[id]
+			if !ok || entry.Negative {
+				continue
+			}
+			var profile T
+			if json.Unmarshal(entry.Value, &profile) == nil {
+				result[id] = CachedProfile[T]{Profile: profile, Stale: true}
+			}
+		}
+		return result, err
+	}
+
+	for _, id := range missing {
+		if profile, ok := v.(map[string]T)[id]; ok {
+			result[id] = CachedProfile[T]{Profile: profile}
+		}
+	}
+	return result, nil
+}
+
+func storeProfiles[T any](c *CachedUserClient, prefix string, requested []string, fetched map[string]T) {
+	now := c.now()
+	for _, id := range requested {
+		profile, ok := fetched[id]
+		if !ok {
+			c.store.Set(prefix+id, cache.Entry{
+				Negative:   true,
+				ExpiresAt:  now.Add(c.cfg.NegativeTTL),
+				StaleUntil: now.Add(c.cfg.NegativeTTL),
+			})
+			continue
+		}
+		value, err := json.Marshal(profile)
+		if err != nil {
+			continue
+		}
+		c.store.Set(prefix+id, cache.Entry{
+			Value:      value,
+			ExpiresAt:  now.Add(c.cfg.TTL),
+			StaleUntil: now.Add(c.cfg.TTL + c.cfg.StaleTTL),
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"order-service/pkg/cache"
+	client_dto "order-service/pkg/clients/dto"
+)
+
+type patientProfile = client_dto.GetPatientProfileResponseDto
+
+// mapStore keeps entries until they are deleted, leaving expiry to lookupProfiles.
+type mapStore map[string]cache.Entry
+
+func (s mapStore) Get(key string) (*cache.Entry, bool) {
+	entry, ok := s[key]
+	return &entry, ok
+}
+
+func (s mapStore) Set(key string, entry cache.Entry) { s[key] = entry }
+
+func (s mapStore) Delete(key string) { delete(s, key) }
+
+var testCacheConfig = CacheConfig{TTL: time.Minute, StaleTTL: time.Hour, NegativeTTL: 30 * time.Second}
+
+func newTestCachedClient(store cache.Store, now time.Time) *CachedUserClient {
+	c := NewCachedUserClient(nil, store, testCacheConfig)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func profileEntry(t *testing.T, profile patientProfile, expiresAt time.Time) cache.Entry {
+	t.Helper()
+	value, err := json.Marshal(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cache.Entry{Value: value, ExpiresAt: expiresAt, StaleUntil: expiresAt.Add(testCacheConfig.StaleTTL)}
+}
+
+func patientID(p patientProfile) string { return p.ID }
+
+func TestLookupProfiles(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	alice := patientProfile{ID: "p1", FirstName: "Alice"}
+	bob := patientProfile{ID: "p2", FirstName: "Bob"}
+	errDown := errors.New("user service down")
+
+	tests := []struct {
+		name string
+		// cached entries before the lookup
+		cached map[string]cache.Entry
+		ids    []string
+		// upstream response; fetchErr fails the call
+		upstream []patientProfile
+		fetchErr error
+		// IDs the upstream must be asked for, nil when it must not be called
+		wantFetched []string
+		want        map[string]CachedProfile[patientProfile]
+		wantErr     error
+		// entries the lookup must leave negative
+		wantNegative []string
+	}{
+		{
+			name:   "fresh hit",
+			cached: map[string]cache.Entry{"patient:p1": profileEntry(t, alice, now.Add(time.Minute))},
+			ids:    []string{"p1"},
+			want:   map[string]CachedProfile[patientProfile]{"p1": {Profile: alice}},
+		},
+		{
+			name:        "miss is fetched",
+			ids:         []string{"p1", "p2", "p1"},
+			upstream:    []patientProfile{alice, bob},
+			wantFetched: []string{"p1", "p2"},
+			want:        map[string]CachedProfile[patientProfile]{"p1": {Profile: alice}, "p2": {Profile: bob}},
+		},
+		{
+			name:        "expired entry is refreshed",
+			cached:      map[string]cache.Entry{"patient:p1": profileEntry(t, patientProfile{ID: "p1", FirstName: "Old"}, now.Add(-time.Second))},
+			ids:         []string{"p1"},
+			upstream:    []patientProfile{alice},
+			wantFetched: []string{"p1"},
+			want:        map[string]CachedProfile[patientProfile]{"p1": {Profile: alice}},
+		},
+		{
+			name: "stale served on upstream error",
+			cached: map[string]cache.Entry{
+				"patient:p1": profileEntry(t, alice, now.Add(-time.Second)),
+				"patient:p2": profileEntry(t, bob, now.Add(time.Minute)),
+			},
+			ids:         []string{"p1", "p2", "p3"},
+			fetchErr:    errDown,
+			wantFetched: []string{"p1", "p3"},
+			want:        map[string]CachedProfile[patientProfile]{"p1": {Profile: alice, Stale: true}, "p2": {Profile: bob}},
+			wantErr:     errDown,
+		},
+		{
+			name:         "unknown ID is cached as negative",
+			ids:          []string{"p1", "p9"},
+			upstream:     []patientProfile{alice},
+			wantFetched:  []string{"p1", "p9"},
+			want:         map[string]CachedProfile[patientProfile]{"p1": {Profile: alice}},
+			wantNegative: []string{"p9"},
+		},
+		{
+			name:   "fresh negative entry is not fetched again",
+			cached: map[string]cache.Entry{"patient:p9": {Negative: true, ExpiresAt: now.Add(time.Second), StaleUntil: now.Add(time.Second)}},
+			ids:    []string{"p9"},
+			want:   map[string]CachedProfile[patientProfile]{},
+		},
+		{
+			name:        "expired negative entry is not served on upstream error",
+			cached:      map[string]cache.Entry{"patient:p9": {Negative: true, ExpiresAt: now.Add(-time.Second), StaleUntil: now.Add(time.Hour)}},
+			ids:         []string{"p9"},
+			fetchErr:    errDown,
+			wantFetched: []string{"p9"},
+			want:        map[string]CachedProfile[patientProfile]{},
+			wantErr:     errDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := mapStore{}
+			for key, entry := range tt.cached {
+				store[key] = entry
+			}
+			c := newTestCachedClient(store, now)
+
+			var fetched []string
+			fetch := func(_ context.Context, ids []string) (*[]patientProfile, error) {
+				if fetched != nil {
+					t.Error("upstream called more than once")
+				}
+				fetched = append([]string{}, ids...)
+				if tt.fetchErr != nil {
+					return nil, tt.fetchErr
+				}
+				profiles := append([]patientProfile{}, tt.upstream...)
+				return &profiles, nil
+			}
+
+			got, err := lookupProfiles(context.Background(), c, patientCachePrefix, tt.ids, fetch, patientID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !equalStrings(fetched, tt.wantFetched) {
+				t.Errorf("fetched %v, want %v", fetched, tt.wantFetched)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got %d profiles, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("profile %s = %+v, want %+v", id, got[id], want)
+				}
+			}
+			for _, id := range tt.wantNegative {
+				if entry, ok := store[patientCachePrefix+id]; !ok || !entry.Negative {
+					t.Errorf("%s is not cached as negative", id)
+				} else if !entry.ExpiresAt.Equal(now.Add(testCacheConfig.NegativeTTL)) {
+					t.Errorf("%s negative entry expires at %s", id, entry.ExpiresAt)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupProfilesCollapsesConcurrentMisses(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	c := newTestCachedClient(&lockedStore{store: mapStore{}}, now)
+
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fetch := func(_ context.Context, ids []string) (*[]patientProfile, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return &[]patientProfile{{ID: "p1"}, {ID: "p2"}}, nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([]map[string]CachedProfile[patientProfile], callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// the same batch in a different order still shares the call
+			ids := []string{"p1", "p2"}
+			if i%2 == 1 {
+				ids = []string{"p2", "p1"}
+			}
+			results[i], _ = lookupProfiles(context.Background(), c, patientCachePrefix, ids, fetch, patientID)
+		}(i)
+	}
+	<-started
+	// give the other callers time to join the call in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("upstream called %d times, want 1", got)
+	}
+	for i, result := range results {
+		if len(result) != 2 {
+			t.Errorf("caller %d got %d profiles, want 2", i, len(result))
+		}
+	}
+}
+
+// lockedStore makes a mapStore safe for concurrent lookups.
+type lockedStore struct {
+	mu    sync.Mutex
+	store mapStore
+}
+
+func (s *lockedStore) Get(key string) (*cache.Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store.Get(key)
+}
+
+func (s *lockedStore) Set(key string, entry cache.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store.Set(key, entry)
+}
+
+func (s *lockedStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store.Delete(key)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
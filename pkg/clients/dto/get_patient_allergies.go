@@ -0,0 +1,6 @@
+package client_dto
+
+type GetPatientAllergiesResponseDto struct {
+	PatientID string  `json:"patient_id"`
+	Allergies *string `json:"allergies"`
+}
@@ -0,0 +1,6 @@
+package client_dto
+
+type GetPatientEntitlementsResponseDto struct {
+	PatientID              string   `json:"patient_id"`
+	HealthcareEntitlements []string `json:"healthcare_entitlements"`
+}
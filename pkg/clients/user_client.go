@@ -116,3 +116,21 @@ func (c *UserClient) GetPatientByIds(ctx context.Context, patientIDs []string) (
 
 	return &patientProfiles, nil
 }
+
+func (c *UserClient) GetPatientAllergies(ctx context.Context, patientID string) (*client_dto.GetPatientAllergiesResponseDto, error) {
+	var allergies client_dto.GetPatientAllergiesResponseDto
+	if err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("/v1/patients/%s/allergies", patientID), nil, &allergies); err != nil {
+		return nil, err
+	}
+
+	return &allergies, nil
+}
+
+func (c *UserClient) GetPatientEntitlements(ctx context.Context, patientID string) (*client_dto.GetPatientEntitlementsResponseDto, error) {
+	var entitlements client_dto.GetPatientEntitlementsResponseDto
+	if err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("/v1/patients/%s/entitlements", patientID), nil, &entitlements); err != nil {
+		return nil, err
+	}
+
+	return &entitlements, nil
+}
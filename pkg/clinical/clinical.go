@@ -0,0 +1,210 @@
+package clinical
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed data/interactions.json
+var defaultTable []byte
+
+type Severity string
+
+const (
+	SeverityMinor           Severity = "minor"
+	SeverityModerate        Severity = "moderate"
+	SeverityMajor           Severity = "major"
+	SeverityContraindicated Severity = "contraindicated"
+)
+
+type FindingKind string
+
+const (
+	FindingKindInteraction        FindingKind = "interaction"
+	FindingKindAllergy            FindingKind = "allergy"
+	FindingKindAllergyUnavailable FindingKind = "allergy_check_unavailable"
+)
+
+type Interaction struct {
+	A        string   `json:"a"`
+	B        string   `json:"b"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+type Table struct {
+	Interactions    []Interaction       `json:"interactions"`
+	AllergenClasses map[string][]string `json:"allergen_classes"`
+}
+
+type Finding struct {
+	Kind      FindingKind `json:"kind"`
+	Severity  Severity    `json:"severity"`
+	Medicines []string    `json:"medicines"`
+	Message   string      `json:"message"`
+}
+
+// Blocking findings prevent the order from being approved at all.
+func (f Finding) Blocking() bool {
+	return f.Severity == SeverityContraindicated
+}
+
+// RequiresOverride findings can only be approved with an explicit override reason.
+func (f Finding) RequiresOverride() bool {
+	return f.Severity == SeverityMajor
+}
+
+// Checker runs interaction and allergy checks against an interaction table. The table
+// starts from the embedded default and can be replaced from a JSON file at runtime.
+type Checker struct {
+	mu              sync.RWMutex
+	path            string
+	interactions    map[string]Interaction
+	allergenClasses map[string][]string
+}
+
+// NewChecker loads the embedded table, or the file at path when one is given.
+func NewChecker(path string) (*Checker, error) {
+	c := &Checker{path: path}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload re-reads the interaction table from the configured file, falling back to the
+// embedded table when no file is configured.
+func (c *Checker) Reload() error {
+	raw := defaultTable
+	if c.path != "" {
+		b, err := os.ReadFile(c.path)
+		if err != nil {
+			return fmt.Errorf("read interaction table: %w", err)
+		}
+		raw = b
+	}
+
+	var table Table
+	if err := json.Unmarshal(raw, &table); err != nil {
+		return fmt.Errorf("parse interaction table: %w", err)
+	}
+
+	interactions := make(map[string]Interaction, len(table.Interactions))
+	for _, interaction := range table.Interactions {
+		switch interaction.Severity {
+		case SeverityMinor, SeverityModerate, SeverityMajor, SeverityContraindicated:
+		default:
+			return fmt.Errorf("interaction %s/%s: unknown severity %q", interaction.A, interaction.B, interaction.Severity)
+		}
+		interactions[pairKey(interaction.A, interaction.B)] = interaction
+	}
+	allergenClasses := make(map[string][]string, len(table.AllergenClasses))
+	for class, members := range table.AllergenClasses {
+		normalized := make([]string, len(members))
+		for i, member := range members {
+			normalized[i] = normalize(member)
+		}
+		allergenClasses[normalize(class)] = normalized
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = interactions
+	c.allergenClasses = allergenClasses
+	return nil
+}
+
+// Check looks for interactions between every pair of medicines and for medicines the
+// patient is allergic to. A nil allergies value means the allergy record could not be
+// retrieved, which is reported as a finding of its own.
+func (c *Checker) Check(medicines []string, allergies *string) []Finding {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	findings := []Finding{}
+	for i := 0; i < len(medicines); i++ {
+		for j := i + 1; j < len(medicines); j++ {
+			interaction, ok := c.interactions[pairKey(medicines[i], medicines[j])]
+			if !ok {
+				continue
+			}
+			findings = append(findings, Finding{
+				Kind:      FindingKindInteraction,
+				Severity:  interaction.Severity,
+				Medicines: []string{medicines[i], medicines[j]},
+				Message:   interaction.Message,
+			})
+		}
+	}
+
+	if allergies == nil {
+		findings = append(findings, Finding{
+			Kind:     FindingKindAllergyUnavailable,
+			Severity: SeverityModerate,
+			Message:  "patient allergy record could not be retrieved; check allergies manually",
+		})
+		return findings
+	}
+
+	for _, allergen := range splitAllergies(*allergies) {
+		for _, medicine := range medicines {
+			if c.matchesAllergen(normalize(medicine), allergen) {
+				findings = append(findings, Finding{
+					Kind:      FindingKindAllergy,
+					Severity:  SeverityMajor,
+					Medicines: []string{medicine},
+					Message:   fmt.Sprintf("patient has a recorded allergy to %q", allergen),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func (c *Checker) matchesAllergen(medicine, allergen string) bool {
+	if medicine == allergen || strings.Contains(medicine, allergen) || strings.Contains(allergen, medicine) {
+		return true
+	}
+	for class, members := range c.allergenClasses {
+		if !strings.Contains(allergen, class) {
+			continue
+		}
+		for _, member := range members {
+			if member == medicine {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitAllergies(allergies string) []string {
+	fields := strings.FieldsFunc(allergies, func(r rune) bool {
+		return r == ',' || r == ';' || r == '\n' || r == '/'
+	})
+	result := []string{}
+	for _, field := range fields {
+		field = normalize(field)
+		switch field {
+		case "", "-", "none", "no", "nkda", "n/a", "ไม่มี":
+			continue
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+func pairKey(a, b string) string {
+	pair := []string{normalize(a), normalize(b)}
+	sort.Strings(pair)
+	return pair[0] + "|" + pair[1]
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
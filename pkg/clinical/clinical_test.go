@@ -0,0 +1,167 @@
+package clinical
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestFindingSeverity(t *testing.T) {
+	tests := []struct {
+		severity         Severity
+		blocking         bool
+		requiresOverride bool
+	}{
+		{SeverityMinor, false, false},
+		{SeverityModerate, false, false},
+		{SeverityMajor, false, true},
+		{SeverityContraindicated, true, false},
+	}
+	for _, tt := range tests {
+		finding := Finding{Severity: tt.severity}
+		if got := finding.Blocking(); got != tt.blocking {
+			t.Errorf("%s: Blocking = %t, want %t", tt.severity, got, tt.blocking)
+		}
+		if got := finding.RequiresOverride(); got != tt.requiresOverride {
+			t.Errorf("%s: RequiresOverride = %t, want %t", tt.severity, got, tt.requiresOverride)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	checker, err := NewChecker("")
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+
+	type want struct {
+		kind      FindingKind
+		severity  Severity
+		medicines []string
+	}
+	tests := []struct {
+		name      string
+		medicines []string
+		allergies *string
+		want      []want
+	}{
+		{
+			name:      "no interactions or allergies",
+			medicines: []string{"paracetamol", "cetirizine"},
+			allergies: strPtr(""),
+		},
+		{
+			name:      "major interaction, any order and case",
+			medicines: []string{" Aspirin", "IBUPROFEN "},
+			allergies: strPtr("none"),
+			want:      []want{{FindingKindInteraction, SeverityMajor, []string{" Aspirin", "IBUPROFEN "}}},
+		},
+		{
+			name:      "contraindicated interaction",
+			medicines: []string{"nitroglycerin", "sildenafil"},
+			allergies: strPtr("NKDA"),
+			want:      []want{{FindingKindInteraction, SeverityContraindicated, []string{"nitroglycerin", "sildenafil"}}},
+		},
+		{
+			name:      "every pair is checked",
+			medicines: []string{"ibuprofen", "aspirin", "lisinopril"},
+			allergies: strPtr("-"),
+			want: []want{
+				{FindingKindInteraction, SeverityMajor, []string{"ibuprofen", "aspirin"}},
+				{FindingKindInteraction, SeverityModerate, []string{"ibuprofen", "lisinopril"}},
+				{FindingKindInteraction, SeverityMinor, []string{"aspirin", "lisinopril"}},
+			},
+		},
+		{
+			name:      "allergy record unavailable",
+			medicines: []string{"warfarin", "aspirin"},
+			want: []want{
+				{FindingKindInteraction, SeverityMajor, []string{"warfarin", "aspirin"}},
+				{FindingKindAllergyUnavailable, SeverityModerate, nil},
+			},
+		},
+		{
+			name:      "allergy named inside the medicine name",
+			medicines: []string{"Amoxicillin 500 mg"},
+			allergies: strPtr("amoxicillin"),
+			want:      []want{{FindingKindAllergy, SeverityMajor, []string{"Amoxicillin 500 mg"}}},
+		},
+		{
+			name:      "medicine named inside the allergy note",
+			medicines: []string{"ibuprofen"},
+			allergies: strPtr("ibuprofen (rash)"),
+			want:      []want{{FindingKindAllergy, SeverityMajor, []string{"ibuprofen"}}},
+		},
+		{
+			name:      "allergen class",
+			medicines: []string{"cloxacillin", "paracetamol"},
+			allergies: strPtr("Penicillin allergy"),
+			want:      []want{{FindingKindAllergy, SeverityMajor, []string{"cloxacillin"}}},
+		},
+		{
+			name:      "several allergies",
+			medicines: []string{"co-trimoxazole", "naproxen"},
+			allergies: strPtr("sulfa drugs; NSAIDs / latex"),
+			want: []want{
+				{FindingKindAllergy, SeverityMajor, []string{"co-trimoxazole"}},
+				{FindingKindAllergy, SeverityMajor, []string{"naproxen"}},
+			},
+		},
+		{
+			name:      "no known allergies in Thai",
+			medicines: []string{"amoxicillin"},
+			allergies: strPtr("ไม่มี"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := checker.Check(tt.medicines, tt.allergies)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("got %d findings, want %d: %+v", len(findings), len(tt.want), findings)
+			}
+			for i, w := range tt.want {
+				got := findings[i]
+				if got.Kind != w.kind || got.Severity != w.severity || !reflect.DeepEqual(got.Medicines, w.medicines) {
+					t.Errorf("finding %d = %s %s %q, want %s %s %q", i, got.Kind, got.Severity, got.Medicines, w.kind, w.severity, w.medicines)
+				}
+				if got.Message == "" {
+					t.Errorf("finding %d has no message", i)
+				}
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interactions.json")
+	write := func(table string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(table), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"interactions":[{"a":"drug a","b":"drug b","severity":"contraindicated","message":"do not combine"}]}`)
+	checker, err := NewChecker(path)
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+	if findings := checker.Check([]string{"drug b", "drug a"}, strPtr("")); len(findings) != 1 || !findings[0].Blocking() {
+		t.Errorf("findings from the file = %+v", findings)
+	}
+	if findings := checker.Check([]string{"ibuprofen", "aspirin"}, strPtr("")); len(findings) != 0 {
+		t.Errorf("the embedded table is still used: %+v", findings)
+	}
+
+	// a broken table is rejected and the loaded one kept
+	write(`{"interactions":[{"a":"drug a","b":"drug b","severity":"severe","message":"unknown severity"}]}`)
+	if err := checker.Reload(); err == nil {
+		t.Error("Reload accepted an unknown severity")
+	}
+	if findings := checker.Check([]string{"drug a", "drug b"}, strPtr("")); len(findings) != 1 {
+		t.Errorf("failed reload replaced the table: %+v", findings)
+	}
+}
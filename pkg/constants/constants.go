@@ -5,6 +5,7 @@ const (
 	RoleAdmin   = "admin"
 	RoleDoctor  = "doctor"
 	RolePatient = "patient"
+	RoleStaff   = "staff"
 
 	// Error messages
 	ErrUnuserorized = "unuserorized access"
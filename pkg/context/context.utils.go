@@ -33,15 +33,18 @@ func WithBody[T any]() fiber.Handler {
 }
 
 func GetUserId(c context.Context) string {
-	return c.Value(ContextKeyUserID).(string)
+	value, _ := c.Value(ContextKeyUserID).(string)
+	return value
 }
 
 func GetRole(c context.Context) string {
-	return c.Value(ContextKeyRole).(string)
+	value, _ := c.Value(ContextKeyRole).(string)
+	return value
 }
 
 func GetAccessToken(c context.Context) string {
-	return c.Value(ContextKeyAccessToken).(string)
+	value, _ := c.Value(ContextKeyAccessToken).(string)
+	return value
 }
 
 func GetContext(c *fiber.Ctx) context.Context {
@@ -61,3 +64,11 @@ func GetContext(c *fiber.Ctx) context.Context {
 
 	return ctx
 }
+
+// WithSystem marks ctx as work done by the service itself rather than by a user, so
+// background jobs can call code that reads the user from the context.
+func WithSystem(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyUserID, "")
+	ctx = context.WithValue(ctx, ContextKeyRole, "")
+	return context.WithValue(ctx, ContextKeyAccessToken, "")
+}
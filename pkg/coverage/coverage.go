@@ -0,0 +1,130 @@
+// Package coverage splits order totals between a patient's healthcare entitlement and
+// the patient.
+package coverage
+
+import (
+	"math"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Item is an order item priced after discounts.
+type Item struct {
+	OrderItemID uuid.UUID
+	MedicineID  uuid.UUID
+	Amount      float64
+}
+
+// Items prices the order items net of the discounts given on each of them.
+func Items(orderItems []models.OrderItem, discounts []models.OrderDiscount) []Item {
+	discountByItem := make(map[uuid.UUID]float64)
+	for _, discount := range discounts {
+		discountByItem[discount.OrderItemID] += discount.Amount
+	}
+	items := make([]Item, len(orderItems))
+	for i := range orderItems {
+		item := &orderItems[i]
+		items[i] = Item{
+			OrderItemID: item.ID,
+			MedicineID:  item.MedicineID,
+			Amount:      round(math.Max(round(item.LineTotal())-discountByItem[item.ID], 0)),
+		}
+	}
+	return items
+}
+
+// Line is the coverage of one order item.
+type Line struct {
+	OrderItemID   uuid.UUID
+	RuleID        *uuid.UUID
+	LineTotal     float64
+	InsurerAmount float64
+	PatientAmount float64
+}
+
+// Result is how an order is split under one entitlement. Entitlement is empty when no
+// entitlement covers any part of the order.
+type Result struct {
+	Entitlement   string
+	Lines         []Line
+	InsurerAmount float64
+	PatientAmount float64
+}
+
+// Calculate splits the items under one entitlement's rules. A medicine's own rule is
+// used when there is one, the entitlement's default rule otherwise; items without a rule
+// or under an excluded rule are paid by the patient. The insurer pays the covered
+// percentage of a line, less the copay the patient always pays, up to what is left of
+// the rule's annual cap. used holds what each rule has already paid this year and is
+// updated as lines are covered.
+func Calculate(entitlement string, items []Item, rules []models.CoverageRule, used map[uuid.UUID]float64) Result {
+	var defaultRule *models.CoverageRule
+	byMedicine := make(map[uuid.UUID]*models.CoverageRule)
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Entitlement != entitlement {
+			continue
+		}
+		if rule.MedicineID == nil {
+			defaultRule = rule
+		} else {
+			byMedicine[*rule.MedicineID] = rule
+		}
+	}
+
+	result := Result{Entitlement: entitlement}
+	for i := range items {
+		item := &items[i]
+		line := Line{OrderItemID: item.OrderItemID, LineTotal: item.Amount}
+
+		rule, ok := byMedicine[item.MedicineID]
+		if !ok {
+			rule = defaultRule
+		}
+		if rule != nil && !rule.Excluded {
+			ruleID := rule.ID
+			line.RuleID = &ruleID
+			insurer := line.LineTotal * rule.CoveredPercent / 100
+			insurer = math.Min(insurer, line.LineTotal-rule.Copay)
+			if rule.AnnualCap != nil {
+				insurer = math.Min(insurer, *rule.AnnualCap-used[rule.ID])
+			}
+			line.InsurerAmount = round(math.Max(insurer, 0))
+			used[rule.ID] += line.InsurerAmount
+		}
+		line.PatientAmount = round(line.LineTotal - line.InsurerAmount)
+
+		result.Lines = append(result.Lines, line)
+		result.InsurerAmount += line.InsurerAmount
+		result.PatientAmount += line.PatientAmount
+	}
+	result.InsurerAmount = round(result.InsurerAmount)
+	result.PatientAmount = round(result.PatientAmount)
+	if result.InsurerAmount == 0 {
+		result.Entitlement = ""
+	}
+	return result
+}
+
+// Best calculates the split under each of the patient's entitlements and returns the one
+// that leaves the patient the least to pay. Ties go to the entitlement listed first.
+func Best(entitlements []string, items []Item, rules []models.CoverageRule, used map[uuid.UUID]float64) Result {
+	best := Calculate("", items, nil, map[uuid.UUID]float64{})
+	for _, entitlement := range entitlements {
+		trial := make(map[uuid.UUID]float64, len(used))
+		for id, amount := range used {
+			trial[id] = amount
+		}
+		result := Calculate(entitlement, items, rules, trial)
+		if result.PatientAmount < best.PatientAmount {
+			best = result
+		}
+	}
+	return best
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS appointment_id uuid;
+
+CREATE INDEX IF NOT EXISTS idx_orders_appointment
+  ON orders (appointment_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_orders_appointment;
+ALTER TABLE orders DROP COLUMN IF EXISTS appointment_id;
+
+-- +goose StatementEnd
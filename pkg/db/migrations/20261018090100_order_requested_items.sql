@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE requested_item_status AS ENUM ('requested','accepted','modified','struck');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE TABLE IF NOT EXISTS order_requested_items (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  medicine_id uuid NOT NULL,
+  quantity numeric(12,2) NOT NULL CHECK (quantity > 0),
+  reason text,
+  status requested_item_status NOT NULL DEFAULT 'requested',
+  approved_quantity numeric(12,2) CHECK (approved_quantity IS NULL OR approved_quantity > 0),
+  doctor_note text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_requested_items_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_requested_items_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE RESTRICT,
+  CONSTRAINT unique_requested_item_per_medicine UNIQUE (order_id, medicine_id)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS order_requested_items CASCADE;
+DROP TYPE IF EXISTS requested_item_status CASCADE;
+
+-- +goose StatementEnd
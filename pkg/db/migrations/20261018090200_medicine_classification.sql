@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE drug_classification AS ENUM ('otc','pharmacy_only','prescription_only','controlled');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+ALTER TABLE medicines
+  ADD COLUMN IF NOT EXISTS classification drug_classification NOT NULL DEFAULT 'prescription_only',
+  ADD COLUMN IF NOT EXISTS max_quantity_per_order numeric(12,2) CHECK (max_quantity_per_order IS NULL OR max_quantity_per_order > 0);
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS controlled_approved_by uuid,
+  ADD COLUMN IF NOT EXISTS controlled_approved_at timestamptz;
+
+UPDATE medicines SET classification = 'otc'
+  WHERE name IN ('Paracetamol', 'Aspirin', 'Vitamin C');
+UPDATE medicines SET classification = 'pharmacy_only'
+  WHERE name IN ('Ibuprofen', 'Cetirizine', 'Omeprazole');
+UPDATE medicines SET classification = 'prescription_only'
+  WHERE name IN ('Amoxicillin', 'Metformin', 'Lisinopril', 'Atorvastatin');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE orders
+  DROP COLUMN IF EXISTS controlled_approved_at,
+  DROP COLUMN IF EXISTS controlled_approved_by;
+
+ALTER TABLE medicines
+  DROP COLUMN IF EXISTS max_quantity_per_order,
+  DROP COLUMN IF EXISTS classification;
+
+DROP TYPE IF EXISTS drug_classification CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE order_items
+  ADD COLUMN IF NOT EXISTS dose numeric(12,2) CHECK (dose IS NULL OR dose > 0),
+  ADD COLUMN IF NOT EXISTS frequency_per_day numeric(6,2) CHECK (frequency_per_day IS NULL OR frequency_per_day > 0),
+  ADD COLUMN IF NOT EXISTS route text,
+  ADD COLUMN IF NOT EXISTS duration_days int CHECK (duration_days IS NULL OR duration_days > 0),
+  ADD COLUMN IF NOT EXISTS instructions text;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE order_items
+  DROP COLUMN IF EXISTS instructions,
+  DROP COLUMN IF EXISTS duration_days,
+  DROP COLUMN IF EXISTS route,
+  DROP COLUMN IF EXISTS frequency_per_day,
+  DROP COLUMN IF EXISTS dose;
+
+-- +goose StatementEnd
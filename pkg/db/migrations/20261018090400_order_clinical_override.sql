@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS clinical_override_reason text;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS clinical_overridden_at timestamptz;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE orders DROP COLUMN IF EXISTS clinical_overridden_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS clinical_override_reason;
+
+-- +goose StatementEnd
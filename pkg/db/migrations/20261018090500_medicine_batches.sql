@@ -0,0 +1,66 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS medicine_batches (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  medicine_id uuid NOT NULL,
+  lot_number text NOT NULL,
+  expiry_date date,
+  received_quantity numeric(12,2) NOT NULL CHECK (received_quantity >= 0),
+  quantity numeric(12,2) NOT NULL CHECK (quantity >= 0),
+  received_at timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_medicine_batches_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE RESTRICT,
+  CONSTRAINT unique_medicine_lot UNIQUE (medicine_id, lot_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_medicine_batches_fefo
+  ON medicine_batches (medicine_id, expiry_date NULLS LAST, received_at)
+  WHERE quantity > 0;
+
+CREATE INDEX IF NOT EXISTS idx_medicine_batches_expiry
+  ON medicine_batches (expiry_date)
+  WHERE quantity > 0;
+
+CREATE TABLE IF NOT EXISTS order_item_batches (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_item_id uuid NOT NULL,
+  batch_id uuid NOT NULL,
+  quantity numeric(12,2) NOT NULL CHECK (quantity > 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_item_batches_order_item
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_item_batches_batch
+    FOREIGN KEY (batch_id)
+    REFERENCES medicine_batches(id)
+    ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_batches_order_item
+  ON order_item_batches (order_item_id);
+
+CREATE INDEX IF NOT EXISTS idx_order_item_batches_batch
+  ON order_item_batches (batch_id);
+
+-- existing stock has no lot information; carry it over as an undated opening batch
+INSERT INTO medicine_batches (medicine_id, lot_number, expiry_date, received_quantity, quantity, received_at)
+SELECT id, 'OPENING-BALANCE', NULL, stock, stock, now()
+FROM medicines
+WHERE stock > 0 AND deleted_at IS NULL
+ON CONFLICT (medicine_id, lot_number) DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS order_item_batches CASCADE;
+DROP TABLE IF EXISTS medicine_batches CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,94 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE stock_movement_type AS ENUM ('receive','reserve','release','dispense','adjust','return');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS reserved numeric(12,2) NOT NULL DEFAULT 0;
+
+DO $$ BEGIN
+  ALTER TABLE medicines ADD CONSTRAINT medicines_reserved_check CHECK (reserved >= 0);
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  medicine_id uuid NOT NULL,
+  batch_id uuid,
+  order_id uuid,
+  movement_type stock_movement_type NOT NULL,
+  stock_delta numeric(12,2) NOT NULL DEFAULT 0,
+  reserved_delta numeric(12,2) NOT NULL DEFAULT 0,
+  actor_id uuid,
+  actor_role text NOT NULL,
+  reason text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_stock_movements_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE RESTRICT,
+  CONSTRAINT fk_stock_movements_batch
+    FOREIGN KEY (batch_id)
+    REFERENCES medicine_batches(id)
+    ON DELETE RESTRICT,
+  CONSTRAINT fk_stock_movements_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_medicine
+  ON stock_movements (medicine_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order
+  ON stock_movements (order_id);
+
+-- the ledger is append-only
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_stock_movements_append_only ON stock_movements;
+CREATE TRIGGER trg_stock_movements_append_only
+  BEFORE UPDATE OR DELETE ON stock_movements
+  FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- opening balance so the ledger agrees with the stock on hand today
+INSERT INTO stock_movements (medicine_id, batch_id, movement_type, stock_delta, reserved_delta, actor_role, reason)
+SELECT m.id, b.id, 'adjust', m.stock, 0, 'system', 'opening balance'
+FROM medicines m
+LEFT JOIN medicine_batches b ON b.medicine_id = m.id AND b.lot_number = 'OPENING-BALANCE'
+WHERE m.stock <> 0;
+
+-- approved orders that are not paid yet already hold their items
+INSERT INTO stock_movements (medicine_id, order_id, movement_type, stock_delta, reserved_delta, actor_role, reason)
+SELECT oi.medicine_id, o.id, 'reserve', 0, oi.quantity, 'system', 'opening reservation for approved order'
+FROM orders o
+JOIN order_items oi ON oi.order_id = o.id
+WHERE o.status = 'approved';
+
+UPDATE medicines m
+SET reserved = r.reserved
+FROM (
+  SELECT oi.medicine_id, SUM(oi.quantity) AS reserved
+  FROM orders o
+  JOIN order_items oi ON oi.order_id = o.id
+  WHERE o.status = 'approved'
+  GROUP BY oi.medicine_id
+) r
+WHERE r.medicine_id = m.id;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS stock_movements CASCADE;
+DROP FUNCTION IF EXISTS stock_movements_append_only();
+ALTER TABLE medicines DROP CONSTRAINT IF EXISTS medicines_reserved_check;
+ALTER TABLE medicines DROP COLUMN IF EXISTS reserved;
+DROP TYPE IF EXISTS stock_movement_type CASCADE;
+
+-- +goose StatementEnd
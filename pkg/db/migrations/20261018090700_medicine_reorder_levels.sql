@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS reorder_point numeric(12,2);
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS reorder_quantity numeric(12,2);
+
+DO $$ BEGIN
+  ALTER TABLE medicines ADD CONSTRAINT medicines_reorder_point_check CHECK (reorder_point IS NULL OR reorder_point >= 0);
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  ALTER TABLE medicines ADD CONSTRAINT medicines_reorder_quantity_check CHECK (reorder_quantity IS NULL OR reorder_quantity > 0);
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE INDEX IF NOT EXISTS idx_medicines_low_stock
+  ON medicines ((stock - reserved))
+  WHERE reorder_point IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_medicines_low_stock;
+ALTER TABLE medicines DROP CONSTRAINT IF EXISTS medicines_reorder_quantity_check;
+ALTER TABLE medicines DROP CONSTRAINT IF EXISTS medicines_reorder_point_check;
+ALTER TABLE medicines DROP COLUMN IF EXISTS reorder_quantity;
+ALTER TABLE medicines DROP COLUMN IF EXISTS reorder_point;
+
+-- +goose StatementEnd
@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS generic_name text;
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS brand_name text;
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS name_th text;
+
+-- 'simple' keeps words as typed, which suits drug names and Thai text alike
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS search_vector tsvector
+  GENERATED ALWAYS AS (
+    to_tsvector('simple',
+      coalesce(name, '') || ' ' ||
+      coalesce(generic_name, '') || ' ' ||
+      coalesce(brand_name, '') || ' ' ||
+      coalesce(name_th, ''))
+  ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_medicines_search_vector
+  ON medicines USING gin (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_medicines_name_trgm
+  ON medicines USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicines_generic_name_trgm
+  ON medicines USING gin (generic_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicines_brand_name_trgm
+  ON medicines USING gin (brand_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_medicines_name_th_trgm
+  ON medicines USING gin (name_th gin_trgm_ops);
+
+UPDATE medicines SET generic_name = v.generic_name, name_th = v.name_th
+FROM (VALUES
+  ('Paracetamol',  'paracetamol',           'พาราเซตามอล'),
+  ('Ibuprofen',    'ibuprofen',             'ไอบูโพรเฟน'),
+  ('Amoxicillin',  'amoxicillin',           'อะม็อกซีซิลลิน'),
+  ('Metformin',    'metformin',             'เมทฟอร์มิน'),
+  ('Lisinopril',   'lisinopril',            'ลิซิโนพริล'),
+  ('Atorvastatin', 'atorvastatin',          'อะทอร์วาสแตติน'),
+  ('Omeprazole',   'omeprazole',            'โอเมพราโซล'),
+  ('Aspirin',      'aspirin',               'แอสไพริน'),
+  ('Cetirizine',   'cetirizine',            'เซทิริซีน'),
+  ('Vitamin C',    'ascorbic acid',         'วิตามินซี')
+) AS v(name, generic_name, name_th)
+WHERE medicines.name = v.name AND medicines.generic_name IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_medicines_name_th_trgm;
+DROP INDEX IF EXISTS idx_medicines_brand_name_trgm;
+DROP INDEX IF EXISTS idx_medicines_generic_name_trgm;
+DROP INDEX IF EXISTS idx_medicines_name_trgm;
+DROP INDEX IF EXISTS idx_medicines_search_vector;
+ALTER TABLE medicines DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE medicines DROP COLUMN IF EXISTS name_th;
+ALTER TABLE medicines DROP COLUMN IF EXISTS brand_name;
+ALTER TABLE medicines DROP COLUMN IF EXISTS generic_name;
+
+-- +goose StatementEnd
@@ -0,0 +1,153 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS therapeutic_categories (
+  code text PRIMARY KEY,
+  name text NOT NULL,
+  name_th text,
+  level smallint NOT NULL CHECK (level BETWEEN 1 AND 5)
+);
+
+INSERT INTO therapeutic_categories (code, name, name_th, level) VALUES
+  ('A', 'Alimentary tract and metabolism', 'ระบบทางเดินอาหารและเมตาบอลิซึม', 1),
+  ('B', 'Blood and blood forming organs', 'เลือดและอวัยวะสร้างเลือด', 1),
+  ('C', 'Cardiovascular system', 'ระบบหัวใจและหลอดเลือด', 1),
+  ('D', 'Dermatologicals', 'ยาผิวหนัง', 1),
+  ('G', 'Genito urinary system and sex hormones', 'ระบบสืบพันธุ์ ทางเดินปัสสาวะ และฮอร์โมนเพศ', 1),
+  ('H', 'Systemic hormonal preparations, excluding sex hormones and insulins', 'ฮอร์โมนที่ออกฤทธิ์ทั่วร่างกาย', 1),
+  ('J', 'Antiinfectives for systemic use', 'ยาต้านการติดเชื้อที่ออกฤทธิ์ทั่วร่างกาย', 1),
+  ('L', 'Antineoplastic and immunomodulating agents', 'ยาต้านมะเร็งและปรับภูมิคุ้มกัน', 1),
+  ('M', 'Musculo-skeletal system', 'ระบบกล้ามเนื้อและกระดูก', 1),
+  ('N', 'Nervous system', 'ระบบประสาท', 1),
+  ('P', 'Antiparasitic products, insecticides and repellents', 'ยาต้านปรสิต', 1),
+  ('R', 'Respiratory system', 'ระบบทางเดินหายใจ', 1),
+  ('S', 'Sensory organs', 'อวัยวะรับสัมผัส', 1),
+  ('V', 'Various', 'อื่น ๆ', 1),
+  ('A02BC01', 'omeprazole', 'โอเมพราโซล', 5),
+  ('A10BA02', 'metformin', 'เมทฟอร์มิน', 5),
+  ('A11GA01', 'ascorbic acid (vitamin C)', 'วิตามินซี', 5),
+  ('C09AA03', 'lisinopril', 'ลิซิโนพริล', 5),
+  ('C10AA05', 'atorvastatin', 'อะทอร์วาสแตติน', 5),
+  ('J01CA04', 'amoxicillin', 'อะม็อกซีซิลลิน', 5),
+  ('M01AE01', 'ibuprofen', 'ไอบูโพรเฟน', 5),
+  ('N02BA01', 'acetylsalicylic acid', 'แอสไพริน', 5),
+  ('N02BE01', 'paracetamol', 'พาราเซตามอล', 5),
+  ('R06AE07', 'cetirizine', 'เซทิริซีน', 5)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS atc_code text;
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS strength_value numeric(12,3);
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS strength_unit text;
+
+DO $$ BEGIN
+  ALTER TABLE medicines ADD CONSTRAINT fk_medicines_atc_code
+    FOREIGN KEY (atc_code) REFERENCES therapeutic_categories(code) ON DELETE RESTRICT;
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+CREATE INDEX IF NOT EXISTS idx_medicines_atc_code
+  ON medicines (atc_code text_pattern_ops);
+
+UPDATE medicines SET atc_code = v.atc_code, strength_value = v.strength_value, strength_unit = 'mg'
+FROM (VALUES
+  ('Paracetamol',  'N02BE01', 500),
+  ('Ibuprofen',    'M01AE01', 400),
+  ('Amoxicillin',  'J01CA04', 500),
+  ('Metformin',    'A10BA02', 500),
+  ('Lisinopril',   'C09AA03', 10),
+  ('Atorvastatin', 'C10AA05', 20),
+  ('Omeprazole',   'A02BC01', 20),
+  ('Aspirin',      'N02BA01', 81),
+  ('Cetirizine',   'R06AE07', 10),
+  ('Vitamin C',    'A11GA01', 500)
+) AS v(name, atc_code, strength_value)
+WHERE medicines.name = v.name AND medicines.atc_code IS NULL;
+
+CREATE TABLE IF NOT EXISTS medicine_units (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  medicine_id uuid NOT NULL,
+  name text NOT NULL,
+  factor numeric(12,3) NOT NULL CHECK (factor > 0),
+  price numeric(12,2) NOT NULL CHECK (price >= 0),
+  is_base boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_medicine_units_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE CASCADE,
+  CONSTRAINT unique_medicine_unit_name UNIQUE (medicine_id, name),
+  CONSTRAINT base_unit_factor_check CHECK (NOT is_base OR factor = 1)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_medicine_base_unit
+  ON medicine_units (medicine_id)
+  WHERE is_base;
+
+-- every medicine is sold in its existing unit at its existing price
+INSERT INTO medicine_units (medicine_id, name, factor, price, is_base)
+SELECT id, unit, 1, price, true
+FROM medicines
+ON CONFLICT DO NOTHING;
+
+-- tablets and capsules are also sold by the strip and by the box
+INSERT INTO medicine_units (medicine_id, name, factor, price, is_base)
+SELECT m.id, p.name, p.factor, m.price * p.factor, false
+FROM medicines m
+CROSS JOIN (VALUES ('strip', 10), ('box', 100)) AS p(name, factor)
+WHERE m.unit IN ('tablet', 'capsule') AND m.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_id uuid;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_factor numeric(12,3) NOT NULL DEFAULT 1;
+ALTER TABLE order_requested_items ADD COLUMN IF NOT EXISTS unit_id uuid;
+ALTER TABLE order_requested_items ADD COLUMN IF NOT EXISTS unit_factor numeric(12,3) NOT NULL DEFAULT 1;
+
+DO $$ BEGIN
+  ALTER TABLE order_items ADD CONSTRAINT fk_order_items_unit
+    FOREIGN KEY (unit_id) REFERENCES medicine_units(id) ON DELETE RESTRICT;
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  ALTER TABLE order_items ADD CONSTRAINT order_items_unit_factor_check CHECK (unit_factor > 0);
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  ALTER TABLE order_requested_items ADD CONSTRAINT fk_order_requested_items_unit
+    FOREIGN KEY (unit_id) REFERENCES medicine_units(id) ON DELETE RESTRICT;
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  ALTER TABLE order_requested_items ADD CONSTRAINT order_requested_items_unit_factor_check CHECK (unit_factor > 0);
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- existing lines were ordered in the base unit
+UPDATE order_items oi SET unit_id = u.id
+FROM medicine_units u
+WHERE u.medicine_id = oi.medicine_id AND u.is_base AND oi.unit_id IS NULL;
+
+UPDATE order_requested_items ri SET unit_id = u.id
+FROM medicine_units u
+WHERE u.medicine_id = ri.medicine_id AND u.is_base AND ri.unit_id IS NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE order_requested_items DROP CONSTRAINT IF EXISTS order_requested_items_unit_factor_check;
+ALTER TABLE order_requested_items DROP CONSTRAINT IF EXISTS fk_order_requested_items_unit;
+ALTER TABLE order_requested_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE order_requested_items DROP COLUMN IF EXISTS unit_id;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_unit_factor_check;
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS fk_order_items_unit;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_factor;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_id;
+DROP TABLE IF EXISTS medicine_units CASCADE;
+DROP INDEX IF EXISTS idx_medicines_atc_code;
+ALTER TABLE medicines DROP CONSTRAINT IF EXISTS fk_medicines_atc_code;
+ALTER TABLE medicines DROP COLUMN IF EXISTS strength_unit;
+ALTER TABLE medicines DROP COLUMN IF EXISTS strength_value;
+ALTER TABLE medicines DROP COLUMN IF EXISTS atc_code;
+DROP TABLE IF EXISTS therapeutic_categories CASCADE;
+
+-- +goose StatementEnd
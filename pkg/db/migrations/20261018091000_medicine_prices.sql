@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS medicine_prices (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  medicine_id uuid NOT NULL,
+  unit_id uuid NOT NULL,
+  price numeric(12,2) NOT NULL CHECK (price >= 0),
+  effective_from timestamptz NOT NULL,
+  effective_to timestamptz,
+  reason text,
+  created_by uuid,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_medicine_prices_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_medicine_prices_unit
+    FOREIGN KEY (unit_id)
+    REFERENCES medicine_units(id)
+    ON DELETE CASCADE,
+  CONSTRAINT medicine_prices_range_check CHECK (effective_to IS NULL OR effective_to > effective_from),
+  CONSTRAINT unique_medicine_price_start UNIQUE (unit_id, effective_from)
+);
+
+CREATE INDEX IF NOT EXISTS idx_medicine_prices_medicine
+  ON medicine_prices (medicine_id, effective_from DESC);
+
+-- only the latest range of a unit is open-ended
+CREATE UNIQUE INDEX IF NOT EXISTS unique_medicine_price_open
+  ON medicine_prices (unit_id)
+  WHERE effective_to IS NULL;
+
+-- the current unit prices have been in effect since the medicine was added
+INSERT INTO medicine_prices (medicine_id, unit_id, price, effective_from, reason)
+SELECT u.medicine_id, u.id, u.price, LEAST(m.created_at, u.created_at), 'opening price'
+FROM medicine_units u
+JOIN medicines m ON m.id = u.medicine_id
+ON CONFLICT DO NOTHING;
+
+-- order lines keep the unit price they were ordered at
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_price numeric(12,2) NOT NULL DEFAULT 0;
+
+UPDATE order_items oi SET unit_price = u.price
+FROM medicine_units u
+WHERE u.id = oi.unit_id AND oi.unit_price = 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_price;
+DROP TABLE IF EXISTS medicine_prices CASCADE;
+
+-- +goose StatementEnd
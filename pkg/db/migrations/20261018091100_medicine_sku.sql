@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS sku text;
+
+-- derive a code from the name for medicines added before SKUs existed
+WITH derived AS (
+  SELECT id,
+         upper(trim(both '-' from regexp_replace(name, '[^A-Za-z0-9]+', '-', 'g'))) AS base,
+         row_number() OVER (
+           PARTITION BY upper(trim(both '-' from regexp_replace(name, '[^A-Za-z0-9]+', '-', 'g')))
+           ORDER BY created_at, id
+         ) AS n
+  FROM medicines
+  WHERE sku IS NULL
+)
+UPDATE medicines m
+SET sku = CASE WHEN d.n = 1 THEN d.base ELSE d.base || '-' || d.n END
+FROM derived d
+WHERE d.id = m.id;
+
+ALTER TABLE medicines ALTER COLUMN sku SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_medicines_sku
+  ON medicines (sku);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS unique_medicines_sku;
+ALTER TABLE medicines DROP COLUMN IF EXISTS sku;
+
+-- +goose StatementEnd
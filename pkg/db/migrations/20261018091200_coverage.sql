@@ -0,0 +1,112 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- coverage a healthcare entitlement (as named in the user service) gives for a medicine;
+-- a rule without a medicine is the entitlement's default for medicines without their own rule
+CREATE TABLE IF NOT EXISTS coverage_rules (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  entitlement text NOT NULL,
+  medicine_id uuid,
+  covered_percent numeric(5,2) NOT NULL DEFAULT 0 CHECK (covered_percent >= 0 AND covered_percent <= 100),
+  copay numeric(12,2) NOT NULL DEFAULT 0 CHECK (copay >= 0),
+  excluded boolean NOT NULL DEFAULT false,
+  annual_cap numeric(12,2) CHECK (annual_cap IS NULL OR annual_cap >= 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_coverage_rules_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_coverage_rule_medicine
+  ON coverage_rules (entitlement, medicine_id)
+  WHERE medicine_id IS NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_coverage_rule_default
+  ON coverage_rules (entitlement)
+  WHERE medicine_id IS NULL;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS entitlement text;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS insurer_amount numeric(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS patient_amount numeric(12,2) NOT NULL DEFAULT 0;
+
+-- orders approved before coverage existed are paid in full by the patient
+UPDATE orders SET patient_amount = total_amount
+WHERE status IN ('approved','paid','processing','shipped','delivered') AND patient_amount = 0;
+
+-- how each order item was split at approval; also the running total for annual caps
+CREATE TABLE IF NOT EXISTS order_coverage_lines (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  order_item_id uuid NOT NULL,
+  entitlement text,
+  rule_id uuid,
+  line_total numeric(12,2) NOT NULL,
+  insurer_amount numeric(12,2) NOT NULL CHECK (insurer_amount >= 0),
+  patient_amount numeric(12,2) NOT NULL CHECK (patient_amount >= 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_coverage_lines_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_coverage_lines_item
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_coverage_lines_rule
+    FOREIGN KEY (rule_id)
+    REFERENCES coverage_rules(id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_coverage_lines_order ON order_coverage_lines (order_id);
+CREATE INDEX IF NOT EXISTS idx_order_coverage_lines_rule ON order_coverage_lines (rule_id, created_at);
+
+DO $$ BEGIN
+  CREATE TYPE payment_payer AS ENUM ('patient','insurer');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  CREATE TYPE payment_status AS ENUM ('pending','captured');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- money owed on an order by each payer: the patient's charge and the insurer's claim
+CREATE TABLE IF NOT EXISTS payments (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  payer payment_payer NOT NULL,
+  entitlement text,
+  amount numeric(12,2) NOT NULL CHECK (amount >= 0),
+  status payment_status NOT NULL DEFAULT 'pending',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_payments_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order ON payments (order_id);
+
+INSERT INTO payments (order_id, payer, amount, status, created_at)
+SELECT id, 'patient', total_amount, 'captured', updated_at
+FROM orders
+WHERE status IN ('paid','processing','shipped','delivered')
+  AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.order_id = orders.id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS payments CASCADE;
+DROP TYPE IF EXISTS payment_status CASCADE;
+DROP TYPE IF EXISTS payment_payer CASCADE;
+DROP TABLE IF EXISTS order_coverage_lines CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS patient_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS insurer_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS entitlement;
+DROP TABLE IF EXISTS coverage_rules CASCADE;
+
+-- +goose StatementEnd
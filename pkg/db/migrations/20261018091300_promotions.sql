@@ -0,0 +1,92 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE promotion_kind AS ENUM ('percentage','fixed');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+DO $$ BEGIN
+  CREATE TYPE promotion_scope AS ENUM ('order','medicine');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- a promotion without a coupon code applies to every order it qualifies for;
+-- one with a code only applies once the patient enters the code
+CREATE TABLE IF NOT EXISTS promotions (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  name text NOT NULL,
+  kind promotion_kind NOT NULL,
+  value numeric(12,2) NOT NULL CHECK (value >= 0),
+  scope promotion_scope NOT NULL DEFAULT 'order',
+  medicine_id uuid,
+  coupon_code text,
+  min_order_amount numeric(12,2) CHECK (min_order_amount IS NULL OR min_order_amount >= 0),
+  max_discount numeric(12,2) CHECK (max_discount IS NULL OR max_discount >= 0),
+  usage_limit int CHECK (usage_limit IS NULL OR usage_limit > 0),
+  per_patient_limit int CHECK (per_patient_limit IS NULL OR per_patient_limit > 0),
+  stackable boolean NOT NULL DEFAULT true,
+  active boolean NOT NULL DEFAULT true,
+  starts_at timestamptz NOT NULL DEFAULT now(),
+  ends_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_promotions_medicine
+    FOREIGN KEY (medicine_id)
+    REFERENCES medicines(id)
+    ON DELETE CASCADE,
+  CONSTRAINT promotions_percentage_value CHECK (kind <> 'percentage' OR value <= 100),
+  CONSTRAINT promotions_scope_medicine CHECK ((scope = 'medicine') = (medicine_id IS NOT NULL)),
+  CONSTRAINT promotions_window CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS unique_promotion_coupon_code
+  ON promotions (upper(coupon_code))
+  WHERE coupon_code IS NOT NULL;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal_amount numeric(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount numeric(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_code text;
+
+UPDATE orders SET subtotal_amount = total_amount WHERE subtotal_amount = 0;
+
+-- the discount a promotion gave on one order item; order-wide discounts are spread
+-- over the items in proportion to their price
+CREATE TABLE IF NOT EXISTS order_discounts (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  order_item_id uuid NOT NULL,
+  promotion_id uuid,
+  promotion_name text NOT NULL,
+  coupon_code text,
+  amount numeric(12,2) NOT NULL CHECK (amount >= 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_discounts_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_discounts_item
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_discounts_promotion
+    FOREIGN KEY (promotion_id)
+    REFERENCES promotions(id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order ON order_discounts (order_id);
+CREATE INDEX IF NOT EXISTS idx_order_discounts_promotion ON order_discounts (promotion_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS order_discounts CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS coupon_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal_amount;
+DROP TABLE IF EXISTS promotions CASCADE;
+DROP TYPE IF EXISTS promotion_scope CASCADE;
+DROP TYPE IF EXISTS promotion_kind CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,147 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE vat_treatment AS ENUM ('standard','exempt');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+ALTER TABLE medicines ADD COLUMN IF NOT EXISTS vat_treatment vat_treatment NOT NULL DEFAULT 'standard';
+
+-- prices include VAT; vat_amount is the part of total_amount that is tax
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS vat_amount numeric(12,2) NOT NULL DEFAULT 0;
+
+-- orders priced before VAT was tracked only sold standard-rated medicines
+UPDATE orders SET vat_amount = round(total_amount * 7 / 107, 2)
+WHERE status IN ('approved','paid','processing','shipped','delivered') AND vat_amount = 0;
+
+-- the VAT included in each order item after discounts; replaced whenever the order is priced
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  order_item_id uuid NOT NULL,
+  vat_treatment vat_treatment NOT NULL,
+  vat_rate numeric(5,2) NOT NULL CHECK (vat_rate >= 0),
+  amount numeric(12,2) NOT NULL CHECK (amount >= 0),
+  taxable_amount numeric(12,2) NOT NULL CHECK (taxable_amount >= 0),
+  vat_amount numeric(12,2) NOT NULL CHECK (vat_amount >= 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_tax_lines_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_order_tax_lines_item
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order ON order_tax_lines (order_id);
+
+DO $$ BEGIN
+  CREATE TYPE tax_document_type AS ENUM ('tax_invoice','receipt');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+-- last running number issued per document type and year; the row stays locked until the
+-- issuing transaction ends so numbers are never skipped or repeated
+CREATE TABLE IF NOT EXISTS tax_invoice_sequences (
+  document_type tax_document_type NOT NULL,
+  year int NOT NULL,
+  last_number int NOT NULL CHECK (last_number > 0),
+  PRIMARY KEY (document_type, year)
+);
+
+-- tax invoices and receipts issued at payment; seller, buyer and amounts are copied in
+-- so the document reads the same however the order or configuration changes later
+CREATE TABLE IF NOT EXISTS tax_invoices (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  number text NOT NULL UNIQUE,
+  document_type tax_document_type NOT NULL,
+  year int NOT NULL,
+  sequence int NOT NULL CHECK (sequence > 0),
+  order_id uuid NOT NULL,
+  payment_id uuid NOT NULL UNIQUE,
+  seller_name text NOT NULL,
+  seller_tax_id text NOT NULL,
+  seller_branch text NOT NULL,
+  seller_address text NOT NULL,
+  buyer_name text NOT NULL,
+  buyer_tax_id text,
+  buyer_branch text,
+  buyer_address text,
+  subtotal_amount numeric(12,2) NOT NULL,
+  discount_amount numeric(12,2) NOT NULL,
+  vatable_amount numeric(12,2) NOT NULL,
+  exempt_amount numeric(12,2) NOT NULL,
+  vat_rate numeric(5,2) NOT NULL,
+  vat_amount numeric(12,2) NOT NULL,
+  total_amount numeric(12,2) NOT NULL,
+  entitlement text,
+  insurer_amount numeric(12,2) NOT NULL,
+  patient_amount numeric(12,2) NOT NULL,
+  issued_at timestamptz NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT unique_tax_invoice_sequence UNIQUE (document_type, year, sequence),
+  CONSTRAINT tax_invoices_full_buyer CHECK (
+    document_type <> 'tax_invoice' OR (buyer_tax_id IS NOT NULL AND buyer_address IS NOT NULL)
+  ),
+  CONSTRAINT fk_tax_invoices_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id),
+  CONSTRAINT fk_tax_invoices_payment
+    FOREIGN KEY (payment_id)
+    REFERENCES payments(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_invoices_order ON tax_invoices (order_id);
+
+CREATE TABLE IF NOT EXISTS tax_invoice_lines (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  tax_invoice_id uuid NOT NULL,
+  line_no int NOT NULL,
+  order_item_id uuid NOT NULL,
+  description text NOT NULL,
+  quantity numeric(12,2) NOT NULL,
+  unit_label text NOT NULL,
+  unit_price numeric(12,2) NOT NULL,
+  discount_amount numeric(12,2) NOT NULL,
+  amount numeric(12,2) NOT NULL,
+  vat_treatment vat_treatment NOT NULL,
+  vat_amount numeric(12,2) NOT NULL,
+  CONSTRAINT unique_tax_invoice_line UNIQUE (tax_invoice_id, line_no),
+  CONSTRAINT fk_tax_invoice_lines_invoice
+    FOREIGN KEY (tax_invoice_id)
+    REFERENCES tax_invoices(id)
+);
+
+CREATE OR REPLACE FUNCTION reject_tax_invoice_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'issued tax invoices cannot be changed';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS tax_invoices_immutable ON tax_invoices;
+CREATE TRIGGER tax_invoices_immutable
+  BEFORE UPDATE OR DELETE ON tax_invoices
+  FOR EACH ROW EXECUTE FUNCTION reject_tax_invoice_change();
+
+DROP TRIGGER IF EXISTS tax_invoice_lines_immutable ON tax_invoice_lines;
+CREATE TRIGGER tax_invoice_lines_immutable
+  BEFORE UPDATE OR DELETE ON tax_invoice_lines
+  FOR EACH ROW EXECUTE FUNCTION reject_tax_invoice_change();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS tax_invoice_lines CASCADE;
+DROP TABLE IF EXISTS tax_invoices CASCADE;
+DROP FUNCTION IF EXISTS reject_tax_invoice_change();
+DROP TABLE IF EXISTS tax_invoice_sequences CASCADE;
+DROP TYPE IF EXISTS tax_document_type CASCADE;
+DROP TABLE IF EXISTS order_tax_lines CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS vat_amount;
+ALTER TABLE medicines DROP COLUMN IF EXISTS vat_treatment;
+DROP TYPE IF EXISTS vat_treatment CASCADE;
+
+-- +goose StatementEnd
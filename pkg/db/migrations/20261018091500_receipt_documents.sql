@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+
+DO $$ BEGIN
+  CREATE TYPE payment_method AS ENUM ('cash','card','promptpay','bank_transfer');
+EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS method payment_method;
+
+-- charged to the patient on top of total_amount
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_fee numeric(12,2) NOT NULL DEFAULT 0 CHECK (delivery_fee >= 0);
+
+-- adding columns does not fire the row triggers that keep issued invoices unchanged
+ALTER TABLE tax_invoices ADD COLUMN IF NOT EXISTS delivery_fee numeric(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE tax_invoices ADD COLUMN IF NOT EXISTS payment_method payment_method;
+
+-- the PDF of an invoice as first rendered; later downloads serve these bytes
+CREATE TABLE IF NOT EXISTS tax_invoice_documents (
+  tax_invoice_id uuid PRIMARY KEY,
+  content_type text NOT NULL,
+  content bytea NOT NULL,
+  sha256 text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_tax_invoice_documents_invoice
+    FOREIGN KEY (tax_invoice_id)
+    REFERENCES tax_invoices(id)
+);
+
+DROP TRIGGER IF EXISTS tax_invoice_documents_immutable ON tax_invoice_documents;
+CREATE TRIGGER tax_invoice_documents_immutable
+  BEFORE UPDATE OR DELETE ON tax_invoice_documents
+  FOR EACH ROW EXECUTE FUNCTION reject_tax_invoice_change();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS tax_invoice_documents CASCADE;
+ALTER TABLE tax_invoices DROP COLUMN IF EXISTS payment_method;
+ALTER TABLE tax_invoices DROP COLUMN IF EXISTS delivery_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_fee;
+ALTER TABLE payments DROP COLUMN IF EXISTS method;
+DROP TYPE IF EXISTS payment_method CASCADE;
+
+-- +goose StatementEnd
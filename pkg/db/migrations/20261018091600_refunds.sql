@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'partially_refunded';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'refunded';
+ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'refunded';
+ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'voided';
+
+-- money given back on the patient's payment; insurer_amount is taken off the insurer claim
+CREATE TABLE IF NOT EXISTS refunds (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  payment_id uuid NOT NULL,
+  amount numeric(12,2) NOT NULL CHECK (amount >= 0),
+  insurer_amount numeric(12,2) NOT NULL DEFAULT 0 CHECK (insurer_amount >= 0),
+  reason text NOT NULL,
+  provider_reference text,
+  actor_id uuid,
+  actor_role text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_refunds_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id),
+  CONSTRAINT fk_refunds_payment
+    FOREIGN KEY (payment_id)
+    REFERENCES payments(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds (order_id);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  refund_id uuid NOT NULL,
+  order_item_id uuid NOT NULL,
+  quantity numeric(12,2) NOT NULL CHECK (quantity > 0),
+  amount numeric(12,2) NOT NULL CHECK (amount >= 0),
+  insurer_amount numeric(12,2) NOT NULL DEFAULT 0 CHECK (insurer_amount >= 0),
+  CONSTRAINT fk_refund_items_refund
+    FOREIGN KEY (refund_id)
+    REFERENCES refunds(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_refund_items_order_item
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refund_items_order_item ON refund_items (order_item_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS refund_items CASCADE;
+DROP TABLE IF EXISTS refunds CASCADE;
+-- enum values cannot be dropped; refunded orders and payments keep their status
+
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_reason text;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_by uuid;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_by_role text;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at timestamptz;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_fee numeric(12,2) NOT NULL DEFAULT 0 CHECK (cancellation_fee >= 0);
+
+-- how long patients may cancel orders in each status and what is kept from paid orders;
+-- statuses without a row can be cancelled at any time free of charge
+CREATE TABLE IF NOT EXISTS cancellation_rules (
+  status order_status PRIMARY KEY,
+  window_minutes int CHECK (window_minutes >= 0),
+  fee_percent numeric(5,2) NOT NULL DEFAULT 0 CHECK (fee_percent >= 0 AND fee_percent <= 100),
+  fee_amount numeric(12,2) NOT NULL DEFAULT 0 CHECK (fee_amount >= 0),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS cancellation_rules CASCADE;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_by_role;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_by;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancellation_reason;
+
+-- +goose StatementEnd
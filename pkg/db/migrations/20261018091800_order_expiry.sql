@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'expired';
+
+-- every status an order has moved through; from_status is null for the creation entry
+CREATE TABLE IF NOT EXISTS order_status_history (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  from_status order_status,
+  to_status order_status NOT NULL,
+  actor_id uuid,
+  actor_role text NOT NULL,
+  reason text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_status_history_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order ON order_status_history (order_id, created_at);
+
+-- the expiry job looks for orders waiting in these statuses since before a cutoff
+CREATE INDEX IF NOT EXISTS idx_orders_waiting ON orders (status, (COALESCE(reviewed_at, submitted_at, created_at)))
+  WHERE status IN ('pending','approved');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_orders_waiting;
+DROP TABLE IF EXISTS order_status_history CASCADE;
+-- enum values cannot be dropped; expired orders keep their status
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- domain events written with the change they describe and published by the relay
+CREATE TABLE IF NOT EXISTS outbox_events (
+  id uuid PRIMARY KEY,
+  aggregate_type text NOT NULL,
+  aggregate_id uuid NOT NULL,
+  event_type text NOT NULL,
+  payload jsonb NOT NULL,
+  status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','published','dead')),
+  attempts int NOT NULL DEFAULT 0,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  last_error text,
+  published_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- the relay only looks at pending events
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events (next_attempt_at, id)
+  WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate ON outbox_events (aggregate_id, id)
+  WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS outbox_events CASCADE;
+
+-- +goose StatementEnd
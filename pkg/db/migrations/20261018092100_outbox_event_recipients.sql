@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- the order stream replays a user's events after the last one they saw
+CREATE INDEX IF NOT EXISTS idx_outbox_events_patient ON outbox_events ((payload->>'patient_id'), id)
+  WHERE aggregate_type = 'order';
+CREATE INDEX IF NOT EXISTS idx_outbox_events_doctor ON outbox_events ((payload->>'doctor_id'), id)
+  WHERE aggregate_type = 'order';
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created ON outbox_events (created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_outbox_events_created;
+DROP INDEX IF EXISTS idx_outbox_events_doctor;
+DROP INDEX IF EXISTS idx_outbox_events_patient;
+
+-- +goose StatementEnd
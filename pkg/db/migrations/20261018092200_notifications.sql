@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+  user_id uuid PRIMARY KEY,
+  language text NOT NULL DEFAULT 'th' CHECK (language IN ('th','en')),
+  email text,
+  email_enabled boolean NOT NULL DEFAULT false,
+  phone text,
+  sms_enabled boolean NOT NULL DEFAULT false,
+  line_user_id text,
+  line_enabled boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS notification_logs (
+  id uuid PRIMARY KEY,
+  user_id uuid NOT NULL,
+  order_id uuid,
+  event_id uuid NOT NULL,
+  message text NOT NULL,
+  channel text NOT NULL,
+  recipient text NOT NULL,
+  language text NOT NULL,
+  subject text NOT NULL,
+  body text NOT NULL,
+  status text NOT NULL CHECK (status IN ('sent','failed')),
+  error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  -- the outbox may relay an event again; each message goes out once
+  CONSTRAINT uq_notification_logs_event UNIQUE (event_id, user_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_logs_user ON notification_logs (user_id, created_at DESC);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS notification_logs CASCADE;
+DROP TABLE IF EXISTS notification_preferences CASCADE;
+
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'changes_requested';
+
+ALTER TABLE orders
+  ADD COLUMN IF NOT EXISTS rejection_reason_code text,
+  ADD COLUMN IF NOT EXISTS rejection_reason text;
+
+-- the thread the patient and the doctor keep on an order
+CREATE TABLE IF NOT EXISTS order_comments (
+  id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  order_id uuid NOT NULL,
+  author_id uuid NOT NULL,
+  author_role text NOT NULL,
+  kind text NOT NULL CHECK (kind IN ('comment','change_request','resubmission','rejection')),
+  body text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT fk_order_comments_order
+    FOREIGN KEY (order_id)
+    REFERENCES orders(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_comments_order ON order_comments (order_id, created_at);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS order_comments CASCADE;
+ALTER TABLE orders
+  DROP COLUMN IF EXISTS rejection_reason,
+  DROP COLUMN IF EXISTS rejection_reason_code;
+-- enum values cannot be dropped; orders sent back to patients keep their status
+
+-- +goose StatementEnd
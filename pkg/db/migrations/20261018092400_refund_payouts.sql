@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- refunds are recorded pending and paid out through the provider once committed;
+-- refunds made before this were paid out inside their transaction
+ALTER TABLE refunds
+  ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'completed' CHECK (status IN ('pending','completed')),
+  ADD COLUMN IF NOT EXISTS last_error text;
+
+CREATE INDEX IF NOT EXISTS idx_refunds_pending ON refunds (created_at) WHERE status = 'pending';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_refunds_pending;
+ALTER TABLE refunds
+  DROP COLUMN IF EXISTS last_error,
+  DROP COLUMN IF EXISTS status;
+
+-- +goose StatementEnd
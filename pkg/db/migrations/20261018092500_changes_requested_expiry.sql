@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- orders sent back to the patient expire too, and wait from the later of their
+-- submission and their review: a resubmitted order from its resubmission
+DROP INDEX IF EXISTS idx_orders_waiting;
+CREATE INDEX IF NOT EXISTS idx_orders_waiting ON orders (status, (GREATEST(reviewed_at, submitted_at, created_at)))
+  WHERE status IN ('pending','approved','changes_requested');
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_orders_waiting;
+CREATE INDEX IF NOT EXISTS idx_orders_waiting ON orders (status, (COALESCE(reviewed_at, submitted_at, created_at)))
+  WHERE status IN ('pending','approved');
+
+-- +goose StatementEnd
@@ -2,9 +2,22 @@ package dto
 
 type ApproveOrderRequestDto struct {
 	OrderID string `json:"order_id"`
+	// required when the clinical checks report a major interaction or an allergy
+	OverrideReason *string `json:"override_reason"`
 }
 
 type ApproveOrderResponseDto struct {
+	OrderID          string            `json:"order_id"`
+	Status           string            `json:"status"`
+	ClinicalFindings []ClinicalFinding `json:"clinical_findings"`
+}
+
+type ApproveControlledOrderRequestDto struct {
 	OrderID string `json:"order_id"`
-	Status  string `json:"status"`
+}
+
+type ApproveControlledOrderResponseDto struct {
+	OrderID              string `json:"order_id"`
+	Status               string `json:"status"`
+	ControlledApprovedAt string `json:"controlled_approved_at"`
 }
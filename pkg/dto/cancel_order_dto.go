@@ -2,9 +2,15 @@ package dto
 
 type CancelOrderRequestDto struct {
 	OrderID string `json:"order_id"`
+	// Reason is required when a patient cancels.
+	Reason *string `json:"reason"`
 }
 
 type CancelOrderResponseDto struct {
 	OrderID string `json:"order_id"`
 	Status  string `json:"status"`
+	// CancellationFee is what was kept from the patient's payment; Refund is the
+	// automatic refund of the rest when the order had been paid.
+	CancellationFee float64    `json:"cancellation_fee"`
+	Refund          *RefundDto `json:"refund,omitempty"`
 }
@@ -0,0 +1,43 @@
+package dto
+
+import "order-service/pkg/models"
+
+// CancellationRuleRequestDto sets how long patients may cancel orders in a status and
+// the fee kept when the order was paid for.
+type CancellationRuleRequestDto struct {
+	// minutes after the order reached the status; any time when omitted
+	WindowMinutes *int `json:"window_minutes" validate:"omitempty,gte=0"`
+	// fee kept from the patient's payment: fee_amount plus fee_percent of what was paid
+	FeePercent float64 `json:"fee_percent" validate:"gte=0,lte=100"`
+	FeeAmount  float64 `json:"fee_amount" validate:"gte=0"`
+}
+
+type CancellationRuleDto struct {
+	Status        string  `json:"status"`
+	WindowMinutes *int    `json:"window_minutes"`
+	FeePercent    float64 `json:"fee_percent"`
+	FeeAmount     float64 `json:"fee_amount"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type GetCancellationRulesResponseDto struct {
+	Rules []CancellationRuleDto `json:"rules"`
+	Total int                   `json:"total"`
+}
+
+type DeleteCancellationRuleResponseDto struct {
+	OrderStatus string `json:"order_status"`
+	Status      string `json:"status"`
+}
+
+func ToCancellationRuleDto(rule *models.CancellationRule) CancellationRuleDto {
+	return CancellationRuleDto{
+		Status:        string(rule.Status),
+		WindowMinutes: rule.WindowMinutes,
+		FeePercent:    rule.FeePercent,
+		FeeAmount:     rule.FeeAmount,
+		CreatedAt:     rule.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     rule.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
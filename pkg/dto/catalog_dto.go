@@ -0,0 +1,40 @@
+package dto
+
+import "order-service/pkg/catalog"
+
+const (
+	CatalogActionCreate    = "create"
+	CatalogActionUpdate    = "update"
+	CatalogActionUnchanged = "unchanged"
+)
+
+type CatalogFieldChangeDto struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type CatalogRowResultDto struct {
+	Line    int                     `json:"line"`
+	SKU     string                  `json:"sku"`
+	Action  string                  `json:"action"`
+	Changes []CatalogFieldChangeDto `json:"changes"`
+}
+
+type CatalogImportSummaryDto struct {
+	Rows      int `json:"rows"`
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Errors    int `json:"errors"`
+}
+
+// CatalogImportResponseDto describes what an import changes. Nothing is applied on a
+// dry run or when any row has an error.
+type CatalogImportResponseDto struct {
+	DryRun  bool                    `json:"dry_run"`
+	Applied bool                    `json:"applied"`
+	Summary CatalogImportSummaryDto `json:"summary"`
+	Rows    []CatalogRowResultDto   `json:"rows"`
+	Errors  []catalog.RowError      `json:"errors"`
+}
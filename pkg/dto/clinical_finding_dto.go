@@ -0,0 +1,35 @@
+package dto
+
+import "order-service/pkg/clinical"
+
+type ClinicalFinding struct {
+	Kind             string   `json:"kind"`
+	Severity         string   `json:"severity"`
+	Medicines        []string `json:"medicines"`
+	Message          string   `json:"message"`
+	Blocking         bool     `json:"blocking"`
+	RequiresOverride bool     `json:"requires_override"`
+}
+
+type ReloadClinicalTableResponseDto struct {
+	ReloadedAt string `json:"reloaded_at"`
+}
+
+func ToClinicalFindingDtoList(findings []clinical.Finding) []ClinicalFinding {
+	result := make([]ClinicalFinding, len(findings))
+	for i, finding := range findings {
+		medicines := finding.Medicines
+		if medicines == nil {
+			medicines = []string{}
+		}
+		result[i] = ClinicalFinding{
+			Kind:             string(finding.Kind),
+			Severity:         string(finding.Severity),
+			Medicines:        medicines,
+			Message:          finding.Message,
+			Blocking:         finding.Blocking(),
+			RequiresOverride: finding.RequiresOverride(),
+		}
+	}
+	return result
+}
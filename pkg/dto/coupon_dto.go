@@ -0,0 +1,47 @@
+package dto
+
+import "order-service/pkg/models"
+
+type ApplyCouponRequestDto struct {
+	OrderID    string `json:"order_id" validate:"required,uuid"`
+	CouponCode string `json:"coupon_code" validate:"required"`
+}
+
+type RemoveCouponRequestDto struct {
+	OrderID string `json:"order_id" validate:"required,uuid"`
+}
+
+type OrderDiscount struct {
+	OrderItemID   string  `json:"order_item_id"`
+	PromotionID   *string `json:"promotion_id"`
+	PromotionName string  `json:"promotion_name"`
+	CouponCode    *string `json:"coupon_code"`
+	Amount        float64 `json:"amount"`
+}
+
+// OrderTotalsResponseDto is an order's total after a coupon was applied or removed.
+type OrderTotalsResponseDto struct {
+	OrderID        string          `json:"order_id"`
+	CouponCode     *string         `json:"coupon_code"`
+	SubtotalAmount float64         `json:"subtotal_amount"`
+	DiscountAmount float64         `json:"discount_amount"`
+	TotalAmount    float64         `json:"total_amount"`
+	VATAmount      float64         `json:"vat_amount"`
+	InsurerAmount  float64         `json:"insurer_amount"`
+	PatientAmount  float64         `json:"patient_amount"`
+	Discounts      []OrderDiscount `json:"discounts"`
+}
+
+func ToOrderDiscountDtoList(discounts []models.OrderDiscount) []OrderDiscount {
+	res := make([]OrderDiscount, len(discounts))
+	for i, discount := range discounts {
+		res[i] = OrderDiscount{
+			OrderItemID:   discount.OrderItemID.String(),
+			PromotionID:   uuidString(discount.PromotionID),
+			PromotionName: discount.PromotionName,
+			CouponCode:    discount.CouponCode,
+			Amount:        discount.Amount,
+		}
+	}
+	return res
+}
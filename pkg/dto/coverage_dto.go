@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// CoverageRuleRequestDto sets what an entitlement pays for a medicine. Without a
+// medicine_id the rule is the entitlement's default for medicines without a rule.
+type CoverageRuleRequestDto struct {
+	Entitlement    string     `json:"entitlement" validate:"required"`
+	MedicineID     *uuid.UUID `json:"medicine_id"`
+	CoveredPercent float64    `json:"covered_percent" validate:"gte=0,lte=100"`
+	// copay the patient pays on every covered line
+	Copay    float64 `json:"copay" validate:"gte=0"`
+	Excluded bool    `json:"excluded"`
+	// most the rule pays for one patient in a calendar year; no cap when omitted
+	AnnualCap *float64 `json:"annual_cap" validate:"omitempty,gte=0"`
+}
+
+// UpdateCoverageRuleRequestDto changes the terms of a rule; its entitlement and medicine
+// are fixed.
+type UpdateCoverageRuleRequestDto struct {
+	CoveredPercent float64  `json:"covered_percent" validate:"gte=0,lte=100"`
+	Copay          float64  `json:"copay" validate:"gte=0"`
+	Excluded       bool     `json:"excluded"`
+	AnnualCap      *float64 `json:"annual_cap" validate:"omitempty,gte=0"`
+}
+
+type CoverageRuleDto struct {
+	ID             string   `json:"id"`
+	Entitlement    string   `json:"entitlement"`
+	MedicineID     *string  `json:"medicine_id"`
+	MedicineName   *string  `json:"medicine_name"`
+	CoveredPercent float64  `json:"covered_percent"`
+	Copay          float64  `json:"copay"`
+	Excluded       bool     `json:"excluded"`
+	AnnualCap      *float64 `json:"annual_cap"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+type GetCoverageRulesResponseDto struct {
+	Rules []CoverageRuleDto `json:"rules"`
+	Total int               `json:"total"`
+}
+
+type DeleteCoverageRuleResponseDto struct {
+	RuleID string `json:"rule_id"`
+	Status string `json:"status"`
+}
+
+func ToCoverageRuleDto(rule *models.CoverageRule) CoverageRuleDto {
+	res := CoverageRuleDto{
+		ID:             rule.ID.String(),
+		Entitlement:    rule.Entitlement,
+		CoveredPercent: rule.CoveredPercent,
+		Copay:          rule.Copay,
+		Excluded:       rule.Excluded,
+		AnnualCap:      rule.AnnualCap,
+		CreatedAt:      rule.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:      rule.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if rule.MedicineID != nil {
+		medicineID := rule.MedicineID.String()
+		res.MedicineID = &medicineID
+	}
+	if rule.Medicine != nil {
+		res.MedicineName = &rule.Medicine.Name
+	}
+	return res
+}
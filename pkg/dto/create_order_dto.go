@@ -1,9 +1,16 @@
 package dto
 
 type CreateOrderRequestDto struct {
-	Note *string `json:"note"`
+	Note          *string `json:"note"`
+	AppointmentID *string `json:"appointment_id" validate:"omitempty,uuid"`
+	DoctorID      *string `json:"doctor_id" validate:"omitempty,uuid"`
+	// medicines the patient would like the doctor to prescribe
+	RequestedItems []RequestedItemInput `json:"requested_items" validate:"omitempty,dive"`
 }
 
 type CreateOrderResponseDto struct {
-	OrderID string `json:"order_id"`
+	OrderID       string `json:"order_id"`
+	DoctorID      string `json:"doctor_id"`
+	AppointmentID string `json:"appointment_id"`
+	Status        string `json:"status"`
 }
@@ -1,19 +1,27 @@
 package dto
 
 type GetAllOrdersHistoryResponseDto struct {
-	OrderID        string      `json:"order_id"`
-	PatientID      string      `json:"patient_id"`
-	DoctorID       *string     `json:"doctor_id"`
-	TotalAmount    float64     `json:"total_amount"`
-	Note           *string     `json:"note"`
-	SubmittedAt    *string     `json:"submitted_at"`
-	ReviewedAt     *string     `json:"reviewed_at"`
-	Status         string      `json:"status"`
-	DeliveryStatus *string     `json:"delivery_status"`
-	DeliveryAt     *string     `json:"delivery_at"`
-	CreatedAt      string      `json:"created_at"`
-	UpdatedAt      string      `json:"updated_at"`
-	OrderItems     []OrderItem `json:"order_items"`
+	OrderID        string          `json:"order_id"`
+	PatientID      string          `json:"patient_id"`
+	DoctorID       *string         `json:"doctor_id"`
+	SubtotalAmount float64         `json:"subtotal_amount"`
+	DiscountAmount float64         `json:"discount_amount"`
+	CouponCode     *string         `json:"coupon_code"`
+	TotalAmount    float64         `json:"total_amount"`
+	VATAmount      float64         `json:"vat_amount"`
+	Entitlement    *string         `json:"entitlement"`
+	InsurerAmount  float64         `json:"insurer_amount"`
+	PatientAmount  float64         `json:"patient_amount"`
+	Note           *string         `json:"note"`
+	SubmittedAt    *string         `json:"submitted_at"`
+	ReviewedAt     *string         `json:"reviewed_at"`
+	Status         string          `json:"status"`
+	DeliveryStatus *string         `json:"delivery_status"`
+	DeliveryAt     *string         `json:"delivery_at"`
+	CreatedAt      string          `json:"created_at"`
+	UpdatedAt      string          `json:"updated_at"`
+	OrderItems     []OrderItem     `json:"order_items"`
+	RequestedItems []RequestedItem `json:"requested_items"`
 }
 
 type GetAllOrdersHistoryListDto struct {
@@ -1,21 +1,128 @@
 package dto
 
+import (
+	"order-service/pkg/models"
+	"order-service/pkg/prescription"
+
+	"github.com/google/uuid"
+)
+
 type OrderItem struct {
-	MedicineID   string  `json:"medicine_id"`
-	MedicineName string  `json:"medicine_name"`
-	Quantity     float64 `json:"quantity"`
+	MedicineID         string   `json:"medicine_id"`
+	MedicineName       string   `json:"medicine_name"`
+	Quantity           float64  `json:"quantity"`
+	UnitID             *string  `json:"unit_id"`
+	UnitLabel          string   `json:"unit_label"`
+	UnitFactor         float64  `json:"unit_factor"`
+	BaseQuantity       float64  `json:"base_quantity"`
+	UnitPrice          float64  `json:"unit_price"`
+	LineTotal          float64  `json:"line_total"`
+	Dose               *float64 `json:"dose"`
+	FrequencyPerDay    *float64 `json:"frequency_per_day"`
+	Route              *string  `json:"route"`
+	DurationDays       *int     `json:"duration_days"`
+	Instructions       *string  `json:"instructions"`
+	DosageInstructions string   `json:"dosage_instructions"`
+	// batches the item was dispensed from; empty until the order is paid
+	Batches []OrderItemBatch `json:"batches"`
+}
+
+type OrderItemBatch struct {
+	BatchID    string  `json:"batch_id"`
+	LotNumber  string  `json:"lot_number"`
+	ExpiryDate *string `json:"expiry_date"`
+	Quantity   float64 `json:"quantity"`
 }
 
 type GetOrderByIDResponseDto struct {
-	OrderID        string      `json:"order_id"`
-	PatientID      string      `json:"patient_id"`
-	DoctorID       string      `json:"doctor_id"`
-	TotalAmount    float64     `json:"total_amount"`
-	Note           *string     `json:"note"`
-	SubmittedAt    *string     `json:"submitted_at"`
-	ReviewedAt     *string     `json:"reviewed_at"`
-	Status         string      `json:"status"`
-	DeliveryStatus *string     `json:"delivery_status"`
-	DeliveryAt     *string     `json:"delivery_at"`
-	OrderItems     []OrderItem `json:"order_items"`
+	OrderID        string          `json:"order_id"`
+	PatientID      string          `json:"patient_id"`
+	DoctorID       string          `json:"doctor_id"`
+	SubtotalAmount float64         `json:"subtotal_amount"`
+	DiscountAmount float64         `json:"discount_amount"`
+	CouponCode     *string         `json:"coupon_code"`
+	TotalAmount    float64         `json:"total_amount"`
+	VATAmount      float64         `json:"vat_amount"`
+	Entitlement    *string         `json:"entitlement"`
+	InsurerAmount  float64         `json:"insurer_amount"`
+	PatientAmount  float64         `json:"patient_amount"`
+	Note           *string         `json:"note"`
+	SubmittedAt    *string         `json:"submitted_at"`
+	ReviewedAt     *string         `json:"reviewed_at"`
+	Status         string          `json:"status"`
+	DeliveryStatus *string         `json:"delivery_status"`
+	DeliveryAt     *string         `json:"delivery_at"`
+	OrderItems     []OrderItem     `json:"order_items"`
+	RequestedItems []RequestedItem `json:"requested_items"`
+	Discounts      []OrderDiscount `json:"discounts"`
+	// Rejection is why the doctor rejected the order, when they gave a reason.
+	Rejection *OrderRejectionDto `json:"rejection"`
+}
+
+// Conversion functions
+func ToOrderItemDtoList(items []models.OrderItem) []OrderItem {
+	result := make([]OrderItem, len(items))
+	for i := range items {
+		item := &items[i]
+		medicineName, unit := "", ""
+		if item.Medicine != nil {
+			medicineName, unit = item.Medicine.Name, item.Medicine.Unit
+		}
+		var route *string
+		if item.Route != nil {
+			r := string(*item.Route)
+			route = &r
+		}
+		batches := make([]OrderItemBatch, 0, len(item.Batches))
+		for _, itemBatch := range item.Batches {
+			batch := OrderItemBatch{
+				BatchID:  itemBatch.BatchID.String(),
+				Quantity: itemBatch.Quantity,
+			}
+			if itemBatch.Batch != nil {
+				batch.LotNumber = itemBatch.Batch.LotNumber
+				if itemBatch.Batch.ExpiryDate != nil {
+					expiry := itemBatch.Batch.ExpiryDate.Format("2006-01-02")
+					batch.ExpiryDate = &expiry
+				}
+			}
+			batches = append(batches, batch)
+		}
+		result[i] = OrderItem{
+			MedicineID:         item.MedicineID.String(),
+			MedicineName:       medicineName,
+			Quantity:           item.Quantity,
+			UnitID:             uuidString(item.UnitID),
+			UnitLabel:          unitLabel(item.Unit, unit),
+			UnitFactor:         item.UnitFactor,
+			BaseQuantity:       item.BaseQuantity(),
+			UnitPrice:          item.UnitPrice,
+			LineTotal:          item.LineTotal(),
+			Dose:               item.Dose,
+			FrequencyPerDay:    item.FrequencyPerDay,
+			Route:              route,
+			DurationDays:       item.DurationDays,
+			Instructions:       item.Instructions,
+			DosageInstructions: prescription.FormatInstructions(item, unit),
+			Batches:            batches,
+		}
+	}
+	return result
+}
+
+// unitLabel describes the ordered unit, falling back to the base unit for items that
+// were loaded without their unit.
+func unitLabel(unit *models.MedicineUnit, baseUnit string) string {
+	if unit == nil {
+		return baseUnit
+	}
+	return unit.Label(baseUnit)
+}
+
+func uuidString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
 }
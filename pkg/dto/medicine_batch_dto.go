@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"order-service/pkg/models"
+	"time"
+)
+
+type MedicineBatchResponseDto struct {
+	ID               string  `json:"id"`
+	MedicineID       string  `json:"medicine_id"`
+	MedicineName     string  `json:"medicine_name"`
+	LotNumber        string  `json:"lot_number"`
+	ExpiryDate       *string `json:"expiry_date"`
+	DaysUntilExpiry  *int    `json:"days_until_expiry"`
+	ReceivedQuantity float64 `json:"received_quantity"`
+	Quantity         float64 `json:"quantity"`
+	Unit             string  `json:"unit"`
+	ReceivedAt       string  `json:"received_at"`
+}
+
+type GetExpiringBatchesResponseDto struct {
+	Days    int                        `json:"days"`
+	Batches []MedicineBatchResponseDto `json:"batches"`
+	Total   int                        `json:"total"`
+}
+
+// ToMedicineBatchDto converts a batch; today is used to count the days left until expiry.
+func ToMedicineBatchDto(batch *models.MedicineBatch, today time.Time) MedicineBatchResponseDto {
+	item := MedicineBatchResponseDto{
+		ID:               batch.ID.String(),
+		MedicineID:       batch.MedicineID.String(),
+		LotNumber:        batch.LotNumber,
+		ReceivedQuantity: batch.ReceivedQuantity,
+		Quantity:         batch.Quantity,
+		ReceivedAt:       batch.ReceivedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if batch.Medicine != nil {
+		item.MedicineName = batch.Medicine.Name
+		item.Unit = batch.Medicine.Unit
+	}
+	if batch.ExpiryDate != nil {
+		expiry := batch.ExpiryDate.Format("2006-01-02")
+		daysLeft := int(batch.ExpiryDate.Sub(today).Hours() / 24)
+		item.ExpiryDate = &expiry
+		item.DaysUntilExpiry = &daysLeft
+	}
+	return item
+}
@@ -1,20 +1,147 @@
 package dto
 
+import "order-service/pkg/models"
+
 type MedicineResponseDto struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Stock     float64 `json:"stock"`
-	Unit      string  `json:"unit"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID                  string            `json:"id"`
+	SKU                 string            `json:"sku"`
+	Name                string            `json:"name"`
+	GenericName         *string           `json:"generic_name"`
+	BrandName           *string           `json:"brand_name"`
+	NameTh              *string           `json:"name_th"`
+	ATCCode             *string           `json:"atc_code"`
+	CategoryName        *string           `json:"category_name"`
+	StrengthValue       *float64          `json:"strength_value"`
+	StrengthUnit        *string           `json:"strength_unit"`
+	Strength            string            `json:"strength"`
+	Price               float64           `json:"price"`
+	Stock               float64           `json:"stock"`
+	Reserved            float64           `json:"reserved"`
+	Available           float64           `json:"available"`
+	Unit                string            `json:"unit"`
+	Classification      string            `json:"classification"`
+	VATTreatment        string            `json:"vat_treatment"`
+	MaxQuantityPerOrder *float64          `json:"max_quantity_per_order"`
+	ReorderPoint        *float64          `json:"reorder_point"`
+	ReorderQuantity     *float64          `json:"reorder_quantity"`
+	Units               []MedicineUnitDto `json:"units"`
+	CreatedAt           string            `json:"created_at"`
+	UpdatedAt           string            `json:"updated_at"`
+}
+
+type MedicineUnitDto struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Label  string  `json:"label"`
+	Factor float64 `json:"factor"`
+	Price  float64 `json:"price"`
+	IsBase bool    `json:"is_base"`
+}
+
+type SearchMedicinesQueryDto struct {
+	Q              string `query:"q"`
+	Unit           string `query:"unit"`
+	Classification string `query:"classification"`
+	Category       string `query:"category"`
+	InStock        bool   `query:"in_stock"`
+	Page           int    `query:"page"`
+	PageSize       int    `query:"page_size"`
 }
 
 type GetAllMedicinesResponseDto struct {
 	Medicines []MedicineResponseDto `json:"medicines"`
-	Total     int                   `json:"total"`
+	// number of medicines matching the search across all pages
+	Total    int `json:"total"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
 }
 
 type GetMedicineByIDResponseDto struct {
 	Medicine MedicineResponseDto `json:"medicine"`
 }
+
+type LowStockMedicineDto struct {
+	MedicineResponseDto
+	// how far available stock is below the reorder point
+	Shortfall              float64 `json:"shortfall"`
+	SuggestedOrderQuantity float64 `json:"suggested_order_quantity"`
+}
+
+type GetLowStockMedicinesResponseDto struct {
+	Medicines []LowStockMedicineDto `json:"medicines"`
+	Total     int                   `json:"total"`
+}
+
+// UpdateReorderLevelsRequestDto sets both levels; a null value turns the level off.
+type UpdateReorderLevelsRequestDto struct {
+	ReorderPoint    *float64 `json:"reorder_point" validate:"omitempty,gte=0"`
+	ReorderQuantity *float64 `json:"reorder_quantity" validate:"omitempty,gt=0"`
+}
+
+func ToMedicineDto(medicine *models.Medicine) MedicineResponseDto {
+	var categoryName *string
+	if medicine.Category != nil {
+		categoryName = &medicine.Category.Name
+	}
+	units := make([]MedicineUnitDto, len(medicine.Units))
+	for i := range medicine.Units {
+		units[i] = ToMedicineUnitDto(&medicine.Units[i], medicine.Unit)
+	}
+	return MedicineResponseDto{
+		ID:                  medicine.ID.String(),
+		SKU:                 medicine.SKU,
+		Name:                medicine.Name,
+		GenericName:         medicine.GenericName,
+		BrandName:           medicine.BrandName,
+		NameTh:              medicine.NameTh,
+		ATCCode:             medicine.ATCCode,
+		CategoryName:        categoryName,
+		StrengthValue:       medicine.StrengthValue,
+		StrengthUnit:        medicine.StrengthUnit,
+		Strength:            medicine.Strength(),
+		Price:               medicine.Price,
+		Stock:               medicine.Stock,
+		Reserved:            medicine.Reserved,
+		Available:           medicine.Available(),
+		Unit:                medicine.Unit,
+		Classification:      string(medicine.Classification),
+		VATTreatment:        string(medicine.VATTreatment),
+		MaxQuantityPerOrder: medicine.MaxQuantityPerOrder,
+		ReorderPoint:        medicine.ReorderPoint,
+		ReorderQuantity:     medicine.ReorderQuantity,
+		Units:               units,
+		CreatedAt:           medicine.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:           medicine.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func ToMedicineUnitDto(unit *models.MedicineUnit, baseUnit string) MedicineUnitDto {
+	return MedicineUnitDto{
+		ID:     unit.ID.String(),
+		Name:   unit.Name,
+		Label:  unit.Label(baseUnit),
+		Factor: unit.Factor,
+		Price:  unit.Price,
+		IsBase: unit.IsBase,
+	}
+}
+
+// UpsertMedicineUnitRequestDto adds a sellable unit or changes the factor and price of an
+// existing one with the same name. The base unit's factor is always 1.
+type UpsertMedicineUnitRequestDto struct {
+	Name   string  `json:"name" validate:"required"`
+	Factor float64 `json:"factor" validate:"gt=0"`
+	Price  float64 `json:"price" validate:"gte=0"`
+}
+
+type TherapeuticCategoryDto struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	NameTh *string `json:"name_th"`
+	Level  int     `json:"level"`
+}
+
+type GetTherapeuticCategoriesResponseDto struct {
+	Categories []TherapeuticCategoryDto `json:"categories"`
+	Total      int                      `json:"total"`
+}
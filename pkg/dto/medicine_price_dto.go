@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	PriceStatusPast      = "past"
+	PriceStatusCurrent   = "current"
+	PriceStatusScheduled = "scheduled"
+)
+
+// ScheduleMedicinePriceRequestDto sets a new price for one unit of a medicine. Without
+// effective_from the price applies immediately; a future time schedules the change.
+type ScheduleMedicinePriceRequestDto struct {
+	// unit the price is for; defaults to the medicine's base unit
+	UnitID        *uuid.UUID `json:"unit_id"`
+	Price         float64    `json:"price" validate:"gte=0"`
+	EffectiveFrom *string    `json:"effective_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Reason        *string    `json:"reason"`
+}
+
+type MedicinePriceDto struct {
+	ID            string  `json:"id"`
+	UnitID        string  `json:"unit_id"`
+	UnitName      string  `json:"unit_name"`
+	Price         float64 `json:"price"`
+	EffectiveFrom string  `json:"effective_from"`
+	EffectiveTo   *string `json:"effective_to"`
+	Status        string  `json:"status"`
+	Reason        *string `json:"reason"`
+	CreatedBy     *string `json:"created_by"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type GetMedicinePriceHistoryResponseDto struct {
+	MedicineID string             `json:"medicine_id"`
+	Prices     []MedicinePriceDto `json:"prices"`
+	Total      int                `json:"total"`
+}
+
+type CancelMedicinePriceResponseDto struct {
+	PriceID string `json:"price_id"`
+	Status  string `json:"status"`
+}
+
+func ToMedicinePriceDto(price *models.MedicinePrice, now time.Time) MedicinePriceDto {
+	status := PriceStatusPast
+	if price.IsEffectiveAt(now) {
+		status = PriceStatusCurrent
+	} else if price.EffectiveFrom.After(now) {
+		status = PriceStatusScheduled
+	}
+	unitName := ""
+	if price.Unit != nil {
+		unitName = price.Unit.Name
+	}
+	item := MedicinePriceDto{
+		ID:            price.ID.String(),
+		UnitID:        price.UnitID.String(),
+		UnitName:      unitName,
+		Price:         price.Price,
+		EffectiveFrom: price.EffectiveFrom.Format("2006-01-02T15:04:05Z07:00"),
+		Status:        status,
+		Reason:        price.Reason,
+		CreatedBy:     uuidString(price.CreatedBy),
+		CreatedAt:     price.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if price.EffectiveTo != nil {
+		effectiveTo := price.EffectiveTo.Format("2006-01-02T15:04:05Z07:00")
+		item.EffectiveTo = &effectiveTo
+	}
+	return item
+}
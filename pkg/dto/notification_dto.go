@@ -0,0 +1,89 @@
+package dto
+
+import "order-service/pkg/models"
+
+// NotificationPreferenceRequestDto sets how the caller is notified. A channel can only
+// be turned on with its address.
+type NotificationPreferenceRequestDto struct {
+	Language     string  `json:"language" validate:"required,oneof=th en"`
+	Email        *string `json:"email" validate:"omitempty,email"`
+	EmailEnabled bool    `json:"email_enabled"`
+	// E.164, e.g. +66812345678
+	Phone      *string `json:"phone" validate:"omitempty,e164"`
+	SMSEnabled bool    `json:"sms_enabled"`
+	// the user ID LINE gives the pharmacy's Official Account, e.g. U4af4980629...
+	LINEUserID  *string `json:"line_user_id" validate:"omitempty,startswith=U,len=33"`
+	LINEEnabled bool    `json:"line_enabled"`
+}
+
+type NotificationPreferenceDto struct {
+	UserID       string  `json:"user_id"`
+	Language     string  `json:"language"`
+	Email        *string `json:"email"`
+	EmailEnabled bool    `json:"email_enabled"`
+	Phone        *string `json:"phone"`
+	SMSEnabled   bool    `json:"sms_enabled"`
+	LINEUserID   *string `json:"line_user_id"`
+	LINEEnabled  bool    `json:"line_enabled"`
+	UpdatedAt    *string `json:"updated_at"`
+}
+
+type NotificationLogDto struct {
+	ID        string  `json:"id"`
+	OrderID   *string `json:"order_id"`
+	EventID   string  `json:"event_id"`
+	Message   string  `json:"message"`
+	Channel   string  `json:"channel"`
+	Recipient string  `json:"recipient"`
+	Language  string  `json:"language"`
+	Subject   string  `json:"subject"`
+	Body      string  `json:"body"`
+	Status    string  `json:"status"`
+	Error     *string `json:"error"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type GetNotificationLogResponseDto struct {
+	UserID        string               `json:"user_id"`
+	Notifications []NotificationLogDto `json:"notifications"`
+	Total         int                  `json:"total"`
+}
+
+func ToNotificationPreferenceDto(preference *models.NotificationPreference) NotificationPreferenceDto {
+	res := NotificationPreferenceDto{
+		UserID:       preference.UserID.String(),
+		Language:     preference.Language,
+		Email:        preference.Email,
+		EmailEnabled: preference.EmailEnabled,
+		Phone:        preference.Phone,
+		SMSEnabled:   preference.SMSEnabled,
+		LINEUserID:   preference.LINEUserID,
+		LINEEnabled:  preference.LINEEnabled,
+	}
+	if !preference.UpdatedAt.IsZero() {
+		updatedAt := preference.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+		res.UpdatedAt = &updatedAt
+	}
+	return res
+}
+
+func ToNotificationLogDto(entry *models.NotificationLog) NotificationLogDto {
+	res := NotificationLogDto{
+		ID:        entry.ID.String(),
+		EventID:   entry.EventID.String(),
+		Message:   entry.Message,
+		Channel:   entry.Channel,
+		Recipient: entry.Recipient,
+		Language:  entry.Language,
+		Subject:   entry.Subject,
+		Body:      entry.Body,
+		Status:    string(entry.Status),
+		Error:     entry.Error,
+		CreatedAt: entry.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if entry.OrderID != nil {
+		orderID := entry.OrderID.String()
+		res.OrderID = &orderID
+	}
+	return res
+}
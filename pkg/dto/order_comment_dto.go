@@ -0,0 +1,56 @@
+package dto
+
+import "order-service/pkg/models"
+
+type RequestOrderChangesRequestDto struct {
+	OrderID string `json:"order_id"`
+	// Comment tells the patient what to change; it starts or continues the order's
+	// comment thread.
+	Comment string `json:"comment"`
+}
+
+type ResubmitOrderRequestDto struct {
+	OrderID string `json:"order_id"`
+	// Comment answers the doctor's change request; Note replaces the order's note when set.
+	Comment *string `json:"comment"`
+	Note    *string `json:"note"`
+	// RequestedItems replaces the medicines the patient asked for when given; the doctor
+	// reviews the new request from scratch.
+	RequestedItems []RequestedItemInput `json:"requested_items" validate:"omitempty,dive"`
+}
+
+type OrderReviewResponseDto struct {
+	OrderID string           `json:"order_id"`
+	Status  string           `json:"status"`
+	Comment *OrderCommentDto `json:"comment"`
+}
+
+type AddOrderCommentRequestDto struct {
+	Body string `json:"body"`
+}
+
+type OrderCommentDto struct {
+	CommentID  string `json:"comment_id"`
+	AuthorID   string `json:"author_id"`
+	AuthorRole string `json:"author_role"`
+	Kind       string `json:"kind"`
+	Body       string `json:"body"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type GetOrderCommentsResponseDto struct {
+	OrderID  string            `json:"order_id"`
+	Status   string            `json:"status"`
+	Comments []OrderCommentDto `json:"comments"`
+}
+
+func ToOrderCommentDto(comment *models.OrderComment) OrderCommentDto {
+	return OrderCommentDto{
+		CommentID:  comment.ID.String(),
+		AuthorID:   comment.AuthorID.String(),
+		AuthorRole: comment.AuthorRole,
+		Kind:       string(comment.Kind),
+		Body:       comment.Body,
+		CreatedAt:  comment.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"order-service/pkg/models"
+)
+
+// UpdateOrderDeliveryRequestDto moves a paid order along its fulfilment: processing
+// while it is being packed, shipped once it leaves the pharmacy and delivered once the
+// patient has it.
+type UpdateOrderDeliveryRequestDto struct {
+	OrderID string `json:"order_id" validate:"required,uuid"`
+	Status  string `json:"status" validate:"required,oneof=processing shipped delivered"`
+	// TrackingNumber is the carrier's tracking number, recorded when the order ships.
+	TrackingNumber *string `json:"tracking_number" validate:"omitempty"`
+	// DeliveryInformationID is the address the order ships to; the patient's latest
+	// delivery address is used without it.
+	DeliveryInformationID *string `json:"delivery_information_id" validate:"omitempty,uuid"`
+}
+
+type OrderDeliveryDto struct {
+	ID                    string     `json:"id"`
+	DeliveryInformationID string     `json:"delivery_information_id"`
+	TrackingNumber        *string    `json:"tracking_number"`
+	Status                string     `json:"status"`
+	DeliveredAt           *time.Time `json:"delivered_at"`
+}
+
+type UpdateOrderDeliveryResponseDto struct {
+	OrderID  string            `json:"order_id"`
+	Status   string            `json:"status"`
+	Delivery *OrderDeliveryDto `json:"delivery"`
+}
+
+// ToOrderDeliveryDto returns nil for orders that have not shipped yet.
+func ToOrderDeliveryDto(delivery *models.Delivery) *OrderDeliveryDto {
+	if delivery == nil {
+		return nil
+	}
+	return &OrderDeliveryDto{
+		ID:                    delivery.ID.String(),
+		DeliveryInformationID: delivery.DeliveryInformation.String(),
+		TrackingNumber:        delivery.TrackingNumber,
+		Status:                string(delivery.Status),
+		DeliveredAt:           delivery.DeliveredAt,
+	}
+}
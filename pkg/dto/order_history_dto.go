@@ -0,0 +1,36 @@
+package dto
+
+import "order-service/pkg/models"
+
+type OrderStatusChangeDto struct {
+	FromStatus *string `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+	ActorID    *string `json:"actor_id"`
+	ActorRole  string  `json:"actor_role"`
+	Reason     *string `json:"reason"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+type GetOrderHistoryResponseDto struct {
+	OrderID string                 `json:"order_id"`
+	Status  string                 `json:"status"`
+	Changes []OrderStatusChangeDto `json:"changes"`
+}
+
+func ToOrderStatusChangeDto(change *models.OrderStatusChange) OrderStatusChangeDto {
+	res := OrderStatusChangeDto{
+		ToStatus:  string(change.ToStatus),
+		ActorRole: change.ActorRole,
+		Reason:    change.Reason,
+		CreatedAt: change.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if change.FromStatus != nil {
+		from := string(*change.FromStatus)
+		res.FromStatus = &from
+	}
+	if change.ActorID != nil {
+		actorID := change.ActorID.String()
+		res.ActorID = &actorID
+	}
+	return res
+}
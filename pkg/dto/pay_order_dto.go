@@ -1,10 +1,21 @@
 package dto
 
 type PayOrderRequestDto struct {
-	OrderID string `json:"order_id"`
+	OrderID       string  `json:"order_id"`
+	PaymentMethod *string `json:"payment_method" validate:"omitempty,oneof=cash card promptpay bank_transfer"`
+	// TaxInvoice asks for a full tax invoice; without it an abbreviated receipt is issued.
+	TaxInvoice *TaxInvoiceBuyerDto `json:"tax_invoice" validate:"omitempty"`
 }
 
 type PayOrderResponseDto struct {
 	OrderID string `json:"order_id"`
 	Status  string `json:"status"`
+	// AmountCharged is what the patient paid, delivery fee included; InsurerAmount is
+	// claimed from the entitlement the order was covered under.
+	AmountCharged float64 `json:"amount_charged"`
+	InsurerAmount float64 `json:"insurer_amount"`
+	Entitlement   *string `json:"entitlement"`
+	VATAmount     float64 `json:"vat_amount"`
+	// TaxInvoice is the tax invoice or receipt issued for the payment.
+	TaxInvoice TaxInvoiceDto `json:"tax_invoice"`
 }
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// PromotionRequestDto creates or replaces a promotion. Without a coupon code the
+// promotion applies automatically to every order it qualifies for; without starts_at it
+// starts immediately.
+type PromotionRequestDto struct {
+	Name  string  `json:"name" validate:"required"`
+	Kind  string  `json:"kind" validate:"required,oneof=percentage fixed"`
+	Value float64 `json:"value" validate:"gt=0"`
+	// order discounts the whole order; medicine discounts the items of medicine_id
+	Scope           string     `json:"scope" validate:"required,oneof=order medicine"`
+	MedicineID      *uuid.UUID `json:"medicine_id"`
+	CouponCode      *string    `json:"coupon_code" validate:"omitempty,min=3,max=32"`
+	MinOrderAmount  *float64   `json:"min_order_amount" validate:"omitempty,gte=0"`
+	MaxDiscount     *float64   `json:"max_discount" validate:"omitempty,gt=0"`
+	UsageLimit      *int       `json:"usage_limit" validate:"omitempty,gt=0"`
+	PerPatientLimit *int       `json:"per_patient_limit" validate:"omitempty,gt=0"`
+	// whether the promotion may be combined with other promotions
+	Stackable bool    `json:"stackable"`
+	Active    *bool   `json:"active"`
+	StartsAt  *string `json:"starts_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndsAt    *string `json:"ends_at" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+type PromotionDto struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	Value           float64  `json:"value"`
+	Scope           string   `json:"scope"`
+	MedicineID      *string  `json:"medicine_id"`
+	MedicineName    *string  `json:"medicine_name"`
+	CouponCode      *string  `json:"coupon_code"`
+	MinOrderAmount  *float64 `json:"min_order_amount"`
+	MaxDiscount     *float64 `json:"max_discount"`
+	UsageLimit      *int     `json:"usage_limit"`
+	PerPatientLimit *int     `json:"per_patient_limit"`
+	Stackable       bool     `json:"stackable"`
+	Active          bool     `json:"active"`
+	StartsAt        string   `json:"starts_at"`
+	EndsAt          *string  `json:"ends_at"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+type GetPromotionsResponseDto struct {
+	Promotions []PromotionDto `json:"promotions"`
+	Total      int            `json:"total"`
+}
+
+type DeletePromotionResponseDto struct {
+	PromotionID string `json:"promotion_id"`
+	Status      string `json:"status"`
+}
+
+func ToPromotionDto(promotion *models.Promotion) PromotionDto {
+	res := PromotionDto{
+		ID:              promotion.ID.String(),
+		Name:            promotion.Name,
+		Kind:            string(promotion.Kind),
+		Value:           promotion.Value,
+		Scope:           string(promotion.Scope),
+		MedicineID:      uuidString(promotion.MedicineID),
+		CouponCode:      promotion.CouponCode,
+		MinOrderAmount:  promotion.MinOrderAmount,
+		MaxDiscount:     promotion.MaxDiscount,
+		UsageLimit:      promotion.UsageLimit,
+		PerPatientLimit: promotion.PerPatientLimit,
+		Stackable:       promotion.Stackable,
+		Active:          promotion.Active,
+		StartsAt:        promotion.StartsAt.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt:       promotion.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:       promotion.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if promotion.Medicine != nil {
+		res.MedicineName = &promotion.Medicine.Name
+	}
+	if promotion.EndsAt != nil {
+		endsAt := promotion.EndsAt.Format("2006-01-02T15:04:05Z07:00")
+		res.EndsAt = &endsAt
+	}
+	return res
+}
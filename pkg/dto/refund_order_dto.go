@@ -0,0 +1,80 @@
+package dto
+
+import "order-service/pkg/models"
+
+// RefundItemRequestDto gives back a quantity of an order item, counted in the unit it
+// was ordered in.
+type RefundItemRequestDto struct {
+	OrderItemID string  `json:"order_item_id" validate:"required,uuid"`
+	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// RefundOrderRequestDto refunds the listed items, or everything not yet refunded when
+// Items is empty.
+type RefundOrderRequestDto struct {
+	OrderID string                 `json:"order_id" validate:"required,uuid"`
+	Reason  string                 `json:"reason" validate:"required"`
+	Items   []RefundItemRequestDto `json:"items" validate:"omitempty,dive"`
+}
+
+type RefundItemDto struct {
+	OrderItemID   string  `json:"order_item_id"`
+	Quantity      float64 `json:"quantity"`
+	Amount        float64 `json:"amount"`
+	InsurerAmount float64 `json:"insurer_amount"`
+}
+
+type RefundDto struct {
+	ID        string `json:"id"`
+	OrderID   string `json:"order_id"`
+	PaymentID string `json:"payment_id"`
+	// Amount is paid back to the patient; InsurerAmount is taken off the insurer claim.
+	Amount            float64         `json:"amount"`
+	InsurerAmount     float64         `json:"insurer_amount"`
+	Reason            string          `json:"reason"`
+	Status            string          `json:"status"`
+	ProviderReference *string         `json:"provider_reference"`
+	ActorID           *string         `json:"actor_id"`
+	ActorRole         string          `json:"actor_role"`
+	CreatedAt         string          `json:"created_at"`
+	Items             []RefundItemDto `json:"items"`
+}
+
+type RefundOrderResponseDto struct {
+	OrderID string    `json:"order_id"`
+	Status  string    `json:"status"`
+	Refund  RefundDto `json:"refund"`
+	// RefundedAmount is everything paid back on the order so far, this refund included.
+	RefundedAmount float64 `json:"refunded_amount"`
+}
+
+func ToRefundDto(refund *models.Refund) RefundDto {
+	items := make([]RefundItemDto, len(refund.Items))
+	for i, item := range refund.Items {
+		items[i] = RefundItemDto{
+			OrderItemID:   item.OrderItemID.String(),
+			Quantity:      item.Quantity,
+			Amount:        item.Amount,
+			InsurerAmount: item.InsurerAmount,
+		}
+	}
+	var actorID *string
+	if refund.ActorID != nil {
+		id := refund.ActorID.String()
+		actorID = &id
+	}
+	return RefundDto{
+		ID:                refund.ID.String(),
+		OrderID:           refund.OrderID.String(),
+		PaymentID:         refund.PaymentID.String(),
+		Amount:            refund.Amount,
+		InsurerAmount:     refund.InsurerAmount,
+		Reason:            refund.Reason,
+		Status:            string(refund.Status),
+		ProviderReference: refund.ProviderReference,
+		ActorID:           actorID,
+		ActorRole:         refund.ActorRole,
+		CreatedAt:         refund.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Items:             items,
+	}
+}
@@ -1,10 +1,36 @@
 package dto
 
+import "order-service/pkg/models"
+
 type RejectOrderRequestDto struct {
 	OrderID string `json:"order_id"`
+	// ReasonCode is one of not_indicated, contraindicated, allergy, drug_interaction,
+	// duplicate_therapy, consultation_required, insufficient_information or other.
+	ReasonCode string `json:"reason_code"`
+	// Reason explains the rejection to the patient; it is required for other.
+	Reason *string `json:"reason"`
 }
 
 type RejectOrderResponseDto struct {
-	OrderID string `json:"order_id"`
-	Status  string `json:"status"`
+	OrderID   string             `json:"order_id"`
+	Status    string             `json:"status"`
+	Rejection *OrderRejectionDto `json:"rejection"`
+}
+
+// OrderRejectionDto is why the doctor rejected an order.
+type OrderRejectionDto struct {
+	ReasonCode string  `json:"reason_code"`
+	Reason     *string `json:"reason"`
+}
+
+// ToOrderRejectionDto returns nil for orders rejected without a reason code, which
+// includes every order rejected before reasons were recorded.
+func ToOrderRejectionDto(order *models.Order) *OrderRejectionDto {
+	if order.Status != models.OrderStatusRejected || order.RejectionReasonCode == nil {
+		return nil
+	}
+	return &OrderRejectionDto{
+		ReasonCode: string(*order.RejectionReasonCode),
+		Reason:     order.RejectionReason,
+	}
 }
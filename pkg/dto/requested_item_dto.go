@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+type RequestedItemInput struct {
+	MedicineID uuid.UUID `json:"medicine_id" validate:"required"`
+	Quantity   float64   `json:"quantity" validate:"gt=0"`
+	// sellable unit the quantity is counted in; defaults to the medicine's base unit
+	UnitID *uuid.UUID `json:"unit_id"`
+	Reason *string    `json:"reason"`
+}
+
+type RequestedItemDecisionInput struct {
+	RequestedItemID uuid.UUID `json:"requested_item_id" validate:"required"`
+	Action          string    `json:"action" validate:"required,oneof=accept modify strike"`
+	Quantity        *float64  `json:"quantity" validate:"omitempty,gt=0"`
+	Note            *string   `json:"note"`
+	// dosage for the resulting order item when the request is accepted or modified
+	Dosage *DosageInput `json:"dosage" validate:"omitempty"`
+}
+
+type RequestedItem struct {
+	ID               string   `json:"id"`
+	MedicineID       string   `json:"medicine_id"`
+	MedicineName     string   `json:"medicine_name"`
+	Quantity         float64  `json:"quantity"`
+	UnitID           *string  `json:"unit_id"`
+	UnitLabel        string   `json:"unit_label"`
+	UnitFactor       float64  `json:"unit_factor"`
+	Reason           *string  `json:"reason"`
+	Status           string   `json:"status"`
+	ApprovedQuantity *float64 `json:"approved_quantity"`
+	DoctorNote       *string  `json:"doctor_note"`
+}
+
+// Conversion functions
+func ToRequestedItemDtoList(items []models.OrderRequestedItem) []RequestedItem {
+	result := make([]RequestedItem, len(items))
+	for i, item := range items {
+		medicineName, unit := "", ""
+		if item.Medicine != nil {
+			medicineName, unit = item.Medicine.Name, item.Medicine.Unit
+		}
+		result[i] = RequestedItem{
+			ID:               item.ID.String(),
+			MedicineID:       item.MedicineID.String(),
+			MedicineName:     medicineName,
+			Quantity:         item.Quantity,
+			UnitID:           uuidString(item.UnitID),
+			UnitLabel:        unitLabel(item.Unit, unit),
+			UnitFactor:       item.UnitFactor,
+			Reason:           item.Reason,
+			Status:           string(item.Status),
+			ApprovedQuantity: item.ApprovedQuantity,
+			DoctorNote:       item.DoctorNote,
+		}
+	}
+	return result
+}
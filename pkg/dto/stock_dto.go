@@ -0,0 +1,65 @@
+package dto
+
+import "github.com/google/uuid"
+
+type ReceiveStockRequestDto struct {
+	MedicineID uuid.UUID `json:"medicine_id" validate:"required"`
+	LotNumber  string    `json:"lot_number" validate:"required"`
+	// YYYY-MM-DD; omit for stock without an expiry date
+	ExpiryDate *string `json:"expiry_date" validate:"omitempty,datetime=2006-01-02"`
+	Quantity   float64 `json:"quantity" validate:"gt=0"`
+	Reason     *string `json:"reason"`
+}
+
+type ReceiveStockResponseDto struct {
+	Batch         MedicineBatchResponseDto `json:"batch"`
+	MedicineStock float64                  `json:"medicine_stock"`
+}
+
+type StockAdjustmentRequestDto struct {
+	BatchID         uuid.UUID `json:"batch_id" validate:"required"`
+	CountedQuantity float64   `json:"counted_quantity" validate:"gte=0"`
+	Reason          string    `json:"reason" validate:"required"`
+}
+
+type StockAdjustmentResponseDto struct {
+	Batch         MedicineBatchResponseDto `json:"batch"`
+	Delta         float64                  `json:"delta"`
+	MedicineStock float64                  `json:"medicine_stock"`
+}
+
+type StockMovementResponseDto struct {
+	ID            string  `json:"id"`
+	MedicineID    string  `json:"medicine_id"`
+	BatchID       *string `json:"batch_id"`
+	LotNumber     *string `json:"lot_number"`
+	OrderID       *string `json:"order_id"`
+	MovementType  string  `json:"movement_type"`
+	StockDelta    float64 `json:"stock_delta"`
+	ReservedDelta float64 `json:"reserved_delta"`
+	ActorID       *string `json:"actor_id"`
+	ActorRole     string  `json:"actor_role"`
+	Reason        string  `json:"reason"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type GetStockMovementsResponseDto struct {
+	Movements []StockMovementResponseDto `json:"movements"`
+	Total     int                        `json:"total"`
+}
+
+type StockDriftDto struct {
+	MedicineID     string  `json:"medicine_id"`
+	MedicineName   string  `json:"medicine_name"`
+	Stock          float64 `json:"stock"`
+	LedgerStock    float64 `json:"ledger_stock"`
+	Reserved       float64 `json:"reserved"`
+	LedgerReserved float64 `json:"ledger_reserved"`
+	BatchQuantity  float64 `json:"batch_quantity"`
+}
+
+type StockReconciliationResponseDto struct {
+	CheckedAt string          `json:"checked_at"`
+	Medicines int             `json:"medicines"`
+	Drifts    []StockDriftDto `json:"drifts"`
+}
@@ -0,0 +1,102 @@
+package dto
+
+import "order-service/pkg/models"
+
+// TaxInvoiceBuyerDto asks for a full tax invoice made out to the buyer instead of an
+// abbreviated receipt. The branch defaults to the head office.
+type TaxInvoiceBuyerDto struct {
+	Name    string  `json:"name" validate:"required"`
+	TaxID   string  `json:"tax_id" validate:"required,len=13,numeric"`
+	Branch  *string `json:"branch" validate:"omitempty,len=5,numeric"`
+	Address string  `json:"address" validate:"required"`
+}
+
+type TaxInvoiceLineDto struct {
+	LineNo         int     `json:"line_no"`
+	OrderItemID    string  `json:"order_item_id"`
+	Description    string  `json:"description"`
+	Quantity       float64 `json:"quantity"`
+	UnitLabel      string  `json:"unit_label"`
+	UnitPrice      float64 `json:"unit_price"`
+	DiscountAmount float64 `json:"discount_amount"`
+	Amount         float64 `json:"amount"`
+	VATTreatment   string  `json:"vat_treatment"`
+	VATAmount      float64 `json:"vat_amount"`
+}
+
+type TaxInvoiceDto struct {
+	ID             string              `json:"id"`
+	Number         string              `json:"number"`
+	DocumentType   string              `json:"document_type"`
+	OrderID        string              `json:"order_id"`
+	PaymentID      string              `json:"payment_id"`
+	SellerName     string              `json:"seller_name"`
+	SellerTaxID    string              `json:"seller_tax_id"`
+	SellerBranch   string              `json:"seller_branch"`
+	SellerAddress  string              `json:"seller_address"`
+	BuyerName      string              `json:"buyer_name"`
+	BuyerTaxID     *string             `json:"buyer_tax_id"`
+	BuyerBranch    *string             `json:"buyer_branch"`
+	BuyerAddress   *string             `json:"buyer_address"`
+	SubtotalAmount float64             `json:"subtotal_amount"`
+	DiscountAmount float64             `json:"discount_amount"`
+	VatableAmount  float64             `json:"vatable_amount"`
+	ExemptAmount   float64             `json:"exempt_amount"`
+	VATRate        float64             `json:"vat_rate"`
+	VATAmount      float64             `json:"vat_amount"`
+	TotalAmount    float64             `json:"total_amount"`
+	Entitlement    *string             `json:"entitlement"`
+	InsurerAmount  float64             `json:"insurer_amount"`
+	PatientAmount  float64             `json:"patient_amount"`
+	IssuedAt       string              `json:"issued_at"`
+	Lines          []TaxInvoiceLineDto `json:"lines"`
+}
+
+type GetTaxInvoicesResponseDto struct {
+	Invoices []TaxInvoiceDto `json:"invoices"`
+}
+
+func ToTaxInvoiceDto(invoice *models.TaxInvoice) TaxInvoiceDto {
+	lines := make([]TaxInvoiceLineDto, len(invoice.Lines))
+	for i, line := range invoice.Lines {
+		lines[i] = TaxInvoiceLineDto{
+			LineNo:         line.LineNo,
+			OrderItemID:    line.OrderItemID.String(),
+			Description:    line.Description,
+			Quantity:       line.Quantity,
+			UnitLabel:      line.UnitLabel,
+			UnitPrice:      line.UnitPrice,
+			DiscountAmount: line.DiscountAmount,
+			Amount:         line.Amount,
+			VATTreatment:   string(line.VATTreatment),
+			VATAmount:      line.VATAmount,
+		}
+	}
+	return TaxInvoiceDto{
+		ID:             invoice.ID.String(),
+		Number:         invoice.Number,
+		DocumentType:   string(invoice.DocumentType),
+		OrderID:        invoice.OrderID.String(),
+		PaymentID:      invoice.PaymentID.String(),
+		SellerName:     invoice.SellerName,
+		SellerTaxID:    invoice.SellerTaxID,
+		SellerBranch:   invoice.SellerBranch,
+		SellerAddress:  invoice.SellerAddress,
+		BuyerName:      invoice.BuyerName,
+		BuyerTaxID:     invoice.BuyerTaxID,
+		BuyerBranch:    invoice.BuyerBranch,
+		BuyerAddress:   invoice.BuyerAddress,
+		SubtotalAmount: invoice.SubtotalAmount,
+		DiscountAmount: invoice.DiscountAmount,
+		VatableAmount:  invoice.VatableAmount,
+		ExemptAmount:   invoice.ExemptAmount,
+		VATRate:        invoice.VATRate,
+		VATAmount:      invoice.VATAmount,
+		TotalAmount:    invoice.TotalAmount,
+		Entitlement:    invoice.Entitlement,
+		InsurerAmount:  invoice.InsurerAmount,
+		PatientAmount:  invoice.PatientAmount,
+		IssuedAt:       invoice.IssuedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Lines:          lines,
+	}
+}
@@ -2,16 +2,29 @@ package dto
 
 import "github.com/google/uuid"
 
+type DosageInput struct {
+	Dose            float64 `json:"dose" validate:"gt=0"`
+	FrequencyPerDay float64 `json:"frequency_per_day" validate:"gt=0"`
+	Route           string  `json:"route" validate:"required,oneof=oral sublingual topical inhalation ophthalmic otic nasal rectal vaginal injection transdermal"`
+	DurationDays    int     `json:"duration_days" validate:"gt=0"`
+	Instructions    *string `json:"instructions"`
+}
+
 type OrderItemInput struct {
-	MedicineID uuid.UUID `json:"medicine_id"`
-	Quantity   float64   `json:"quantity"`
+	MedicineID uuid.UUID    `json:"medicine_id"`
+	Quantity   float64      `json:"quantity"`
+	UnitID     *uuid.UUID   `json:"unit_id"`
+	Dosage     *DosageInput `json:"dosage" validate:"omitempty"`
 }
 
 type UpdateOrderRequestDto struct {
 	OrderID    string           `json:"order_id"`
 	OrderItems []OrderItemInput `json:"order_items"`
+	// decisions on the patient's requested items; accepted and modified items become order items
+	RequestedItemDecisions []RequestedItemDecisionInput `json:"requested_item_decisions" validate:"omitempty,dive"`
 }
 
 type UpdateOrderResponseDto struct {
-	OrderID string `json:"order_id"`
+	OrderID          string            `json:"order_id"`
+	ClinicalFindings []ClinicalFinding `json:"clinical_findings"`
 }
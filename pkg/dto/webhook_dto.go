@@ -0,0 +1,120 @@
+package dto
+
+import "order-service/pkg/models"
+
+// WebhookSubscriptionRequestDto creates a subscription. Without a secret one is
+// generated; either way it is only returned in this response.
+type WebhookSubscriptionRequestDto struct {
+	Name string `json:"name" validate:"required"`
+	URL  string `json:"url" validate:"required,url"`
+	// event types such as order.paid, patterns such as order.*, or * for every event;
+	// every event when empty
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,required"`
+	Secret     *string  `json:"secret" validate:"omitempty,min=16"`
+}
+
+// UpdateWebhookSubscriptionRequestDto changes a subscription. Setting active enables a
+// disabled subscription again and clears its failure count; a new secret replaces the
+// old one.
+type UpdateWebhookSubscriptionRequestDto struct {
+	Name       string   `json:"name" validate:"required"`
+	URL        string   `json:"url" validate:"required,url"`
+	EventTypes []string `json:"event_types" validate:"omitempty,dive,required"`
+	Active     bool     `json:"active"`
+	Secret     *string  `json:"secret" validate:"omitempty,min=16"`
+}
+
+type WebhookSubscriptionDto struct {
+	ID                  string   `json:"id"`
+	Name                string   `json:"name"`
+	URL                 string   `json:"url"`
+	EventTypes          []string `json:"event_types"`
+	Active              bool     `json:"active"`
+	ConsecutiveFailures int      `json:"consecutive_failures"`
+	DisabledAt          *string  `json:"disabled_at"`
+	DisabledReason      *string  `json:"disabled_reason"`
+	// only returned when the subscription is created or its secret is changed
+	Secret    *string `json:"secret,omitempty"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type GetWebhookSubscriptionsResponseDto struct {
+	Subscriptions []WebhookSubscriptionDto `json:"subscriptions"`
+	Total         int                      `json:"total"`
+}
+
+type DeleteWebhookSubscriptionResponseDto struct {
+	SubscriptionID string `json:"subscription_id"`
+	Status         string `json:"status"`
+}
+
+type WebhookDeliveryDto struct {
+	ID             string  `json:"id"`
+	SubscriptionID string  `json:"subscription_id"`
+	EventID        string  `json:"event_id"`
+	EventType      string  `json:"event_type"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"next_attempt_at"`
+	LastStatusCode *int    `json:"last_status_code"`
+	LastError      *string `json:"last_error"`
+	LastAttemptAt  *string `json:"last_attempt_at"`
+	DeliveredAt    *string `json:"delivered_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+type GetWebhookDeliveriesResponseDto struct {
+	SubscriptionID string               `json:"subscription_id"`
+	Deliveries     []WebhookDeliveryDto `json:"deliveries"`
+	Total          int                  `json:"total"`
+}
+
+func ToWebhookSubscriptionDto(subscription *models.WebhookSubscription) WebhookSubscriptionDto {
+	res := WebhookSubscriptionDto{
+		ID:                  subscription.ID.String(),
+		Name:                subscription.Name,
+		URL:                 subscription.URL,
+		EventTypes:          subscription.EventTypes,
+		Active:              subscription.Active,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		DisabledReason:      subscription.DisabledReason,
+		CreatedAt:           subscription.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:           subscription.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if res.EventTypes == nil {
+		res.EventTypes = []string{}
+	}
+	if subscription.DisabledAt != nil {
+		disabledAt := subscription.DisabledAt.Format("2006-01-02T15:04:05Z07:00")
+		res.DisabledAt = &disabledAt
+	}
+	return res
+}
+
+func ToWebhookDeliveryDto(delivery *models.WebhookDelivery) WebhookDeliveryDto {
+	res := WebhookDeliveryDto{
+		ID:             delivery.ID.String(),
+		SubscriptionID: delivery.SubscriptionID.String(),
+		EventID:        delivery.EventID.String(),
+		EventType:      delivery.EventType,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if delivery.Status == models.WebhookDeliveryStatusPending {
+		nextAttemptAt := delivery.NextAttemptAt.Format("2006-01-02T15:04:05Z07:00")
+		res.NextAttemptAt = &nextAttemptAt
+	}
+	if delivery.LastAttemptAt != nil {
+		lastAttemptAt := delivery.LastAttemptAt.Format("2006-01-02T15:04:05Z07:00")
+		res.LastAttemptAt = &lastAttemptAt
+	}
+	if delivery.DeliveredAt != nil {
+		deliveredAt := delivery.DeliveredAt.Format("2006-01-02T15:04:05Z07:00")
+		res.DeliveredAt = &deliveredAt
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+// Package events publishes the service's domain events to other services. Events are
+// written to the outbox in the transaction that makes the change and published from
+// there, so every committed change is published at least once.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Order event types. Consumers may see an event more than once and should use its ID to
+// drop duplicates.
+const (
+	OrderCreated           = "order.created"
+	OrderApproved          = "order.approved"
+	OrderRejected          = "order.rejected"
+	OrderPaid              = "order.paid"
+	OrderProcessing        = "order.processing"
+	OrderShipped           = "order.shipped"
+	OrderDelivered         = "order.delivered"
+	OrderCancelled         = "order.cancelled"
+	OrderExpired           = "order.expired"
+	OrderRefunded          = "order.refunded"
+	OrderPartiallyRefunded = "order.partially_refunded"
+	// OrderChangesRequested is published when the doctor sends an order back to the
+	// patient, OrderPending when the patient resubmits it.
+	OrderChangesRequested = "order.changes_requested"
+	OrderPending          = "order.pending"
+)
+
+// Event is a domain event as it is published.
+type Event struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// OrderPayload is the payload of order events: the order as it is after the change.
+type OrderPayload struct {
+	OrderID        string  `json:"order_id"`
+	PatientID      string  `json:"patient_id"`
+	DoctorID       *string `json:"doctor_id,omitempty"`
+	AppointmentID  *string `json:"appointment_id,omitempty"`
+	Status         string  `json:"status"`
+	PreviousStatus *string `json:"previous_status,omitempty"`
+	TotalAmount    float64 `json:"total_amount"`
+	PatientAmount  float64 `json:"patient_amount"`
+	InsurerAmount  float64 `json:"insurer_amount"`
+	DeliveryFee    float64 `json:"delivery_fee"`
+	ActorID        *string `json:"actor_id,omitempty"`
+	ActorRole      string  `json:"actor_role"`
+	Reason         *string `json:"reason,omitempty"`
+	// ReasonCode is set on order.rejected events when the doctor picked a reason.
+	ReasonCode *string `json:"reason_code,omitempty"`
+}
+
+// Publisher delivers events to a broker or subscriber. A nil error means the event was
+// accepted; otherwise it is retried.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// MultiPublisher publishes every event to each of its publishers. It fails if any of
+// them fails, and the event is then retried on all of them.
+type MultiPublisher []Publisher
+
+func (m MultiPublisher) Publish(ctx context.Context, event Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
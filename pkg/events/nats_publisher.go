@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const natsFlushTimeout = 5 * time.Second
+
+// NATSPublisher publishes each event to the subject <prefix>.<event type>, e.g.
+// "pharmacy.order.paid", on a NATS server or anything that speaks its protocol. An
+// event counts as published once the server has acknowledged a flush after it.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher connects to the servers in url, a comma-separated list. The
+// connection reconnects by itself; events published while it is down fail and are retried.
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("order-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	return &NATSPublisher{conn: conn, prefix: prefix}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	msg := nats.NewMsg(p.prefix + "." + event.Type)
+	msg.Data = data
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	flushCtx, cancel := context.WithTimeout(ctx, natsFlushTimeout)
+	defer cancel()
+	if err := p.conn.FlushWithContext(flushCtx); err != nil {
+		return fmt.Errorf("failed to flush event: %w", err)
+	}
+	return nil
+}
+
+// Close drains the connection.
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
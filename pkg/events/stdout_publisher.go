@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+// StdoutPublisher writes each event as a line of JSON, to standard output by default.
+// It is meant for development and for log-shipping setups.
+type StdoutPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutPublisher() *StdoutPublisher {
+	return &StdoutPublisher{w: os.Stdout}
+}
+
+func (p *StdoutPublisher) Publish(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookPublisher posts each event as JSON to a URL. The event ID is sent in the
+// Idempotency-Key header so receivers can drop redeliveries.
+type WebhookPublisher struct {
+	url string
+	hc  *http.Client
+}
+
+func NewWebhookPublisher(url string) *WebhookPublisher {
+	return &WebhookPublisher{
+		url: url,
+		hc: &http.Client{
+			Timeout: webhookTimeout,
+		},
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := p.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event webhook returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CancellationHandler struct {
+	cancellationService *service.CancellationService
+}
+
+func NewCancellationHandler(cancellationService *service.CancellationService) *CancellationHandler {
+	return &CancellationHandler{
+		cancellationService: cancellationService,
+	}
+}
+
+// GetCancellationRules godoc
+// @Summary List cancellation rules
+// @Description Lists the cancellation window and fee set for each order status (admin only). Statuses without a rule can be cancelled by patients at any time free of charge.
+// @Tags cancellation
+// @Produce json
+// @Success 200 {object} dto.GetCancellationRulesResponseDto "Cancellation rules retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving cancellation rules"
+// @Router /api/order/v1/cancellation/rules [get]
+// @Security ApiKeyAuth
+func (h *CancellationHandler) GetCancellationRules(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.cancellationService.GetCancellationRules(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// SaveCancellationRule godoc
+// @Summary Set the cancellation rule of an order status
+// @Description Sets how many minutes after an order reaches the status patients may cancel it, and for paid statuses the fee kept from the refund (admin only). Patients may cancel pending, approved, paid and processing orders.
+// @Tags cancellation
+// @Accept json
+// @Produce json
+// @Param status path string true "Order status" Enums(pending, approved, paid, processing)
+// @Param request body dto.CancellationRuleRequestDto true "Cancellation window and fee"
+// @Success 200 {object} dto.CancellationRuleDto "Cancellation rule saved"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, status or fee"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while saving the cancellation rule"
+// @Router /api/order/v1/cancellation/rules/{status} [put]
+// @Security ApiKeyAuth
+func (h *CancellationHandler) SaveCancellationRule(c *fiber.Ctx) error {
+	status := c.Params("status")
+	if status == "" {
+		return response.BadRequest(c, "Order status is required")
+	}
+
+	var body dto.CancellationRuleRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.cancellationService.SaveCancellationRule(ctx, status, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// DeleteCancellationRule godoc
+// @Summary Delete the cancellation rule of an order status
+// @Description Removes the rule of an order status (admin only); patients can then cancel orders in it at any time free of charge.
+// @Tags cancellation
+// @Produce json
+// @Param status path string true "Order status" Enums(pending, approved, paid, processing)
+// @Success 200 {object} dto.DeleteCancellationRuleResponseDto "Cancellation rule deleted"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Cancellation rule not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while deleting the cancellation rule"
+// @Router /api/order/v1/cancellation/rules/{status} [delete]
+// @Security ApiKeyAuth
+func (h *CancellationHandler) DeleteCancellationRule(c *fiber.Ctx) error {
+	status := c.Params("status")
+	if status == "" {
+		return response.BadRequest(c, "Order status is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.cancellationService.DeleteCancellationRule(ctx, status)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
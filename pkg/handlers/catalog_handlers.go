@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"bytes"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+}
+
+func NewCatalogHandler(catalogService *service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+	}
+}
+
+// ImportCatalog godoc
+// @Summary Import the medicine catalog from CSV or XLSX
+// @Description Creates or updates medicines from an uploaded .csv or .xlsx file, matched by SKU (admin only). The first row names the columns; sku, name, unit and price are required, and columns left out of the file are not changed. Every row is validated and compared with the current catalog; nothing is applied when any row has an error or on a dry run. Price changes take effect immediately and are kept in the price history.
+// @Tags catalog
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Catalog file (.csv or .xlsx)"
+// @Param dry_run query bool false "Only report the changes the import would make"
+// @Success 200 {object} dto.CatalogImportResponseDto "Import result with per-row changes and errors"
+// @Failure 400 {object} response.ErrorResponse "Missing or unreadable file"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while importing the catalog"
+// @Router /api/medicine/v1/catalog/import [post]
+// @Security ApiKeyAuth
+func (h *CatalogHandler) ImportCatalog(c *fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.BadRequest(c, "A catalog file is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.BadRequest(c, "Cannot read the uploaded file")
+	}
+	defer file.Close()
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.catalogService.UploadCatalog(ctx, fileHeader.Filename, file, c.QueryBool("dry_run"))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// ExportCatalog godoc
+// @Summary Export the medicine catalog as CSV or XLSX
+// @Description Downloads every medicine in the layout the import endpoint reads, with current base-unit prices (admin only).
+// @Tags catalog
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "File format (default csv)" Enums(csv, xlsx)
+// @Success 200 {file} file "Catalog file"
+// @Failure 400 {object} response.ErrorResponse "Unsupported format"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while exporting the catalog"
+// @Router /api/medicine/v1/catalog/export [get]
+// @Security ApiKeyAuth
+func (h *CatalogHandler) ExportCatalog(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", "csv"))
+
+	var buf bytes.Buffer
+	ctx := contextUtils.GetContext(c)
+	if err := h.catalogService.DownloadCatalog(ctx, &buf, format); err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		contentType = mimeXLSX
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="medicines.`+format+`"`)
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CoverageHandler struct {
+	coverageService *service.CoverageService
+}
+
+func NewCoverageHandler(coverageService *service.CoverageService) *CoverageHandler {
+	return &CoverageHandler{
+		coverageService: coverageService,
+	}
+}
+
+// GetCoverageRules godoc
+// @Summary List coverage rules
+// @Description Lists the coverage rules of every healthcare entitlement, or of one entitlement (admin only). Rules without a medicine are the entitlement's default.
+// @Tags coverage
+// @Produce json
+// @Param entitlement query string false "Only rules of this entitlement"
+// @Success 200 {object} dto.GetCoverageRulesResponseDto "Coverage rules retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving coverage rules"
+// @Router /api/order/v1/coverage/rules [get]
+// @Security ApiKeyAuth
+func (h *CoverageHandler) GetCoverageRules(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.coverageService.GetCoverageRules(ctx, c.Query("entitlement"))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// CreateCoverageRule godoc
+// @Summary Create a coverage rule
+// @Description Sets the covered percentage, copay, exclusion and annual cap an entitlement gives for a medicine, or its default for medicines without a rule when medicine_id is omitted (admin only). Applies to orders approved afterwards.
+// @Tags coverage
+// @Accept json
+// @Produce json
+// @Param request body dto.CoverageRuleRequestDto true "Coverage rule"
+// @Success 201 {object} dto.CoverageRuleDto "Coverage rule created"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 409 {object} response.ErrorResponse "A rule already exists for the entitlement and medicine"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while creating the coverage rule"
+// @Router /api/order/v1/coverage/rules [post]
+// @Security ApiKeyAuth
+func (h *CoverageHandler) CreateCoverageRule(c *fiber.Ctx) error {
+	var body dto.CoverageRuleRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.coverageService.CreateCoverageRule(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// UpdateCoverageRule godoc
+// @Summary Update a coverage rule
+// @Description Changes the terms of a coverage rule (admin only). Orders that are already approved keep their split.
+// @Tags coverage
+// @Accept json
+// @Produce json
+// @Param id path string true "Coverage rule ID (UUID)"
+// @Param request body dto.UpdateCoverageRuleRequestDto true "Coverage terms"
+// @Success 200 {object} dto.CoverageRuleDto "Coverage rule updated"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or rule ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Coverage rule not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while updating the coverage rule"
+// @Router /api/order/v1/coverage/rules/{id} [put]
+// @Security ApiKeyAuth
+func (h *CoverageHandler) UpdateCoverageRule(c *fiber.Ctx) error {
+	ruleID := c.Params("id")
+	if ruleID == "" {
+		return response.BadRequest(c, "Coverage rule ID is required")
+	}
+
+	var body dto.UpdateCoverageRuleRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.coverageService.UpdateCoverageRule(ctx, ruleID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// DeleteCoverageRule godoc
+// @Summary Delete a coverage rule
+// @Description Removes a coverage rule (admin only). Medicines it covered fall back to the entitlement's default rule.
+// @Tags coverage
+// @Produce json
+// @Param id path string true "Coverage rule ID (UUID)"
+// @Success 200 {object} dto.DeleteCoverageRuleResponseDto "Coverage rule deleted"
+// @Failure 400 {object} response.ErrorResponse "Invalid rule ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Coverage rule not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while deleting the coverage rule"
+// @Router /api/order/v1/coverage/rules/{id} [delete]
+// @Security ApiKeyAuth
+func (h *CoverageHandler) DeleteCoverageRule(c *fiber.Ctx) error {
+	ruleID := c.Params("id")
+	if ruleID == "" {
+		return response.BadRequest(c, "Coverage rule ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.coverageService.DeleteCoverageRule(ctx, ruleID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
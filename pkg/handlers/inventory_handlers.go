@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// ReceiveStock godoc
+// @Summary Record a goods receipt
+// @Description Books received stock into a lot of a medicine (admin only). A new lot is created on first delivery; later deliveries of the same lot must carry the same expiry date. The receipt is recorded in the stock ledger.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body dto.ReceiveStockRequestDto true "Goods receipt"
+// @Success 201 {object} dto.ReceiveStockResponseDto "Stock received"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 409 {object} response.ErrorResponse "Lot already exists with a different expiry date"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while receiving stock"
+// @Router /api/medicine/v1/stock/receipts [post]
+// @Security ApiKeyAuth
+func (h *InventoryHandler) ReceiveStock(c *fiber.Ctx) error {
+	var body dto.ReceiveStockRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.inventoryService.ReceiveStock(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// AdjustStock godoc
+// @Summary Record a stock-count adjustment
+// @Description Sets a lot to the quantity found in a physical count (admin only). The difference is recorded in the stock ledger with the given reason.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body dto.StockAdjustmentRequestDto true "Stock count"
+// @Success 200 {object} dto.StockAdjustmentResponseDto "Stock adjusted"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine batch not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while adjusting stock"
+// @Router /api/medicine/v1/stock/adjustments [post]
+// @Security ApiKeyAuth
+func (h *InventoryHandler) AdjustStock(c *fiber.Ctx) error {
+	var body dto.StockAdjustmentRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.inventoryService.AdjustStock(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetStockMovements godoc
+// @Summary Get the stock ledger of a medicine
+// @Description Lists the most recent stock movements of a medicine, newest first (admin only).
+// @Tags inventory
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Param limit query int false "Maximum number of movements (default 100)"
+// @Success 200 {object} dto.GetStockMovementsResponseDto "Stock movements retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid medicine ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving stock movements"
+// @Router /api/medicine/v1/medicines/{id}/movements [get]
+// @Security ApiKeyAuth
+func (h *InventoryHandler) GetStockMovements(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	if medicineID == "" {
+		return response.BadRequest(c, "Medicine ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.inventoryService.GetStockMovements(ctx, medicineID, c.QueryInt("limit", 0))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetStockReconciliation godoc
+// @Summary Reconcile stock against the ledger
+// @Description Reports medicines whose stock or reserved quantity disagrees with the stock ledger or with the remaining batch quantities (admin only). Nothing is changed.
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} dto.StockReconciliationResponseDto "Reconciliation report"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while reconciling stock"
+// @Router /api/medicine/v1/stock/reconciliation [get]
+// @Security ApiKeyAuth
+func (h *InventoryHandler) GetStockReconciliation(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.inventoryService.GetStockReconciliation(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
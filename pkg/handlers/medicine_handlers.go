@@ -3,8 +3,11 @@ package handlers
 import (
 	"order-service/pkg/apperr"
 	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
 	"order-service/pkg/response"
 	service "order-service/pkg/services"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,18 +22,31 @@ func NewMedicineHandler(medicineService *service.MedicineService) *MedicineHandl
 	}
 }
 
-// GetAllMedicines godoc
-// @Summary Get all available medicines
-// @Description Retrieves a list of all available medicines in the system. This endpoint does not require authentication.
+// SearchMedicines godoc
+// @Summary Search the medicine catalog
+// @Description Returns a page of medicines. With q, matches English, generic, brand and Thai names with typo tolerance and ranks the best matches first; otherwise lists medicines by name. This endpoint does not require authentication.
 // @Tags medicines
 // @Accept json
 // @Produce json
+// @Param q query string false "Search term (English or Thai)"
+// @Param unit query string false "Only medicines sold in this unit, e.g. tablet"
+// @Param classification query string false "Only this drug classification" Enums(otc, pharmacy_only, prescription_only, controlled)
+// @Param category query string false "Only medicines in this ATC category or any of its subgroups, e.g. N02"
+// @Param in_stock query bool false "Only medicines with available stock"
+// @Param page query int false "Page number, starting at 1 (default 1)"
+// @Param page_size query int false "Page size, at most 100 (default 20)"
 // @Success 200 {object} dto.GetAllMedicinesResponseDto "Medicines retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid query parameters"
 // @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving medicines"
 // @Router /api/medicine/v1/medicines [get]
-func (h *MedicineHandler) GetAllMedicines(c *fiber.Ctx) error {
+func (h *MedicineHandler) SearchMedicines(c *fiber.Ctx) error {
+	var query dto.SearchMedicinesQueryDto
+	if err := c.QueryParser(&query); err != nil {
+		return response.BadRequest(c, "Invalid query parameters "+err.Error())
+	}
+
 	ctx := contextUtils.GetContext(c)
-	res, err := h.medicineService.GetAllMedicines(ctx)
+	res, err := h.medicineService.SearchMedicines(ctx, query)
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
@@ -40,11 +56,12 @@ func (h *MedicineHandler) GetAllMedicines(c *fiber.Ctx) error {
 
 // GetMedicineByID godoc
 // @Summary Get medicine details by ID
-// @Description Retrieves detailed information about a specific medicine identified by its ID. This endpoint does not require authentication.
+// @Description Retrieves detailed information about a specific medicine identified by its ID. Unit prices are those in effect now, or at the given time. This endpoint does not require authentication.
 // @Tags medicines
 // @Accept json
 // @Produce json
 // @Param id path string true "Medicine ID (UUID)"
+// @Param at query string false "Resolve prices at this time (RFC 3339)"
 // @Success 200 {object} dto.GetMedicineByIDResponseDto "Medicine retrieved successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid or missing medicine ID"
 // @Failure 404 {object} response.ErrorResponse "Medicine not found"
@@ -56,8 +73,254 @@ func (h *MedicineHandler) GetMedicineByID(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Medicine ID is required")
 	}
 
+	var at *time.Time
+	if raw := c.Query("at"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return response.BadRequest(c, "at must be an RFC 3339 timestamp")
+		}
+		at = &parsed
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.GetMedicineByID(ctx, medicineID, at)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetExpiringBatches godoc
+// @Summary List medicine batches that expire soon
+// @Description Lists batches with remaining stock that expire within the given number of days, soonest first. Batches that have already expired are included. Staff only.
+// @Tags medicines
+// @Produce json
+// @Param days query int false "Look-ahead window in days (default 30)"
+// @Success 200 {object} dto.GetExpiringBatchesResponseDto "Expiring batches retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid days parameter"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - staff only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving batches"
+// @Router /api/medicine/v1/batches/expiring [get]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) GetExpiringBatches(c *fiber.Ctx) error {
+	days, err := strconv.Atoi(c.Query("days", "30"))
+	if err != nil {
+		return response.BadRequest(c, "days must be a whole number")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.GetExpiringBatches(ctx, days)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetLowStockMedicines godoc
+// @Summary List medicines at or below their reorder point
+// @Description Lists medicines whose available stock (on hand minus quantities reserved for approved orders) is at or below the reorder point, most urgent first. Staff only; used by the pharmacy dashboard.
+// @Tags medicines
+// @Produce json
+// @Success 200 {object} dto.GetLowStockMedicinesResponseDto "Low-stock medicines retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - staff only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving medicines"
+// @Router /api/medicine/v1/medicines/low-stock [get]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) GetLowStockMedicines(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.GetLowStockMedicines(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// UpdateReorderLevels godoc
+// @Summary Set the reorder point and quantity of a medicine
+// @Description Sets the reorder point and reorder quantity used for low-stock alerts (admin only). Sending null for the reorder point turns alerts off for the medicine.
+// @Tags medicines
+// @Accept json
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Param request body dto.UpdateReorderLevelsRequestDto true "Reorder levels"
+// @Success 200 {object} dto.GetMedicineByIDResponseDto "Reorder levels updated"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or medicine ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while updating medicine"
+// @Router /api/medicine/v1/medicines/{id}/reorder-levels [put]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) UpdateReorderLevels(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	if medicineID == "" {
+		return response.BadRequest(c, "Medicine ID is required")
+	}
+
+	var body dto.UpdateReorderLevelsRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.UpdateReorderLevels(ctx, medicineID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetTherapeuticCategories godoc
+// @Summary List therapeutic categories
+// @Description Lists the ATC therapeutic categories used to group medicines, ordered by code. This endpoint does not require authentication.
+// @Tags medicines
+// @Produce json
+// @Success 200 {object} dto.GetTherapeuticCategoriesResponseDto "Categories retrieved successfully"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving categories"
+// @Router /api/medicine/v1/medicines/categories [get]
+func (h *MedicineHandler) GetTherapeuticCategories(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.GetTherapeuticCategories(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// UpsertMedicineUnit godoc
+// @Summary Add or update a sellable unit of a medicine
+// @Description Adds a package unit (e.g. a strip of 10 tablets) to a medicine, or updates the factor and price of the unit with the same name (admin only). The base unit's factor is always 1 and its price is the medicine's price.
+// @Tags medicines
+// @Accept json
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Param request body dto.UpsertMedicineUnitRequestDto true "Unit"
+// @Success 200 {object} dto.GetMedicineByIDResponseDto "Unit saved"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or medicine ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while saving the unit"
+// @Router /api/medicine/v1/medicines/{id}/units [post]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) UpsertMedicineUnit(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	if medicineID == "" {
+		return response.BadRequest(c, "Medicine ID is required")
+	}
+
+	var body dto.UpsertMedicineUnitRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.UpsertMedicineUnit(ctx, medicineID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetPriceHistory godoc
+// @Summary Get the price history of a medicine
+// @Description Lists every price range of every unit of a medicine, newest first, with past, current and scheduled prices (admin only).
+// @Tags medicines
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Success 200 {object} dto.GetMedicinePriceHistoryResponseDto "Price history retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid medicine ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving prices"
+// @Router /api/medicine/v1/medicines/{id}/prices [get]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) GetPriceHistory(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	if medicineID == "" {
+		return response.BadRequest(c, "Medicine ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.GetPriceHistory(ctx, medicineID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// SchedulePriceChange godoc
+// @Summary Change or schedule the price of a medicine unit
+// @Description Sets a new price for a unit of the medicine (the base unit by default), effective immediately or from a future time. The previous price is kept in the price history (admin only).
+// @Tags medicines
+// @Accept json
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Param request body dto.ScheduleMedicinePriceRequestDto true "Price change"
+// @Success 201 {object} dto.MedicinePriceDto "Price change recorded"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, medicine ID or backdated change"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine or unit not found"
+// @Failure 409 {object} response.ErrorResponse "A price change already starts at that time"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while recording the price"
+// @Router /api/medicine/v1/medicines/{id}/prices [post]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) SchedulePriceChange(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	if medicineID == "" {
+		return response.BadRequest(c, "Medicine ID is required")
+	}
+
+	var body dto.ScheduleMedicinePriceRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.medicineService.SchedulePriceChange(ctx, medicineID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// CancelPriceChange godoc
+// @Summary Cancel a scheduled price change
+// @Description Removes a price change that has not taken effect yet; the previous price stays in effect (admin only).
+// @Tags medicines
+// @Produce json
+// @Param id path string true "Medicine ID (UUID)"
+// @Param priceId path string true "Price change ID (UUID)"
+// @Success 200 {object} dto.CancelMedicinePriceResponseDto "Price change cancelled"
+// @Failure 400 {object} response.ErrorResponse "Invalid medicine or price ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Price change not found"
+// @Failure 409 {object} response.ErrorResponse "The price change has already taken effect"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while cancelling the price change"
+// @Router /api/medicine/v1/medicines/{id}/prices/{priceId} [delete]
+// @Security ApiKeyAuth
+func (h *MedicineHandler) CancelPriceChange(c *fiber.Ctx) error {
+	medicineID := c.Params("id")
+	priceID := c.Params("priceId")
+	if medicineID == "" || priceID == "" {
+		return response.BadRequest(c, "Medicine ID and price ID are required")
+	}
+
 	ctx := contextUtils.GetContext(c)
-	res, err := h.medicineService.GetMedicineByID(ctx, medicineID)
+	res, err := h.medicineService.CancelPriceChange(ctx, medicineID, priceID)
 	if err != nil {
 		return apperr.WriteError(c, err)
 	}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotificationPreferences godoc
+// @Summary Get my notification preferences
+// @Description Returns how the caller is notified about their orders: the language of messages and the email, SMS and LINE channels. Users who have not set preferences get the defaults, with every channel off.
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} dto.NotificationPreferenceDto "Notification preferences retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving notification preferences"
+// @Router /api/order/v1/notifications/preferences [get]
+// @Security ApiKeyAuth
+func (h *NotificationHandler) GetNotificationPreferences(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.notificationService.GetNotificationPreferences(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// SaveNotificationPreferences godoc
+// @Summary Set my notification preferences
+// @Description Sets the language (th or en) of the caller's notifications and which channels they are sent on. Doctors are told about new orders to review; patients about approval, rejection, payment, dispatch and delivery.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body dto.NotificationPreferenceRequestDto true "Language, addresses and channels"
+// @Success 200 {object} dto.NotificationPreferenceDto "Notification preferences saved"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, or a channel turned on without its address"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while saving notification preferences"
+// @Router /api/order/v1/notifications/preferences [put]
+// @Security ApiKeyAuth
+func (h *NotificationHandler) SaveNotificationPreferences(c *fiber.Ctx) error {
+	var body dto.NotificationPreferenceRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.notificationService.SaveNotificationPreferences(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetNotificationLog godoc
+// @Summary Get sent notifications
+// @Description Lists the latest notifications sent to the caller, newest first, including those that failed to send. Admins can view another user's notifications.
+// @Tags notifications
+// @Produce json
+// @Param user_id query string false "User to list notifications of (admin only)"
+// @Param limit query int false "Maximum number of notifications (default 50)"
+// @Success 200 {object} dto.GetNotificationLogResponseDto "Notifications retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only admins can view other users' notifications"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving notifications"
+// @Router /api/order/v1/notifications [get]
+// @Security ApiKeyAuth
+func (h *NotificationHandler) GetNotificationLog(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.notificationService.GetNotificationLog(ctx, c.Query("user_id"), c.QueryInt("limit", 0))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
@@ -27,7 +27,7 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 
 // CreateOrder godoc
 // @Summary Create a new order
-// @Description Creates a new order in the system. Only patients can create orders. The order will be assigned to the authenticated patient's doctor.
+// @Description Creates a new order in the system. Only patients can create orders. The order is assigned to the doctor of a completed appointment: the one given by appointment_id, otherwise the patient's latest completed appointment (optionally restricted to doctor_id). Patients may list the medicines they want in requested_items for the doctor to review. Carts of over-the-counter medicines only are approved straight away, unless they contain a major interaction or one of the patient's allergies, which a doctor then reviews.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -36,6 +36,7 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 // @Failure 400 {object} response.ErrorResponse "Invalid request body or missing required fields"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - only patients can create orders"
+// @Failure 404 {object} response.ErrorResponse "Appointment not found"
 // @Failure 500 {object} response.ErrorResponse "Internal server error while creating order"
 // @Router /api/order/v1/orders [post]
 // @Security ApiKeyAuth
@@ -55,7 +56,7 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 
 // UpdateOrder godoc
 // @Summary Update an existing order
-// @Description Updates an order with new items or modifications. Only doctors can update orders they created. Supports adding, editing, or removing order items.
+// @Description Updates an order with new items or modifications. Only doctors can update orders they created. Supports adding, editing, or removing order items, and accepting, modifying or striking through the patient's requested items. order_items replaces the doctor's own items; requested items accepted or modified in an earlier update stay on the order unless decided again or listed in order_items. Drug interaction and allergy checks run on the resulting items; findings are returned in the response and contraindicated combinations are rejected.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -65,6 +66,7 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor who created this order can update it"
 // @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "Order contains a contraindicated combination of medicines"
 // @Failure 500 {object} response.ErrorResponse "Internal server error while updating order"
 // @Router /api/order/v1/orders [put]
 // @Security ApiKeyAuth
@@ -114,6 +116,189 @@ func (h *OrderHandler) GetOrder(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(res)
 }
 
+// GetOrderLabels godoc
+// @Summary Print dispensing labels for an order
+// @Description Renders one plain-text label per order item with the medicine, quantity and dosage instructions. Available to the patient who owns the order, the assigned doctor and admins once the order is approved.
+// @Tags orders
+// @Produce plain
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {string} string "Printable labels"
+// @Failure 400 {object} response.ErrorResponse "Invalid or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not allowed to print labels for this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "Order is not approved yet"
+// @Router /api/order/v1/orders/{id}/labels [get]
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetOrderLabels(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	labels, err := h.orderService.GetOrderLabels(ctx, orderID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.Status(fiber.StatusOK).SendString(labels)
+}
+
+// GetTaxInvoices godoc
+// @Summary Get the tax invoices of an order
+// @Description Returns the tax invoices and receipts issued when the order was paid, with the seller and buyer details, running number and VAT breakdown they were issued with. Available to the patient who owns the order, the assigned doctor and admins.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} dto.GetTaxInvoicesResponseDto "Tax invoices retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not allowed to read invoices for this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving invoices"
+// @Router /api/order/v1/orders/{id}/invoices [get]
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetTaxInvoices(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.GetTaxInvoices(ctx, orderID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetOrderHistory godoc
+// @Summary Get the status history of an order
+// @Description Lists every status the order has moved through, oldest first, with who made each change and why. Changes made by background jobs such as order expiry have the system role. Available to the patient who owns the order, the assigned doctor and admins.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} dto.GetOrderHistoryResponseDto "Order history retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not allowed to read this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving order history"
+// @Router /api/order/v1/orders/{id}/history [get]
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetOrderHistory(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.GetOrderHistory(ctx, orderID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetOrderComments godoc
+// @Summary Get the comments on an order
+// @Description Lists the comment thread on an order, oldest first: the doctor's change requests and rejection reasons, the patient's replies and any other comments. Available to the patient who owns the order, the assigned doctor and admins.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {object} dto.GetOrderCommentsResponseDto "Order comments retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not allowed to read this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving order comments"
+// @Router /api/order/v1/orders/{id}/comments [get]
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetOrderComments(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.GetOrderComments(ctx, orderID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// AddOrderComment godoc
+// @Summary Comment on an order
+// @Description Adds a comment to the thread on an order. Only the patient who owns the order and the assigned doctor can comment.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID (UUID)"
+// @Param request body dto.AddOrderCommentRequestDto true "Comment"
+// @Success 201 {object} dto.OrderCommentDto "Comment added"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, or missing order ID or comment body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not the patient or the doctor on this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while adding the comment"
+// @Router /api/order/v1/orders/{id}/comments [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) AddOrderComment(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+	var body dto.AddOrderCommentRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.AddOrderComment(ctx, orderID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// GetReceiptPDF godoc
+// @Summary Download the PDF receipt of an order
+// @Description Returns the latest tax invoice issued for the order as a PDF receipt with its items, unit prices, discounts, VAT, payment method and delivery fee. The document is kept from its first download so it never changes afterwards. Available to the patient who owns the order, the assigned doctor and admins.
+// @Tags orders
+// @Produce application/pdf
+// @Param id path string true "Order ID (UUID)"
+// @Success 200 {file} file "PDF receipt"
+// @Failure 400 {object} response.ErrorResponse "Invalid or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - not allowed to read invoices for this order"
+// @Failure 404 {object} response.ErrorResponse "Order not found or not paid yet"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while rendering the receipt"
+// @Router /api/order/v1/orders/{id}/receipt.pdf [get]
+// @Security ApiKeyAuth
+func (h *OrderHandler) GetReceiptPDF(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+	if orderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	receipt, err := h.orderService.GetReceiptPDF(ctx, orderID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, receipt.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", receipt.Filename))
+	return c.Status(fiber.StatusOK).Send(receipt.Content)
+}
+
 // GetAllOrdersHistory godoc
 // @Summary Get all orders for the current patient
 // @Description Retrieves the complete order history for the authenticated patient. The patient is identified from the JWT authentication token.
@@ -188,7 +373,7 @@ func (h *OrderHandler) GetLatestOrderByPatientID(c *fiber.Ctx) error {
 
 // CancelOrder godoc
 // @Summary Cancel an existing order
-// @Description Cancels an order (doctor only). Only the doctor who created the order can cancel it. The order status will be changed to cancelled.
+// @Description Cancels an order and records who cancelled it and why. Doctors can cancel their own orders until they are paid. Patients can cancel their own pending, approved, paid or processing orders within the cancellation window set for the status, and must give a reason. Paid orders can only be cancelled before they are dispatched; the patient's payment is then refunded automatically less the cancellation fee.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -196,9 +381,10 @@ func (h *OrderHandler) GetLatestOrderByPatientID(c *fiber.Ctx) error {
 // @Success 200 {object} dto.CancelOrderResponseDto "Order cancelled successfully"
 // @Failure 400 {object} response.ErrorResponse "Invalid request body or missing order ID"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
-// @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor who created this order can cancel it"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor or patient of this order can cancel it"
 // @Failure 404 {object} response.ErrorResponse "Order not found"
-// @Failure 500 {object} response.ErrorResponse "Internal server error while cancelling order"
+// @Failure 409 {object} response.ErrorResponse "Order cannot be cancelled in its status, was dispatched or the cancellation window has passed"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while cancelling order or refunding the payment"
 // @Router /api/order/v1/orders [delete]
 // @Security ApiKeyAuth
 func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
@@ -222,16 +408,17 @@ func (h *OrderHandler) CancelOrder(c *fiber.Ctx) error {
 
 // ApproveOrder godoc
 // @Summary Approve an existing order
-// @Description Approves an order and sets its status to approved (doctor only). Only the doctor who created the order can approve it.
+// @Description Approves an order and sets its status to approved (doctor only). Only the doctor who created the order can approve it. Drug interaction and allergy checks run first: contraindicated combinations cannot be approved, and major interactions or allergies require an override_reason, which is stored on the order.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param request body dto.ApproveOrderRequestDto true "Approve order request data"
 // @Success 200 {object} dto.ApproveOrderResponseDto "Order approved successfully"
-// @Failure 400 {object} response.ErrorResponse "Invalid request body or missing order ID"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, missing order ID or missing override reason"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor who created this order can approve it"
 // @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "Order contains a contraindicated combination of medicines"
 // @Failure 500 {object} response.ErrorResponse "Internal server error while approving order"
 // @Router /api/order/v1/orders/confirm [post]
 // @Security ApiKeyAuth
@@ -254,15 +441,50 @@ func (h *OrderHandler) ApproveOrder(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(res)
 }
 
+// ApproveControlledOrder godoc
+// @Summary Co-sign an order containing controlled medicines
+// @Description Records the secondary approval required before an approved order containing controlled medicines can be paid (admin only).
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.ApproveControlledOrderRequestDto true "Co-sign order request data"
+// @Success 200 {object} dto.ApproveControlledOrderResponseDto "Order co-signed successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, missing order ID or order has no controlled medicines"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only admins can co-sign orders"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "Order is not approved or already co-signed"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while co-signing order"
+// @Router /api/order/v1/orders/confirm/controlled [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) ApproveControlledOrder(c *fiber.Ctx) error {
+	var body dto.ApproveControlledOrderRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	if body.OrderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.ApproveControlledOrder(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
 // RejectOrder godoc
 // @Summary Reject an existing order
-// @Description Rejects an order and sets its status to rejected (doctor only). Only the doctor who created the order can reject it.
+// @Description Rejects an order and sets its status to rejected (doctor only). Only the doctor who created the order can reject it. The doctor picks a reason code and may explain it in their own words, which is required for the other code; the explanation is added to the order's comments. To let the patient fix the order instead, request changes.
 // @Tags orders
 // @Accept json
 // @Produce json
 // @Param request body dto.RejectOrderRequestDto true "Reject order request data"
 // @Success 200 {object} dto.RejectOrderResponseDto "Order rejected successfully"
-// @Failure 400 {object} response.ErrorResponse "Invalid request body or missing order ID"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, missing order ID, or invalid or unexplained reason code"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor who created this order can reject it"
 // @Failure 404 {object} response.ErrorResponse "Order not found"
@@ -278,6 +500,9 @@ func (h *OrderHandler) RejectOrder(c *fiber.Ctx) error {
 	if body.OrderID == "" {
 		return response.BadRequest(c, "Order ID is required")
 	}
+	if body.ReasonCode == "" {
+		return response.BadRequest(c, "Reason code is required")
+	}
 
 	ctx := contextUtils.GetContext(c)
 	res, err := h.orderService.RejectOrder(ctx, body)
@@ -288,9 +513,141 @@ func (h *OrderHandler) RejectOrder(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(res)
 }
 
+// RequestOrderChanges godoc
+// @Summary Send an order back to the patient
+// @Description Sends a pending order back to the patient with a comment saying what to change, instead of rejecting it (doctor only). Only the doctor who owns the order can request changes. The order waits in changes_requested until the patient resubmits or cancels it, and expires if the patient does neither in time.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.RequestOrderChangesRequestDto true "Order and the doctor's comment"
+// @Success 200 {object} dto.OrderReviewResponseDto "Changes requested"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, or missing order ID or comment"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only the doctor who owns this order can request changes"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "The order is not pending"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while requesting changes"
+// @Router /api/order/v1/orders/request-changes [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) RequestOrderChanges(c *fiber.Ctx) error {
+	var body dto.RequestOrderChangesRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	if body.OrderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.RequestOrderChanges(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// ResubmitOrder godoc
+// @Summary Resubmit an order for review
+// @Description Sends an order the doctor asked changes to back to pending for the doctor to review again (patient only). The patient may answer the doctor with a comment, replace the order's note and revise the medicines they asked for; a revised request replaces the old one, and the order items the doctor made from it are dropped for the doctor to decide again.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.ResubmitOrderRequestDto true "Order, reply and note"
+// @Success 200 {object} dto.OrderReviewResponseDto "Order resubmitted"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or missing order ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only the patient can resubmit their own orders"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "The doctor has not asked for changes to the order"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while resubmitting order"
+// @Router /api/order/v1/orders/resubmit [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) ResubmitOrder(c *fiber.Ctx) error {
+	var body dto.ResubmitOrderRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	if body.OrderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.ResubmitOrder(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// ApplyCoupon godoc
+// @Summary Apply a coupon to an order
+// @Description Applies a coupon code to an approved order before it is paid, replacing any coupon applied before. Only the patient who created the order can apply coupons. The discounts and the insurer and patient portions are worked out again and returned.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.ApplyCouponRequestDto true "Order and coupon code"
+// @Success 200 {object} dto.OrderTotalsResponseDto "Coupon applied"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, or the coupon does not apply to the order"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only the patient can apply coupons to their own orders"
+// @Failure 404 {object} response.ErrorResponse "Order or coupon not found"
+// @Failure 409 {object} response.ErrorResponse "The order is not awaiting payment, or the coupon has reached its usage limit"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while applying the coupon"
+// @Router /api/order/v1/orders/coupon [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) ApplyCoupon(c *fiber.Ctx) error {
+	var body dto.ApplyCouponRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.ApplyCoupon(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// RemoveCoupon godoc
+// @Summary Remove the coupon from an order
+// @Description Takes the coupon off an approved order that has not been paid yet. Automatic promotions still apply.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.RemoveCouponRequestDto true "Order"
+// @Success 200 {object} dto.OrderTotalsResponseDto "Coupon removed"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, or the order has no coupon"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only the patient can change coupons on their own orders"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "The order is not awaiting payment"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while removing the coupon"
+// @Router /api/order/v1/orders/coupon [delete]
+// @Security ApiKeyAuth
+func (h *OrderHandler) RemoveCoupon(c *fiber.Ctx) error {
+	var body dto.RemoveCouponRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.RemoveCoupon(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
 // PayOrder godoc
 // @Summary Mark an order as paid
-// @Description Marks an order as paid and updates its payment status. Only the patient who created the order can pay it.
+// @Description Marks an order as paid and updates its payment status. Only the patient who created the order can pay it. The order must be approved, and orders with controlled medicines must also be co-signed by an admin. A running-numbered tax invoice is issued for the payment: a full tax invoice when tax_invoice buyer details are given, an abbreviated receipt otherwise.
 // @Tags orders
 // @Accept json
 // @Produce json
@@ -322,6 +679,76 @@ func (h OrderHandler) PayOrder(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(res)
 }
 
+// RefundOrder godoc
+// @Summary Refund a paid order
+// @Description Gives back part or all of a paid order. Only staff and admins can refund orders, and a reason is required. List the items and quantities that come back in items, or leave items empty to refund everything not refunded yet. Returned items are put back into the batches they were dispensed from, the patient's share is paid back through the payment provider and the insurer's share is taken off its claim. The order becomes partially_refunded, or refunded once every item has come back, when the rest of the payment including the delivery fee is paid back too. The refund is recorded before the payment provider is asked to pay it out; if the provider fails, the refund stays pending and the payout is retried in the background.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.RefundOrderRequestDto true "Refund order request data"
+// @Success 200 {object} dto.RefundOrderResponseDto "Order refunded successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, missing reason or unknown order item"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only staff and admins can refund orders"
+// @Failure 404 {object} response.ErrorResponse "Order not found"
+// @Failure 409 {object} response.ErrorResponse "Order is not paid or the quantity exceeds what is left to refund"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while refunding"
+// @Router /api/order/v1/orders/refund [post]
+// @Security ApiKeyAuth
+func (h OrderHandler) RefundOrder(c *fiber.Ctx) error {
+	var body dto.RefundOrderRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	if body.OrderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.RefundOrder(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// UpdateOrderDelivery godoc
+// @Summary Update the fulfilment of a paid order
+// @Description Moves a paid order to processing while it is packed, shipped once it leaves the pharmacy and delivered once the patient has it. Only staff and admins can update deliveries. Shipping records the delivery to delivery_information_id, or to the patient's latest delivery address, with the carrier's tracking number if given. The patient is told when the order is dispatched and when it is delivered.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body dto.UpdateOrderDeliveryRequestDto true "Order and its new fulfilment status"
+// @Success 200 {object} dto.UpdateOrderDeliveryResponseDto "Order delivery updated successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, status or delivery information"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only staff and admins can update deliveries"
+// @Failure 404 {object} response.ErrorResponse "Order or delivery information not found"
+// @Failure 409 {object} response.ErrorResponse "Order cannot move to the status, or the patient has no delivery address"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while updating the delivery"
+// @Router /api/order/v1/orders/delivery [post]
+// @Security ApiKeyAuth
+func (h OrderHandler) UpdateOrderDelivery(c *fiber.Ctx) error {
+	var body dto.UpdateOrderDeliveryRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	if body.OrderID == "" {
+		return response.BadRequest(c, "Order ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.UpdateOrderDelivery(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
 // GetAllOrdersForDoctor godoc
 // @Summary Get all orders for the current doctor
 // @Description Retrieves all orders created by the authenticated doctor. Includes patient information for each order. The doctor is identified from the JWT authentication token.
@@ -345,12 +772,12 @@ func (h *OrderHandler) GetAllOrdersForDoctor(c *fiber.Ctx) error {
 }
 
 // GetAllOrdersHistoryForDoctor godoc
-// @Summary Get approved or rejected orders for the current doctor
-// @Description Retrieves approved or rejected orders created by the authenticated doctor. Includes patient information for each order. Can filter by status using the optional query parameter. Valid status values are "approved" or "rejected".
+// @Summary Get reviewed orders for the current doctor
+// @Description Retrieves approved or rejected orders, and orders sent back to the patient for changes, created by the authenticated doctor. Includes patient information for each order. Can filter by status using the optional query parameter. Valid status values are "approved", "rejected" or "changes_requested".
 // @Tags orders
 // @Accept json
 // @Produce json
-// @Param status query string false "Filter by status: 'approved' or 'rejected'. If omitted, returns all approved and rejected orders."
+// @Param status query string false "Filter by status: 'approved', 'rejected' or 'changes_requested'. If omitted, returns all of them."
 // @Success 200 {object} dto.GetAllOrdersForDoctorListDto "Orders retrieved successfully"
 // @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
 // @Failure 403 {object} response.ErrorResponse "Forbidden - only doctors can access this endpoint"
@@ -368,3 +795,24 @@ func (h *OrderHandler) GetAllOrdersHistoryForDoctor(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(res)
 }
+
+// ReloadClinicalTable godoc
+// @Summary Reload the drug interaction table
+// @Description Re-reads the drug interaction and allergen table from the configured file (admin only). Falls back to the built-in table when no file is configured.
+// @Tags orders
+// @Produce json
+// @Success 200 {object} dto.ReloadClinicalTableResponseDto "Interaction table reloaded"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - only admins can reload the table"
+// @Failure 500 {object} response.ErrorResponse "Interaction table could not be read or parsed"
+// @Router /api/order/v1/clinical/interactions/reload [post]
+// @Security ApiKeyAuth
+func (h *OrderHandler) ReloadClinicalTable(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.orderService.ReloadClinicalTable(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/events"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// sseRetryMillis tells EventSource clients how long to wait before reconnecting.
+const sseRetryMillis = 3000
+
+type OrderStreamHandler struct {
+	orderStreamService *service.OrderStreamService
+}
+
+func NewOrderStreamHandler(orderStreamService *service.OrderStreamService) *OrderStreamHandler {
+	return &OrderStreamHandler{
+		orderStreamService: orderStreamService,
+	}
+}
+
+// StreamOrders godoc
+// @Summary Stream order status changes
+// @Description Server-Sent Events stream of status changes to the caller's orders: orders they placed as a patient and orders assigned to them as a doctor. Each event's id is the event ID, its event field the event type (e.g. order.approved) and its data the event JSON. A comment line is sent as a heartbeat while there is nothing to report. Clients that reconnect with Last-Event-ID (sent by EventSource automatically, or as the last_event_id query parameter) first receive the events they missed.
+// @Tags orders
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "ID of the last event received, for clients that cannot set headers"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} response.ErrorResponse "Invalid Last-Event-ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving missed events"
+// @Router /api/order/v1/orders/stream [get]
+// @Security ApiKeyAuth
+func (h *OrderStreamHandler) StreamOrders(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	stream, err := h.orderStreamService.OpenOrderStream(ctx, c.Get("Last-Event-ID", c.Query("last_event_id")))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	heartbeat := h.orderStreamService.HeartbeatInterval()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer stream.Close()
+		fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis)
+		for _, event := range stream.Missed {
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case event, ok := <-stream.Live:
+				if !ok {
+					// dropped for falling behind; the client resumes from its last event
+					return
+				}
+				if stream.Skip(event) {
+					continue
+				}
+				if err := writeSSE(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			// a failed flush means the client went away
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeSSE(w *bufio.Writer, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// UpgradeOrderStream only lets WebSocket handshakes through to StreamOrdersWebSocket.
+func (h *OrderStreamHandler) UpgradeOrderStream(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	// the connection outlives the request, so keep the caller for it
+	c.Locals("streamContext", contextUtils.GetContext(c))
+	return c.Next()
+}
+
+// StreamOrdersWebSocket godoc
+// @Summary Stream order status changes over WebSocket
+// @Description WebSocket equivalent of the order event stream. Each event is sent as a JSON text message; the server pings idle connections. Pass last_event_id to first receive the events missed since that one.
+// @Tags orders
+// @Param last_event_id query string false "ID of the last event received"
+// @Success 101 {string} string "Switching protocols"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 426 {object} response.ErrorResponse "Not a WebSocket handshake"
+// @Router /api/order/v1/orders/stream/ws [get]
+// @Security ApiKeyAuth
+func (h *OrderStreamHandler) StreamOrdersWebSocket() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		ctx, ok := conn.Locals("streamContext").(context.Context)
+		if !ok {
+			conn.Close()
+			return
+		}
+		defer conn.Close()
+
+		stream, err := h.orderStreamService.OpenOrderStream(ctx, conn.Query("last_event_id"))
+		if err != nil {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error()))
+			return
+		}
+		defer stream.Close()
+
+		heartbeat := h.orderStreamService.HeartbeatInterval()
+		// clients only send control frames; reading handles pongs and notices a close
+		closed := make(chan struct{})
+		conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		})
+		go func() {
+			defer close(closed)
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		for _, event := range stream.Missed {
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		}
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closed:
+				return
+			case event, ok := <-stream.Live:
+				if !ok {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "stream fell behind; reconnect with last_event_id"))
+					return
+				}
+				if stream.Skip(event) {
+					continue
+				}
+				if err := conn.WriteJSON(event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+					return
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// GetPromotions godoc
+// @Summary List promotions
+// @Description Lists every promotion and coupon, newest first (admin only).
+// @Tags promotions
+// @Produce json
+// @Success 200 {object} dto.GetPromotionsResponseDto "Promotions retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving promotions"
+// @Router /api/order/v1/promotions [get]
+// @Security ApiKeyAuth
+func (h *PromotionHandler) GetPromotions(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.promotionService.GetPromotions(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// CreatePromotion godoc
+// @Summary Create a promotion
+// @Description Creates a percentage or fixed discount on whole orders or on one medicine (admin only). Promotions without a coupon code apply automatically when an order is approved; coupons apply once the patient enters the code. Stackable promotions are combined, and a promotion that is not stackable is only used when it saves more than the others together.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param request body dto.PromotionRequestDto true "Promotion"
+// @Success 201 {object} dto.PromotionDto "Promotion created"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Medicine not found"
+// @Failure 409 {object} response.ErrorResponse "Coupon code already in use"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while creating the promotion"
+// @Router /api/order/v1/promotions [post]
+// @Security ApiKeyAuth
+func (h *PromotionHandler) CreatePromotion(c *fiber.Ctx) error {
+	var body dto.PromotionRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.promotionService.CreatePromotion(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// UpdatePromotion godoc
+// @Summary Update a promotion
+// @Description Replaces the terms of a promotion (admin only). Discounts already given on orders are not changed.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promotion ID (UUID)"
+// @Param request body dto.PromotionRequestDto true "Promotion"
+// @Success 200 {object} dto.PromotionDto "Promotion updated"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body or promotion ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Promotion or medicine not found"
+// @Failure 409 {object} response.ErrorResponse "Coupon code already in use"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while updating the promotion"
+// @Router /api/order/v1/promotions/{id} [put]
+// @Security ApiKeyAuth
+func (h *PromotionHandler) UpdatePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	if promotionID == "" {
+		return response.BadRequest(c, "Promotion ID is required")
+	}
+
+	var body dto.PromotionRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.promotionService.UpdatePromotion(ctx, promotionID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// DeletePromotion godoc
+// @Summary Delete a promotion
+// @Description Removes a promotion (admin only). Discounts it gave on orders keep its name and coupon code.
+// @Tags promotions
+// @Produce json
+// @Param id path string true "Promotion ID (UUID)"
+// @Success 200 {object} dto.DeletePromotionResponseDto "Promotion deleted"
+// @Failure 400 {object} response.ErrorResponse "Invalid promotion ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Promotion not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while deleting the promotion"
+// @Router /api/order/v1/promotions/{id} [delete]
+// @Security ApiKeyAuth
+func (h *PromotionHandler) DeletePromotion(c *fiber.Ctx) error {
+	promotionID := c.Params("id")
+	if promotionID == "" {
+		return response.BadRequest(c, "Promotion ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.promotionService.DeletePromotion(ctx, promotionID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/response"
+	service "order-service/pkg/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GetWebhookSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description Lists the partner endpoints order events are pushed to, with their event filters and delivery health (admin only). Secrets are not returned.
+// @Tags webhooks
+// @Produce json
+// @Success 200 {object} dto.GetWebhookSubscriptionsResponseDto "Webhook subscriptions retrieved successfully"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving webhook subscriptions"
+// @Router /api/order/v1/webhooks [get]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) GetWebhookSubscriptions(c *fiber.Ctx) error {
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.GetWebhookSubscriptions(ctx)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// CreateWebhookSubscription godoc
+// @Summary Create a webhook subscription
+// @Description Subscribes a partner endpoint to order events (admin only). Deliveries are POSTed as JSON and signed with HMAC-SHA256 over "<timestamp>.<body>": the X-Webhook-Timestamp header carries the Unix timestamp and X-Webhook-Signature carries "v1=<hex digest>". The secret is generated when omitted and is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body dto.WebhookSubscriptionRequestDto true "Endpoint, event filter and optional secret"
+// @Success 201 {object} dto.WebhookSubscriptionDto "Webhook subscription created"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, URL or event filter"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while creating the webhook subscription"
+// @Router /api/order/v1/webhooks [post]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) CreateWebhookSubscription(c *fiber.Ctx) error {
+	var body dto.WebhookSubscriptionRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.CreateWebhookSubscription(ctx, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// UpdateWebhookSubscription godoc
+// @Summary Update a webhook subscription
+// @Description Changes a subscription's endpoint, event filter or secret, or disables it (admin only). Setting active re-enables a subscription that was disabled after repeated failures; its pending deliveries are then sent again.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Param request body dto.UpdateWebhookSubscriptionRequestDto true "New settings"
+// @Success 200 {object} dto.WebhookSubscriptionDto "Webhook subscription updated"
+// @Failure 400 {object} response.ErrorResponse "Invalid request body, ID, URL or event filter"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Webhook subscription not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while updating the webhook subscription"
+// @Router /api/order/v1/webhooks/{id} [put]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) UpdateWebhookSubscription(c *fiber.Ctx) error {
+	subscriptionID := c.Params("id")
+	if subscriptionID == "" {
+		return response.BadRequest(c, "Webhook subscription ID is required")
+	}
+
+	var body dto.UpdateWebhookSubscriptionRequestDto
+	if err := c.BodyParser(&body); err != nil {
+		return response.BadRequest(c, "Invalid request body "+err.Error())
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.UpdateWebhookSubscription(ctx, subscriptionID, body)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// DeleteWebhookSubscription godoc
+// @Summary Delete a webhook subscription
+// @Description Removes a subscription and its delivery log (admin only).
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Success 200 {object} dto.DeleteWebhookSubscriptionResponseDto "Webhook subscription deleted"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook subscription ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Webhook subscription not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while deleting the webhook subscription"
+// @Router /api/order/v1/webhooks/{id} [delete]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) DeleteWebhookSubscription(c *fiber.Ctx) error {
+	subscriptionID := c.Params("id")
+	if subscriptionID == "" {
+		return response.BadRequest(c, "Webhook subscription ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.DeleteWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get the delivery log of a webhook subscription
+// @Description Lists a subscription's latest deliveries, newest first, with the attempts made and the last response or error (admin only).
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook subscription ID"
+// @Param status query string false "Only deliveries in this status" Enums(pending, delivered, failed)
+// @Param limit query int false "Maximum number of deliveries (default 100)"
+// @Success 200 {object} dto.GetWebhookDeliveriesResponseDto "Webhook deliveries retrieved successfully"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook subscription ID or status"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Webhook subscription not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while retrieving webhook deliveries"
+// @Router /api/order/v1/webhooks/{id}/deliveries [get]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) GetWebhookDeliveries(c *fiber.Ctx) error {
+	subscriptionID := c.Params("id")
+	if subscriptionID == "" {
+		return response.BadRequest(c, "Webhook subscription ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.GetWebhookDeliveries(ctx, subscriptionID, c.Query("status"), c.QueryInt("limit", 0))
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary Replay a webhook delivery
+// @Description Sends a delivery again with the same X-Webhook-Id and body, whether it was delivered or failed (admin only). It gets a fresh set of retries and is sent on the next delivery run.
+// @Tags webhooks
+// @Produce json
+// @Param deliveryId path string true "Webhook delivery ID"
+// @Success 200 {object} dto.WebhookDeliveryDto "Webhook delivery queued"
+// @Failure 400 {object} response.ErrorResponse "Invalid webhook delivery ID"
+// @Failure 401 {object} response.ErrorResponse "Unauthorized - authentication token missing or invalid"
+// @Failure 403 {object} response.ErrorResponse "Forbidden - admin only"
+// @Failure 404 {object} response.ErrorResponse "Webhook delivery not found"
+// @Failure 409 {object} response.ErrorResponse "The webhook subscription is disabled"
+// @Failure 500 {object} response.ErrorResponse "Internal server error while replaying the webhook delivery"
+// @Router /api/order/v1/webhooks/deliveries/{deliveryId}/replay [post]
+// @Security ApiKeyAuth
+func (h *WebhookHandler) ReplayWebhookDelivery(c *fiber.Ctx) error {
+	deliveryID := c.Params("deliveryId")
+	if deliveryID == "" {
+		return response.BadRequest(c, "Webhook delivery ID is required")
+	}
+
+	ctx := contextUtils.GetContext(c)
+	res, err := h.webhookService.ReplayWebhookDelivery(ctx, deliveryID)
+	if err != nil {
+		return apperr.WriteError(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
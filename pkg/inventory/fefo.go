@@ -0,0 +1,61 @@
+package inventory
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+var ErrInsufficientStock = errors.New("insufficient unexpired stock")
+
+// Allocation is the quantity taken from a single batch.
+type Allocation struct {
+	BatchID  uuid.UUID
+	Quantity float64
+}
+
+// AllocateFEFO takes quantity from the batches, first expiry first out. Expired and
+// empty batches are skipped, undated batches are used last, and ties are broken by
+// the oldest receipt. Nothing is allocated unless the whole quantity can be covered.
+func AllocateFEFO(batches []models.MedicineBatch, quantity float64, now time.Time) ([]Allocation, error) {
+	candidates := make([]models.MedicineBatch, 0, len(batches))
+	for _, batch := range batches {
+		if batch.Quantity > 0 && !batch.IsExpired(now) {
+			candidates = append(candidates, batch)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.ExpiryDate == nil && b.ExpiryDate != nil:
+			return false
+		case a.ExpiryDate != nil && b.ExpiryDate == nil:
+			return true
+		case a.ExpiryDate != nil && !a.ExpiryDate.Equal(*b.ExpiryDate):
+			return a.ExpiryDate.Before(*b.ExpiryDate)
+		}
+		return a.ReceivedAt.Before(b.ReceivedAt)
+	})
+
+	allocations := []Allocation{}
+	remaining := quantity
+	for _, batch := range candidates {
+		if remaining <= 0 {
+			break
+		}
+		take := batch.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, Allocation{BatchID: batch.ID, Quantity: take})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, ErrInsufficientStock
+	}
+	return allocations, nil
+}
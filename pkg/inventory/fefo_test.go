@@ -0,0 +1,123 @@
+package inventory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+func TestAllocateFEFO(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 0, 0, 0, time.UTC)
+	day := func(offset int) *time.Time {
+		d := time.Date(2026, 10, 18+offset, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	received := func(offset int) time.Time { return now.AddDate(0, 0, offset) }
+
+	ids := map[string]uuid.UUID{}
+	batch := func(name string, quantity float64, expiry *time.Time, receivedAt time.Time) models.MedicineBatch {
+		ids[name] = uuid.New()
+		return models.MedicineBatch{ID: ids[name], LotNumber: name, Quantity: quantity, ExpiryDate: expiry, ReceivedAt: receivedAt}
+	}
+
+	type want struct {
+		lot      string
+		quantity float64
+	}
+	tests := []struct {
+		name     string
+		batches  []models.MedicineBatch
+		quantity float64
+		want     []want
+		wantErr  error
+	}{
+		{
+			name: "nearest expiry first",
+			batches: []models.MedicineBatch{
+				batch("later", 10, day(60), received(-30)),
+				batch("sooner", 10, day(10), received(-1)),
+			},
+			quantity: 4,
+			want:     []want{{"sooner", 4}},
+		},
+		{
+			name: "expired and empty batches are skipped",
+			batches: []models.MedicineBatch{
+				batch("expired", 10, day(-1), received(-90)),
+				batch("empty", 0, day(1), received(-60)),
+				batch("good", 10, day(30), received(-10)),
+			},
+			quantity: 5,
+			want:     []want{{"good", 5}},
+		},
+		{
+			name: "a batch expiring today is still used",
+			batches: []models.MedicineBatch{
+				batch("later", 10, day(30), received(-10)),
+				batch("today", 3, day(0), received(-10)),
+			},
+			quantity: 3,
+			want:     []want{{"today", 3}},
+		},
+		{
+			name: "splits across batches",
+			batches: []models.MedicineBatch{
+				batch("third", 10, day(90), received(-10)),
+				batch("first", 2, day(5), received(-10)),
+				batch("second", 3, day(20), received(-10)),
+			},
+			quantity: 7,
+			want:     []want{{"first", 2}, {"second", 3}, {"third", 2}},
+		},
+		{
+			name: "undated batches last, ties by oldest receipt",
+			batches: []models.MedicineBatch{
+				batch("undated", 10, nil, received(-100)),
+				batch("newer", 2, day(30), received(-1)),
+				batch("older", 2, day(30), received(-20)),
+			},
+			quantity: 6,
+			want:     []want{{"older", 2}, {"newer", 2}, {"undated", 2}},
+		},
+		{
+			name: "insufficient stock",
+			batches: []models.MedicineBatch{
+				batch("a", 2, day(5), received(-10)),
+				batch("expired", 10, day(-5), received(-10)),
+			},
+			quantity: 3,
+			wantErr:  ErrInsufficientStock,
+		},
+		{
+			name:     "no batches",
+			quantity: 1,
+			wantErr:  ErrInsufficientStock,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AllocateFEFO(tt.batches, tt.quantity, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if got != nil {
+					t.Errorf("allocations returned with the error: %+v", got)
+				}
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d allocations, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				if got[i].BatchID != ids[w.lot] || got[i].Quantity != w.quantity {
+					t.Errorf("allocation %d = %s x %v, want %s x %v", i, got[i].BatchID, got[i].Quantity, w.lot, w.quantity)
+				}
+			}
+		})
+	}
+}
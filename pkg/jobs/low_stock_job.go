@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"order-service/pkg/alerts"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+
+	"github.com/google/uuid"
+)
+
+// LowStockJob looks for medicines at or below their reorder point and raises an alert
+// once per medicine. A medicine is alerted again only after its stock has recovered above
+// the reorder point and dropped again. Alerted medicines are remembered in memory, so a
+// replica that takes over the scheduler alerts the current ones once more.
+type LowStockJob struct {
+	medicineRepository *repository.MedicineRepository
+	notifier           alerts.Notifier
+	alerted            map[uuid.UUID]bool
+}
+
+func NewLowStockJob(medicineRepo *repository.MedicineRepository, notifier alerts.Notifier) *LowStockJob {
+	return &LowStockJob{
+		medicineRepository: medicineRepo,
+		notifier:           notifier,
+		alerted:            make(map[uuid.UUID]bool),
+	}
+}
+
+func (j *LowStockJob) RunOnce(ctx context.Context) error {
+	medicines, err := j.medicineRepository.FindLowStock(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	low := make(map[uuid.UUID]bool, len(medicines))
+	for i := range medicines {
+		medicine := &medicines[i]
+		low[medicine.ID] = true
+		if j.alerted[medicine.ID] {
+			continue
+		}
+		if err := j.notifier.NotifyLowStock(ctx, newLowStockAlert(medicine, now)); err != nil {
+			// retried on the next run
+			log.Printf("failed to send low-stock alert for %s: %v", medicine.Name, err)
+			continue
+		}
+		j.alerted[medicine.ID] = true
+	}
+	for id := range j.alerted {
+		if !low[id] {
+			delete(j.alerted, id)
+		}
+	}
+	return nil
+}
+
+func newLowStockAlert(medicine *models.Medicine, now time.Time) alerts.LowStockAlert {
+	return alerts.LowStockAlert{
+		MedicineID:      medicine.ID.String(),
+		MedicineName:    medicine.Name,
+		Unit:            medicine.Unit,
+		Stock:           medicine.Stock,
+		Reserved:        medicine.Reserved,
+		Available:       medicine.Available(),
+		ReorderPoint:    *medicine.ReorderPoint,
+		ReorderQuantity: medicine.ReorderQuantity,
+		DetectedAt:      now,
+	}
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	contextUtils "order-service/pkg/context"
+	service "order-service/pkg/services"
+)
+
+// OrderExpiryJob expires pending orders no doctor reviewed within pendingTTL, approved
+// orders not paid within approvedTTL and orders sent back to the patient that were not
+// resubmitted within changesRequestedTTL. Patients hear of it from the order.expired
+// event each expiry writes to the outbox.
+type OrderExpiryJob struct {
+	orderService        *service.OrderService
+	pendingTTL          time.Duration
+	approvedTTL         time.Duration
+	changesRequestedTTL time.Duration
+}
+
+func NewOrderExpiryJob(orderService *service.OrderService, pendingTTL, approvedTTL, changesRequestedTTL time.Duration) *OrderExpiryJob {
+	return &OrderExpiryJob{
+		orderService:        orderService,
+		pendingTTL:          pendingTTL,
+		approvedTTL:         approvedTTL,
+		changesRequestedTTL: changesRequestedTTL,
+	}
+}
+
+func (j *OrderExpiryJob) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	expired, err := j.orderService.ExpireStaleOrders(contextUtils.WithSystem(ctx), now.Add(-j.pendingTTL), now.Add(-j.approvedTTL), now.Add(-j.changesRequestedTTL))
+	if len(expired) > 0 {
+		log.Printf("expired %d stale orders", len(expired))
+	}
+	return err
+}
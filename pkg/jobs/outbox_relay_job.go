@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"order-service/pkg/events"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = time.Hour
+	// outboxLease is how long claimed events are held back from other relays while they
+	// are being published
+	outboxLease = 5 * time.Minute
+)
+
+// OutboxRelayJob publishes pending outbox events. Events are claimed in a short
+// transaction and published outside it, so slow publishers hold no locks or connections.
+// An event is marked published only after the publisher accepts it, so an event may be
+// published again if the relay stops in between, once its lease runs out; consumers drop
+// duplicates by event ID. Failed events are retried with exponential backoff and moved
+// to dead after maxAttempts.
+type OutboxRelayJob struct {
+	db          *gorm.DB
+	publisher   events.Publisher
+	batchSize   int
+	maxAttempts int
+}
+
+func NewOutboxRelayJob(db *gorm.DB, publisher events.Publisher, batchSize, maxAttempts int) *OutboxRelayJob {
+	return &OutboxRelayJob{
+		db:          db,
+		publisher:   publisher,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// RunOnce publishes batches of due events until none are left.
+func (j *OutboxRelayJob) RunOnce(ctx context.Context) error {
+	for {
+		n, err := j.relayBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if n < j.batchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// relayBatch claims one batch of due events, publishes it and records the outcome of
+// each event. It returns how many events it claimed.
+func (j *OutboxRelayJob) relayBatch(ctx context.Context) (int, error) {
+	due, err := j.claimBatch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	outboxRepo := repository.NewOutboxEventRepository(j.db)
+	// once an event fails, later events of its aggregate wait for it; their lease is
+	// given back so they are retried with it
+	blocked := make(map[uuid.UUID]bool)
+	var held []uuid.UUID
+	for i := range due {
+		event := &due[i]
+		if blocked[event.AggregateID] {
+			held = append(held, event.ID)
+			continue
+		}
+		if err := j.publisher.Publish(ctx, event.ToEvent()); err != nil {
+			blocked[event.AggregateID] = true
+			if err := j.fail(ctx, outboxRepo, event, err); err != nil {
+				return len(due), err
+			}
+			continue
+		}
+		if err := outboxRepo.MarkPublished(ctx, event.ID, time.Now()); err != nil {
+			return len(due), err
+		}
+	}
+	if len(held) > 0 {
+		if err := outboxRepo.Lease(ctx, held, time.Now()); err != nil {
+			return len(due), err
+		}
+	}
+	return len(due), nil
+}
+
+// claimBatch locks up to batchSize due events just long enough to lease them.
+func (j *OutboxRelayJob) claimBatch(ctx context.Context) ([]models.OutboxEvent, error) {
+	var due []models.OutboxEvent
+	err := j.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		outboxRepo := repository.NewOutboxEventRepository(tx)
+		now := time.Now()
+		var err error
+		due, err = outboxRepo.FindDueForUpdate(ctx, now, j.batchSize)
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+		}
+		return outboxRepo.Lease(ctx, ids, now.Add(outboxLease))
+	})
+	return due, err
+}
+
+func (j *OutboxRelayJob) fail(ctx context.Context, outboxRepo *repository.OutboxEventRepository, event *models.OutboxEvent, publishErr error) error {
+	attempts := event.Attempts + 1
+	dead := attempts >= j.maxAttempts
+	if dead {
+		log.Printf("outbox event %s (%s) failed %d times, giving up: %v", event.ID, event.EventType, attempts, publishErr)
+	} else {
+		log.Printf("failed to publish outbox event %s (%s), attempt %d: %v", event.ID, event.EventType, attempts, publishErr)
+	}
+	return outboxRepo.MarkFailed(ctx, event.ID, publishErr.Error(), time.Now().Add(outboxBackoff(attempts)), dead)
+}
+
+// outboxBackoff doubles the wait after every failed attempt, up to outboxMaxBackoff.
+func outboxBackoff(attempts int) time.Duration {
+	backoff := float64(outboxBaseBackoff) * math.Pow(2, float64(attempts-1))
+	if backoff > float64(outboxMaxBackoff) {
+		return outboxMaxBackoff
+	}
+	return time.Duration(backoff)
+}
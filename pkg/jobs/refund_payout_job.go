@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	contextUtils "order-service/pkg/context"
+	service "order-service/pkg/services"
+)
+
+// RefundPayoutJob retries paying out refunds whose payout failed when they were made.
+// Refunds younger than retryAfter are left to the request that made them.
+type RefundPayoutJob struct {
+	orderService *service.OrderService
+	retryAfter   time.Duration
+	batchSize    int
+}
+
+func NewRefundPayoutJob(orderService *service.OrderService, retryAfter time.Duration, batchSize int) *RefundPayoutJob {
+	return &RefundPayoutJob{
+		orderService: orderService,
+		retryAfter:   retryAfter,
+		batchSize:    batchSize,
+	}
+}
+
+func (j *RefundPayoutJob) RunOnce(ctx context.Context) error {
+	_, err := j.orderService.PayOutPendingRefunds(contextUtils.WithSystem(ctx), time.Now().Add(-j.retryAfter), j.batchSize)
+	return err
+}
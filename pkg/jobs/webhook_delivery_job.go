@@ -0,0 +1,33 @@
+package jobs
+
+import (
+	"context"
+
+	service "order-service/pkg/services"
+)
+
+// WebhookDeliveryJob sends the webhook deliveries that are due, batch by batch, until
+// none are left.
+type WebhookDeliveryJob struct {
+	webhookService *service.WebhookService
+	batchSize      int
+}
+
+func NewWebhookDeliveryJob(webhookService *service.WebhookService, batchSize int) *WebhookDeliveryJob {
+	return &WebhookDeliveryJob{
+		webhookService: webhookService,
+		batchSize:      batchSize,
+	}
+}
+
+func (j *WebhookDeliveryJob) RunOnce(ctx context.Context) error {
+	for {
+		n, err := j.webhookService.DeliverDue(ctx, j.batchSize)
+		if err != nil {
+			return err
+		}
+		if n < j.batchSize || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
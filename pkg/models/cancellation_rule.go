@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// CancellationRule is the policy for patients cancelling orders in one status. A patient
+// may cancel within WindowMinutes of the order reaching the status, or at any time when it
+// is not set. Orders that were paid for are refunded less a fee of FeeAmount plus
+// FeePercent of what the patient paid. Statuses without a rule can be cancelled at any
+// time free of charge.
+type CancellationRule struct {
+	Status        OrderStatus `gorm:"type:order_status;primaryKey" json:"status"`
+	WindowMinutes *int        `gorm:"type:int" json:"window_minutes,omitempty"`
+	FeePercent    float64     `gorm:"type:numeric(5,2);not null;default:0" json:"fee_percent"`
+	FeeAmount     float64     `gorm:"type:numeric(12,2);not null;default:0" json:"fee_amount"`
+	CreatedAt     time.Time   `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt     time.Time   `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+// Fee is what is kept when an order the patient paid is cancelled.
+func (r *CancellationRule) Fee(paid float64) float64 {
+	fee := r.FeeAmount + paid*r.FeePercent/100
+	if fee > paid {
+		fee = paid
+	}
+	return fee
+}
+
+func (r *CancellationRule) TableName() string {
+	return "cancellation_rules"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CoverageRule is what a healthcare entitlement pays for a medicine. A rule without a
+// medicine is the entitlement's default for medicines that have no rule of their own.
+// The insurer pays CoveredPercent of a line, less the patient's Copay, until AnnualCap
+// has been paid under the rule for the patient in the calendar year.
+type CoverageRule struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Entitlement    string     `gorm:"type:text;not null" json:"entitlement"`
+	MedicineID     *uuid.UUID `gorm:"type:uuid" json:"medicine_id,omitempty"`
+	CoveredPercent float64    `gorm:"type:numeric(5,2);not null;default:0" json:"covered_percent"`
+	Copay          float64    `gorm:"type:numeric(12,2);not null;default:0" json:"copay"`
+	Excluded       bool       `gorm:"not null;default:false" json:"excluded"`
+	AnnualCap      *float64   `gorm:"type:numeric(12,2)" json:"annual_cap,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	Medicine       *Medicine  `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+}
+
+func (r *CoverageRule) TableName() string {
+	return "coverage_rules"
+}
@@ -1,21 +1,140 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+type DrugClassification string
+
+const (
+	DrugClassificationOTC              DrugClassification = "otc"
+	DrugClassificationPharmacyOnly     DrugClassification = "pharmacy_only"
+	DrugClassificationPrescriptionOnly DrugClassification = "prescription_only"
+	DrugClassificationControlled       DrugClassification = "controlled"
+)
+
+// ClassificationRule describes how orders containing a drug class are handled.
+type ClassificationRule struct {
+	RequiresDoctorApproval    bool // the assigned doctor must approve the order before payment
+	RequiresSecondaryApproval bool // an admin must co-sign the approval before payment
+	RequiresQuantityCap       bool // the medicine cannot be ordered unless a per-order cap is configured
+}
+
+var classificationRules = map[DrugClassification]ClassificationRule{
+	DrugClassificationOTC:              {},
+	DrugClassificationPharmacyOnly:     {RequiresDoctorApproval: true},
+	DrugClassificationPrescriptionOnly: {RequiresDoctorApproval: true},
+	DrugClassificationControlled:       {RequiresDoctorApproval: true, RequiresSecondaryApproval: true, RequiresQuantityCap: true},
+}
+
+// Rule returns the handling rules for the classification. Unknown classes get the
+// strictest rules.
+func (c DrugClassification) Rule() ClassificationRule {
+	if rule, ok := classificationRules[c]; ok {
+		return rule
+	}
+	return classificationRules[DrugClassificationControlled]
+}
+
+func (c DrugClassification) IsValid() bool {
+	_, ok := classificationRules[c]
+	return ok
+}
+
+// VATTreatment is how value added tax applies to a medicine's sales.
+type VATTreatment string
+
+const (
+	VATTreatmentStandard VATTreatment = "standard"
+	VATTreatmentExempt   VATTreatment = "exempt"
+)
+
+// StandardVATRate is the Thai VAT rate in percent.
+const StandardVATRate = 7.0
+
+// Rate returns the VAT rate in percent charged under the treatment.
+func (t VATTreatment) Rate() float64 {
+	if t == VATTreatmentExempt {
+		return 0
+	}
+	return StandardVATRate
+}
+
+func (t VATTreatment) IsValid() bool {
+	return t == VATTreatmentStandard || t == VATTreatmentExempt
+}
+
 type Medicine struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
-	Name      string         `gorm:"type:text;not null" json:"name"`
-	Price     float64        `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
-	Stock     float64        `gorm:"type:numeric(12,2);not null;check:stock >= 0" json:"stock"`
-	Unit      string         `gorm:"type:text;not null" json:"unit"`
-	CreatedAt time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`
-	UpdatedAt time.Time      `gorm:"autoUpdateTime:milli" json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID                  uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	SKU                 string               `gorm:"column:sku;type:text;not null" json:"sku"`
+	Name                string               `gorm:"type:text;not null" json:"name"`
+	GenericName         *string              `gorm:"type:text" json:"generic_name,omitempty"`
+	BrandName           *string              `gorm:"type:text" json:"brand_name,omitempty"`
+	NameTh              *string              `gorm:"type:text" json:"name_th,omitempty"`
+	ATCCode             *string              `gorm:"column:atc_code;type:text" json:"atc_code,omitempty"`
+	StrengthValue       *float64             `gorm:"type:numeric(12,3)" json:"strength_value,omitempty"`
+	StrengthUnit        *string              `gorm:"type:text" json:"strength_unit,omitempty"`
+	Price               float64              `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	Stock               float64              `gorm:"type:numeric(12,2);not null;check:stock >= 0" json:"stock"`
+	Reserved            float64              `gorm:"type:numeric(12,2);not null;default:0;check:reserved >= 0" json:"reserved"`
+	Unit                string               `gorm:"type:text;not null" json:"unit"`
+	Classification      DrugClassification   `gorm:"type:drug_classification;not null;default:'prescription_only'" json:"classification"`
+	VATTreatment        VATTreatment         `gorm:"column:vat_treatment;type:vat_treatment;not null;default:'standard'" json:"vat_treatment"`
+	MaxQuantityPerOrder *float64             `gorm:"type:numeric(12,2)" json:"max_quantity_per_order,omitempty"`
+	ReorderPoint        *float64             `gorm:"type:numeric(12,2);check:reorder_point >= 0" json:"reorder_point,omitempty"`
+	ReorderQuantity     *float64             `gorm:"type:numeric(12,2);check:reorder_quantity > 0" json:"reorder_quantity,omitempty"`
+	CreatedAt           time.Time            `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt           time.Time            `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	DeletedAt           gorm.DeletedAt       `gorm:"index" json:"deleted_at,omitempty"`
+	Category            *TherapeuticCategory `gorm:"foreignKey:ATCCode;references:Code" json:"category,omitempty"`
+	Units               []MedicineUnit       `gorm:"foreignKey:MedicineID" json:"units,omitempty"`
+}
+
+// CheckQuantity validates a per-order quantity against the medicine's cap and class rules.
+func (m *Medicine) CheckQuantity(quantity float64) error {
+	if m.MaxQuantityPerOrder == nil {
+		if m.Classification.Rule().RequiresQuantityCap {
+			return fmt.Errorf("%s has no per-order quantity cap configured and cannot be ordered", m.Name)
+		}
+		return nil
+	}
+	if quantity > *m.MaxQuantityPerOrder {
+		return fmt.Errorf("%s is limited to %g %s per order", m.Name, *m.MaxQuantityPerOrder, m.Unit)
+	}
+	return nil
+}
+
+// Strength renders the strength per base unit, e.g. "500 mg", or "" when unknown.
+func (m *Medicine) Strength() string {
+	if m.StrengthValue == nil || m.StrengthUnit == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*m.StrengthValue, 'f', -1, 64) + " " + *m.StrengthUnit
+}
+
+// ClinicalName is the name used for interaction and allergy checks: the generic name when
+// one is recorded, otherwise the catalog name.
+func (m *Medicine) ClinicalName() string {
+	if m.GenericName != nil && *m.GenericName != "" {
+		return *m.GenericName
+	}
+	return m.Name
+}
+
+// Available is the stock that is not yet held for approved orders.
+func (m *Medicine) Available() float64 {
+	return m.Stock - m.Reserved
+}
+
+// IsLowStock reports whether the available stock has fallen to or below the reorder point.
+// Medicines without a reorder point are never low.
+func (m *Medicine) IsLowStock() bool {
+	return m.ReorderPoint != nil && m.Available() <= *m.ReorderPoint
 }
 
 func (m *Medicine) TableName() string {
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MedicineBatch is a received lot of a medicine. Quantity is what remains of the
+// lot; ReceivedQuantity is what originally came in. Lots without an expiry date
+// are allocated after every dated lot.
+type MedicineBatch struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	MedicineID       uuid.UUID  `gorm:"type:uuid;not null" json:"medicine_id"`
+	LotNumber        string     `gorm:"type:text;not null" json:"lot_number"`
+	ExpiryDate       *time.Time `gorm:"type:date" json:"expiry_date,omitempty"`
+	ReceivedQuantity float64    `gorm:"type:numeric(12,2);not null;check:received_quantity >= 0" json:"received_quantity"`
+	Quantity         float64    `gorm:"type:numeric(12,2);not null;check:quantity >= 0" json:"quantity"`
+	ReceivedAt       time.Time  `gorm:"not null" json:"received_at"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	Medicine         *Medicine  `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+}
+
+// IsExpired reports whether the lot can no longer be dispensed on the given day.
+func (b *MedicineBatch) IsExpired(now time.Time) bool {
+	if b.ExpiryDate == nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return b.ExpiryDate.Before(today)
+}
+
+func (b *MedicineBatch) TableName() string {
+	return "medicine_batches"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MedicinePrice is the price of one sellable unit over a period of time. The ranges of a
+// unit do not overlap; the latest one has no end. Ranges that start in the future are
+// scheduled price changes.
+type MedicinePrice struct {
+	ID            uuid.UUID     `gorm:"type:uuid;primaryKey" json:"id"`
+	MedicineID    uuid.UUID     `gorm:"type:uuid;not null" json:"medicine_id"`
+	UnitID        uuid.UUID     `gorm:"type:uuid;not null" json:"unit_id"`
+	Price         float64       `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	EffectiveFrom time.Time     `gorm:"type:timestamptz;not null" json:"effective_from"`
+	EffectiveTo   *time.Time    `gorm:"type:timestamptz" json:"effective_to,omitempty"`
+	Reason        *string       `gorm:"type:text" json:"reason,omitempty"`
+	CreatedBy     *uuid.UUID    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt     time.Time     `gorm:"autoCreateTime:milli" json:"created_at"`
+	Unit          *MedicineUnit `gorm:"foreignKey:UnitID;references:ID" json:"unit,omitempty"`
+}
+
+// IsEffectiveAt reports whether the price applies at the given time.
+func (p *MedicinePrice) IsEffectiveAt(at time.Time) bool {
+	return !at.Before(p.EffectiveFrom) && (p.EffectiveTo == nil || at.Before(*p.EffectiveTo))
+}
+
+func (p *MedicinePrice) TableName() string {
+	return "medicine_prices"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MedicineUnit is a sellable pack of a medicine. Factor is the number of base units in
+// one pack (a strip of 10 tablets has factor 10) and Price is the price of one pack.
+// Every medicine has exactly one base unit with factor 1, named after Medicine.Unit.
+type MedicineUnit struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	MedicineID uuid.UUID `gorm:"type:uuid;not null" json:"medicine_id"`
+	Name       string    `gorm:"type:text;not null" json:"name"`
+	Factor     float64   `gorm:"type:numeric(12,3);not null;check:factor > 0" json:"factor"`
+	Price      float64   `gorm:"type:numeric(12,2);not null;check:price >= 0" json:"price"`
+	IsBase     bool      `gorm:"not null;default:false" json:"is_base"`
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+// Label describes the pack in base units, e.g. "strip (10 tablet)".
+func (u *MedicineUnit) Label(baseUnit string) string {
+	if u.IsBase || u.Factor == 1 {
+		return u.Name
+	}
+	return u.Name + " (" + strconv.FormatFloat(u.Factor, 'f', -1, 64) + " " + baseUnit + ")"
+}
+
+func (u *MedicineUnit) TableName() string {
+	return "medicine_units"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreference is how a patient or doctor wants to be notified: the language
+// of their messages and, for each channel, their address and whether it is on. Users
+// without preferences are not notified.
+type NotificationPreference struct {
+	UserID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Language     string    `gorm:"type:text;not null;default:'th'" json:"language"`
+	Email        *string   `gorm:"type:text" json:"email,omitempty"`
+	EmailEnabled bool      `gorm:"not null;default:false" json:"email_enabled"`
+	Phone        *string   `gorm:"type:text" json:"phone,omitempty"`
+	SMSEnabled   bool      `gorm:"column:sms_enabled;not null;default:false" json:"sms_enabled"`
+	LINEUserID   *string   `gorm:"column:line_user_id;type:text" json:"line_user_id,omitempty"`
+	LINEEnabled  bool      `gorm:"column:line_enabled;not null;default:false" json:"line_enabled"`
+	CreatedAt    time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+type NotificationLogStatus string
+
+const (
+	NotificationLogStatusSent   NotificationLogStatus = "sent"
+	NotificationLogStatusFailed NotificationLogStatus = "failed"
+)
+
+// NotificationLog is a message sent, or that failed to send, to one user on one
+// channel for an event. A message is sent once per event, user and channel.
+type NotificationLog struct {
+	ID        uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID             `gorm:"type:uuid;not null" json:"user_id"`
+	OrderID   *uuid.UUID            `gorm:"type:uuid" json:"order_id,omitempty"`
+	EventID   uuid.UUID             `gorm:"type:uuid;not null" json:"event_id"`
+	Message   string                `gorm:"type:text;not null" json:"message"`
+	Channel   string                `gorm:"type:text;not null" json:"channel"`
+	Recipient string                `gorm:"type:text;not null" json:"recipient"`
+	Language  string                `gorm:"type:text;not null" json:"language"`
+	Subject   string                `gorm:"type:text;not null" json:"subject"`
+	Body      string                `gorm:"type:text;not null" json:"body"`
+	Status    NotificationLogStatus `gorm:"type:text;not null" json:"status"`
+	Error     *string               `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt time.Time             `gorm:"autoCreateTime:milli" json:"created_at"`
+}
@@ -17,20 +17,141 @@ const (
 	OrderStatusShipped    OrderStatus = "shipped"
 	OrderStatusDelivered  OrderStatus = "delivered"
 	OrderStatusCancelled  OrderStatus = "cancelled"
+	// OrderStatusPartiallyRefunded is a paid order some of whose items were refunded;
+	// OrderStatusRefunded one whose payment was refunded in full.
+	OrderStatusPartiallyRefunded OrderStatus = "partially_refunded"
+	OrderStatusRefunded          OrderStatus = "refunded"
+	// OrderStatusExpired is a pending order no doctor reviewed, an approved order that was
+	// never paid, or an order sent back to the patient that was never resubmitted, in time.
+	OrderStatusExpired OrderStatus = "expired"
+	// OrderStatusChangesRequested is a pending order the doctor sent back to the patient
+	// with comments; it returns to pending when the patient resubmits it.
+	OrderStatusChangesRequested OrderStatus = "changes_requested"
 )
 
+// orderTransitions lists the statuses an order may move to from each status.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:           {OrderStatusApproved, OrderStatusRejected, OrderStatusChangesRequested, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusChangesRequested:  {OrderStatusPending, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusApproved:          {OrderStatusPaid, OrderStatusCancelled, OrderStatusExpired},
+	OrderStatusPaid:              {OrderStatusProcessing, OrderStatusShipped, OrderStatusPartiallyRefunded, OrderStatusRefunded, OrderStatusCancelled},
+	OrderStatusProcessing:        {OrderStatusShipped, OrderStatusPartiallyRefunded, OrderStatusRefunded, OrderStatusCancelled},
+	OrderStatusShipped:           {OrderStatusDelivered, OrderStatusPartiallyRefunded, OrderStatusRefunded},
+	OrderStatusDelivered:         {OrderStatusPartiallyRefunded, OrderStatusRefunded},
+	OrderStatusPartiallyRefunded: {OrderStatusPartiallyRefunded, OrderStatusRefunded},
+}
+
+// RejectionReasonCode is why a doctor rejected an order.
+type RejectionReasonCode string
+
+const (
+	RejectionReasonNotIndicated            RejectionReasonCode = "not_indicated"
+	RejectionReasonContraindicated         RejectionReasonCode = "contraindicated"
+	RejectionReasonAllergy                 RejectionReasonCode = "allergy"
+	RejectionReasonDrugInteraction         RejectionReasonCode = "drug_interaction"
+	RejectionReasonDuplicateTherapy        RejectionReasonCode = "duplicate_therapy"
+	RejectionReasonConsultationRequired    RejectionReasonCode = "consultation_required"
+	RejectionReasonInsufficientInformation RejectionReasonCode = "insufficient_information"
+	// RejectionReasonOther must come with a free text reason.
+	RejectionReasonOther RejectionReasonCode = "other"
+)
+
+// rejectionReasonCodes lists every valid rejection reason code.
+var rejectionReasonCodes = []RejectionReasonCode{
+	RejectionReasonNotIndicated,
+	RejectionReasonContraindicated,
+	RejectionReasonAllergy,
+	RejectionReasonDrugInteraction,
+	RejectionReasonDuplicateTherapy,
+	RejectionReasonConsultationRequired,
+	RejectionReasonInsufficientInformation,
+	RejectionReasonOther,
+}
+
+func (c RejectionReasonCode) IsValid() bool {
+	for _, code := range rejectionReasonCodes {
+		if code == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 type Order struct {
-	ID               uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
-	PatientID        uuid.UUID   `gorm:"type:uuid;not null" json:"patient_id"`
-	DoctorID         *uuid.UUID  `gorm:"type:uuid" json:"doctor_id,omitempty"`
-	TotalAmount      float64     `gorm:"type:numeric(12,2);not null;check:total_amount >= 0" json:"total_amount"`
-	Note             *string     `gorm:"type:text" json:"note,omitempty"`
-	SubmittedAt      *time.Time  `json:"submitted_at,omitempty"`
-	ReviewedAt       *time.Time  `json:"reviewed_at,omitempty"`
-	Status           OrderStatus `gorm:"type:order_status;not null;default:'pending'" json:"status"`
-	CreatedAt        time.Time   `gorm:"autoCreateTime:milli" json:"created_at"`
-	UpdatedAt        time.Time   `gorm:"autoUpdateTime:milli" json:"updated_at"`
-	OrderItems       []OrderItem `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"order_items,omitempty"`
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PatientID     uuid.UUID  `gorm:"type:uuid;not null" json:"patient_id"`
+	DoctorID      *uuid.UUID `gorm:"type:uuid" json:"doctor_id,omitempty"`
+	AppointmentID *uuid.UUID `gorm:"type:uuid" json:"appointment_id,omitempty"`
+	TotalAmount   float64    `gorm:"type:numeric(12,2);not null;check:total_amount >= 0" json:"total_amount"`
+	// SubtotalAmount is the sum of the item prices; TotalAmount is what is left after
+	// DiscountAmount.
+	SubtotalAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"subtotal_amount"`
+	DiscountAmount float64 `gorm:"type:numeric(12,2);not null;default:0" json:"discount_amount"`
+	// DeliveryFee is charged to the patient on top of TotalAmount when the order is
+	// delivered.
+	DeliveryFee float64 `gorm:"type:numeric(12,2);not null;default:0" json:"delivery_fee"`
+	// VATAmount is the value added tax included in TotalAmount.
+	VATAmount float64 `gorm:"column:vat_amount;type:numeric(12,2);not null;default:0" json:"vat_amount"`
+	// CouponCode is the coupon the patient applied to the order, if any.
+	CouponCode *string `gorm:"type:text" json:"coupon_code,omitempty"`
+	// Entitlement is the healthcare entitlement the order was covered under, if any.
+	Entitlement            *string     `gorm:"type:text" json:"entitlement,omitempty"`
+	InsurerAmount          float64     `gorm:"type:numeric(12,2);not null;default:0" json:"insurer_amount"`
+	PatientAmount          float64     `gorm:"type:numeric(12,2);not null;default:0" json:"patient_amount"`
+	Note                   *string     `gorm:"type:text" json:"note,omitempty"`
+	SubmittedAt            *time.Time  `json:"submitted_at,omitempty"`
+	ReviewedAt             *time.Time  `json:"reviewed_at,omitempty"`
+	Status                 OrderStatus `gorm:"type:order_status;not null;default:'pending'" json:"status"`
+	ControlledApprovedBy   *uuid.UUID  `gorm:"type:uuid" json:"controlled_approved_by,omitempty"`
+	ControlledApprovedAt   *time.Time  `json:"controlled_approved_at,omitempty"`
+	ClinicalOverrideReason *string     `gorm:"type:text" json:"clinical_override_reason,omitempty"`
+	ClinicalOverriddenAt   *time.Time  `json:"clinical_overridden_at,omitempty"`
+	// why the doctor rejected the order; RejectionReason is the doctor's own words
+	RejectionReasonCode *RejectionReasonCode `gorm:"type:text" json:"rejection_reason_code,omitempty"`
+	RejectionReason     *string              `gorm:"type:text" json:"rejection_reason,omitempty"`
+	// who cancelled the order and why; CancellationFee is what was kept from the patient's
+	// payment when a paid order was cancelled, the rest was refunded
+	CancellationReason *string              `gorm:"type:text" json:"cancellation_reason,omitempty"`
+	CancelledBy        *uuid.UUID           `gorm:"type:uuid" json:"cancelled_by,omitempty"`
+	CancelledByRole    *string              `gorm:"type:text" json:"cancelled_by_role,omitempty"`
+	CancelledAt        *time.Time           `json:"cancelled_at,omitempty"`
+	CancellationFee    float64              `gorm:"type:numeric(12,2);not null;default:0" json:"cancellation_fee"`
+	CreatedAt          time.Time            `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt          time.Time            `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	OrderItems         []OrderItem          `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"order_items,omitempty"`
+	RequestedItems     []OrderRequestedItem `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"requested_items,omitempty"`
+	Discounts          []OrderDiscount      `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"discounts,omitempty"`
+}
+
+// RequiresDoctorApproval reports whether any item needs the doctor to approve the order.
+// Items must be loaded with their medicine.
+func (o *Order) RequiresDoctorApproval() bool {
+	for _, item := range o.OrderItems {
+		if item.Medicine == nil || item.Medicine.Classification.Rule().RequiresDoctorApproval {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiresSecondaryApproval reports whether any item needs an admin co-sign before payment.
+// Items must be loaded with their medicine.
+func (o *Order) RequiresSecondaryApproval() bool {
+	for _, item := range o.OrderItems {
+		if item.Medicine == nil || item.Medicine.Classification.Rule().RequiresSecondaryApproval {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *Order) TableName() string {
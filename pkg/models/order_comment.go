@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderCommentKind is what prompted a comment on an order.
+type OrderCommentKind string
+
+const (
+	OrderCommentKindComment OrderCommentKind = "comment"
+	// OrderCommentKindChangeRequest is the doctor's comment when sending an order back to
+	// the patient, OrderCommentKindResubmission the patient's when sending it back.
+	OrderCommentKindChangeRequest OrderCommentKind = "change_request"
+	OrderCommentKindResubmission  OrderCommentKind = "resubmission"
+	OrderCommentKindRejection     OrderCommentKind = "rejection"
+)
+
+// OrderComment is a message in the thread the patient and the doctor keep on an order.
+// Comments are only ever appended.
+type OrderComment struct {
+	ID         uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID    uuid.UUID        `gorm:"type:uuid;not null" json:"order_id"`
+	AuthorID   uuid.UUID        `gorm:"type:uuid;not null" json:"author_id"`
+	AuthorRole string           `gorm:"type:text;not null" json:"author_role"`
+	Kind       OrderCommentKind `gorm:"type:text;not null" json:"kind"`
+	Body       string           `gorm:"type:text;not null" json:"body"`
+	CreatedAt  time.Time        `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (c *OrderComment) TableName() string {
+	return "order_comments"
+}
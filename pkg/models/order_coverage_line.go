@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderCoverageLine records how one order item was split between the insurer and the
+// patient when the order was approved.
+type OrderCoverageLine struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null" json:"order_id"`
+	OrderItemID   uuid.UUID  `gorm:"type:uuid;not null" json:"order_item_id"`
+	Entitlement   *string    `gorm:"type:text" json:"entitlement,omitempty"`
+	RuleID        *uuid.UUID `gorm:"type:uuid" json:"rule_id,omitempty"`
+	LineTotal     float64    `gorm:"type:numeric(12,2);not null" json:"line_total"`
+	InsurerAmount float64    `gorm:"type:numeric(12,2);not null" json:"insurer_amount"`
+	PatientAmount float64    `gorm:"type:numeric(12,2);not null" json:"patient_amount"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (l *OrderCoverageLine) TableName() string {
+	return "order_coverage_lines"
+}
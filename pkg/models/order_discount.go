@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderDiscount is the discount one promotion gave on one order item. Order-wide
+// discounts are spread over the items so each item's net price is known when it is
+// covered or refunded.
+type OrderDiscount struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null" json:"order_id"`
+	OrderItemID   uuid.UUID  `gorm:"type:uuid;not null" json:"order_item_id"`
+	PromotionID   *uuid.UUID `gorm:"type:uuid" json:"promotion_id,omitempty"`
+	PromotionName string     `gorm:"type:text;not null" json:"promotion_name"`
+	CouponCode    *string    `gorm:"type:text" json:"coupon_code,omitempty"`
+	Amount        float64    `gorm:"type:numeric(12,2);not null" json:"amount"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (d *OrderDiscount) TableName() string {
+	return "order_discounts"
+}
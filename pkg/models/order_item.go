@@ -4,12 +4,57 @@ import (
 	"github.com/google/uuid"
 )
 
+type DosageRoute string
+
+const (
+	DosageRouteOral        DosageRoute = "oral"
+	DosageRouteSublingual  DosageRoute = "sublingual"
+	DosageRouteTopical     DosageRoute = "topical"
+	DosageRouteInhalation  DosageRoute = "inhalation"
+	DosageRouteOphthalmic  DosageRoute = "ophthalmic"
+	DosageRouteOtic        DosageRoute = "otic"
+	DosageRouteNasal       DosageRoute = "nasal"
+	DosageRouteRectal      DosageRoute = "rectal"
+	DosageRouteVaginal     DosageRoute = "vaginal"
+	DosageRouteInjection   DosageRoute = "injection"
+	DosageRouteTransdermal DosageRoute = "transdermal"
+)
+
 type OrderItem struct {
-	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	OrderID    uuid.UUID `gorm:"type:uuid;not null" json:"order_id"`
-	MedicineID uuid.UUID `gorm:"type:uuid;not null" json:"medicine_id"`
-	Quantity   float64   `gorm:"type:numeric(12,2);not null;check:quantity > 0" json:"quantity"`
-	Medicine   *Medicine `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+	ID              uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID         uuid.UUID        `gorm:"type:uuid;not null" json:"order_id"`
+	MedicineID      uuid.UUID        `gorm:"type:uuid;not null" json:"medicine_id"`
+	Quantity        float64          `gorm:"type:numeric(12,2);not null;check:quantity > 0" json:"quantity"`
+	UnitID          *uuid.UUID       `gorm:"type:uuid" json:"unit_id,omitempty"`
+	UnitFactor      float64          `gorm:"type:numeric(12,3);not null;default:1;check:unit_factor > 0" json:"unit_factor"`
+	UnitPrice       float64          `gorm:"type:numeric(12,2);not null;default:0" json:"unit_price"`
+	Dose            *float64         `gorm:"type:numeric(12,2)" json:"dose,omitempty"`
+	FrequencyPerDay *float64         `gorm:"type:numeric(6,2)" json:"frequency_per_day,omitempty"`
+	Route           *DosageRoute     `gorm:"type:text" json:"route,omitempty"`
+	DurationDays    *int             `gorm:"type:int" json:"duration_days,omitempty"`
+	Instructions    *string          `gorm:"type:text" json:"instructions,omitempty"`
+	Medicine        *Medicine        `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+	Unit            *MedicineUnit    `gorm:"foreignKey:UnitID;references:ID" json:"unit,omitempty"`
+	Batches         []OrderItemBatch `gorm:"foreignKey:OrderItemID;constraint:OnDelete:CASCADE" json:"batches,omitempty"`
+}
+
+// LineTotal is the price of the item at the unit price it was ordered at.
+func (oi *OrderItem) LineTotal() float64 {
+	return oi.UnitPrice * oi.Quantity
+}
+
+// BaseQuantity is the quantity in the medicine's base unit. Quantity is counted in the
+// ordered unit; UnitFactor is that unit's factor at the time of ordering.
+func (oi *OrderItem) BaseQuantity() float64 {
+	if oi.UnitFactor == 0 {
+		return oi.Quantity
+	}
+	return oi.Quantity * oi.UnitFactor
+}
+
+// HasDosage reports whether the doctor attached a dosage regimen to the item.
+func (oi *OrderItem) HasDosage() bool {
+	return oi.Dose != nil && oi.FrequencyPerDay != nil && oi.DurationDays != nil
 }
 
 func (oi *OrderItem) TableName() string {
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderItemBatch records how much of an order item was taken from each batch,
+// so dispensed lots can be traced when a batch is recalled.
+type OrderItemBatch struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderItemID uuid.UUID      `gorm:"type:uuid;not null" json:"order_item_id"`
+	BatchID     uuid.UUID      `gorm:"type:uuid;not null" json:"batch_id"`
+	Quantity    float64        `gorm:"type:numeric(12,2);not null;check:quantity > 0" json:"quantity"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`
+	Batch       *MedicineBatch `gorm:"foreignKey:BatchID;references:ID" json:"batch,omitempty"`
+}
+
+func (ib *OrderItemBatch) TableName() string {
+	return "order_item_batches"
+}
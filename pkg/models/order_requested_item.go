@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RequestedItemStatus string
+
+const (
+	RequestedItemStatusRequested RequestedItemStatus = "requested"
+	RequestedItemStatusAccepted  RequestedItemStatus = "accepted"
+	RequestedItemStatusModified  RequestedItemStatus = "modified"
+	RequestedItemStatusStruck    RequestedItemStatus = "struck"
+)
+
+// OrderRequestedItem is a medicine the patient asked for when creating the order.
+// It is kept alongside the doctor's final order items so both versions stay visible.
+type OrderRequestedItem struct {
+	ID               uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID          uuid.UUID           `gorm:"type:uuid;not null" json:"order_id"`
+	MedicineID       uuid.UUID           `gorm:"type:uuid;not null" json:"medicine_id"`
+	Quantity         float64             `gorm:"type:numeric(12,2);not null;check:quantity > 0" json:"quantity"`
+	UnitID           *uuid.UUID          `gorm:"type:uuid" json:"unit_id,omitempty"`
+	UnitFactor       float64             `gorm:"type:numeric(12,3);not null;default:1;check:unit_factor > 0" json:"unit_factor"`
+	Reason           *string             `gorm:"type:text" json:"reason,omitempty"`
+	Status           RequestedItemStatus `gorm:"type:requested_item_status;not null;default:'requested'" json:"status"`
+	ApprovedQuantity *float64            `gorm:"type:numeric(12,2)" json:"approved_quantity,omitempty"`
+	DoctorNote       *string             `gorm:"type:text" json:"doctor_note,omitempty"`
+	CreatedAt        time.Time           `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt        time.Time           `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	Medicine         *Medicine           `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+	Unit             *MedicineUnit       `gorm:"foreignKey:UnitID;references:ID" json:"unit,omitempty"`
+}
+
+func (ri *OrderRequestedItem) TableName() string {
+	return "order_requested_items"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatusChange is an entry in an order's history: the order moved from FromStatus,
+// or was created when it is nil, to ToStatus. Entries are only ever appended.
+type OrderStatusChange struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID    uuid.UUID    `gorm:"type:uuid;not null" json:"order_id"`
+	FromStatus *OrderStatus `gorm:"type:order_status" json:"from_status,omitempty"`
+	ToStatus   OrderStatus  `gorm:"type:order_status;not null" json:"to_status"`
+	ActorID    *uuid.UUID   `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorRole  string       `gorm:"type:text;not null" json:"actor_role"`
+	Reason     *string      `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (c *OrderStatusChange) TableName() string {
+	return "order_status_history"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderTaxLine records the VAT included in one order item's price after discounts.
+// Amount includes the tax; TaxableAmount is the amount before it.
+type OrderTaxLine struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID       uuid.UUID    `gorm:"type:uuid;not null" json:"order_id"`
+	OrderItemID   uuid.UUID    `gorm:"type:uuid;not null" json:"order_item_id"`
+	VATTreatment  VATTreatment `gorm:"column:vat_treatment;type:vat_treatment;not null" json:"vat_treatment"`
+	VATRate       float64      `gorm:"column:vat_rate;type:numeric(5,2);not null" json:"vat_rate"`
+	Amount        float64      `gorm:"type:numeric(12,2);not null" json:"amount"`
+	TaxableAmount float64      `gorm:"type:numeric(12,2);not null" json:"taxable_amount"`
+	VATAmount     float64      `gorm:"column:vat_amount;type:numeric(12,2);not null" json:"vat_amount"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (l *OrderTaxLine) TableName() string {
+	return "order_tax_lines"
+}
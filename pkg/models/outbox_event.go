@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"order-service/pkg/events"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxEventStatus string
+
+const (
+	OutboxEventStatusPending   OutboxEventStatus = "pending"
+	OutboxEventStatusPublished OutboxEventStatus = "published"
+	// OutboxEventStatusDead marks an event that failed every attempt. It is kept for
+	// inspection and is no longer retried.
+	OutboxEventStatusDead OutboxEventStatus = "dead"
+)
+
+// OutboxEvent is a domain event waiting to be published, written in the same
+// transaction as the change it describes. Events of one aggregate are published in
+// the order they were written.
+type OutboxEvent struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	AggregateType string            `gorm:"type:text;not null" json:"aggregate_type"`
+	AggregateID   uuid.UUID         `gorm:"type:uuid;not null" json:"aggregate_id"`
+	EventType     string            `gorm:"type:text;not null" json:"event_type"`
+	Payload       json.RawMessage   `gorm:"type:jsonb;not null" json:"payload"`
+	Status        OutboxEventStatus `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Attempts      int               `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time         `gorm:"not null" json:"next_attempt_at"`
+	LastError     *string           `gorm:"type:text" json:"last_error,omitempty"`
+	PublishedAt   *time.Time        `json:"published_at,omitempty"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+// ToEvent returns the event as it is published.
+func (e *OutboxEvent) ToEvent() events.Event {
+	return events.Event{
+		ID:            e.ID.String(),
+		Type:          e.EventType,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID.String(),
+		OccurredAt:    e.CreatedAt,
+		Payload:       e.Payload,
+	}
+}
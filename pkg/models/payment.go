@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentPayer string
+
+const (
+	PaymentPayerPatient PaymentPayer = "patient"
+	PaymentPayerInsurer PaymentPayer = "insurer"
+)
+
+// PaymentMethod is how the patient paid their share. Insurer claims have no method.
+type PaymentMethod string
+
+const (
+	PaymentMethodCash         PaymentMethod = "cash"
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodPromptPay    PaymentMethod = "promptpay"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusPending  PaymentStatus = "pending"
+	PaymentStatusCaptured PaymentStatus = "captured"
+	// PaymentStatusRefunded is a captured payment that was given back in full;
+	// PaymentStatusVoided an insurer claim dropped because its order was refunded.
+	PaymentStatusRefunded PaymentStatus = "refunded"
+	PaymentStatusVoided   PaymentStatus = "voided"
+)
+
+// Payment is the part of an order's total owed by one payer. The patient's share is
+// captured when the order is paid; the insurer's share stays pending as a claim.
+type Payment struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID     uuid.UUID      `gorm:"type:uuid;not null" json:"order_id"`
+	Payer       PaymentPayer   `gorm:"type:payment_payer;not null" json:"payer"`
+	Entitlement *string        `gorm:"type:text" json:"entitlement,omitempty"`
+	Method      *PaymentMethod `gorm:"type:payment_method" json:"method,omitempty"`
+	Amount      float64        `gorm:"type:numeric(12,2);not null;check:amount >= 0" json:"amount"`
+	Status      PaymentStatus  `gorm:"type:payment_status;not null;default:'pending'" json:"status"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+func (p *Payment) TableName() string {
+	return "payments"
+}
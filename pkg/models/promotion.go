@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PromotionKind string
+
+const (
+	PromotionKindPercentage PromotionKind = "percentage"
+	PromotionKindFixed      PromotionKind = "fixed"
+)
+
+type PromotionScope string
+
+const (
+	PromotionScopeOrder    PromotionScope = "order"
+	PromotionScopeMedicine PromotionScope = "medicine"
+)
+
+// Promotion is a discount on whole orders or on one medicine. Promotions without a
+// coupon code apply automatically; the others only once the patient enters the code.
+// A promotion that is not stackable is never combined with another one.
+type Promotion struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name            string         `gorm:"type:text;not null" json:"name"`
+	Kind            PromotionKind  `gorm:"type:promotion_kind;not null" json:"kind"`
+	Value           float64        `gorm:"type:numeric(12,2);not null" json:"value"`
+	Scope           PromotionScope `gorm:"type:promotion_scope;not null;default:'order'" json:"scope"`
+	MedicineID      *uuid.UUID     `gorm:"type:uuid" json:"medicine_id,omitempty"`
+	CouponCode      *string        `gorm:"type:text" json:"coupon_code,omitempty"`
+	MinOrderAmount  *float64       `gorm:"type:numeric(12,2)" json:"min_order_amount,omitempty"`
+	MaxDiscount     *float64       `gorm:"type:numeric(12,2)" json:"max_discount,omitempty"`
+	UsageLimit      *int           `gorm:"type:int" json:"usage_limit,omitempty"`
+	PerPatientLimit *int           `gorm:"type:int" json:"per_patient_limit,omitempty"`
+	Stackable       bool           `gorm:"not null;default:true" json:"stackable"`
+	Active          bool           `gorm:"not null;default:true" json:"active"`
+	StartsAt        time.Time      `gorm:"not null" json:"starts_at"`
+	EndsAt          *time.Time     `json:"ends_at,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	Medicine        *Medicine      `gorm:"foreignKey:MedicineID;references:ID" json:"medicine,omitempty"`
+}
+
+// IsRunningAt reports whether the promotion is active and inside its validity window.
+func (p *Promotion) IsRunningAt(t time.Time) bool {
+	return p.Active && !t.Before(p.StartsAt) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+func (p *Promotion) TableName() string {
+	return "promotions"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundStatus is whether a refund's amount has been paid back through the payment
+// provider yet.
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "pending"
+	RefundStatusCompleted RefundStatus = "completed"
+)
+
+// Refund gives back part or all of the patient's payment for an order. Amount is paid
+// back to the patient through the payment provider; InsurerAmount is taken off the
+// pending insurer claim for the same items. A refund is recorded pending and paid out
+// once it is committed; LastError is why the last payout attempt failed.
+type Refund struct {
+	ID                uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	OrderID           uuid.UUID    `gorm:"type:uuid;not null" json:"order_id"`
+	PaymentID         uuid.UUID    `gorm:"type:uuid;not null" json:"payment_id"`
+	Amount            float64      `gorm:"type:numeric(12,2);not null;check:amount >= 0" json:"amount"`
+	InsurerAmount     float64      `gorm:"type:numeric(12,2);not null;default:0" json:"insurer_amount"`
+	Reason            string       `gorm:"type:text;not null" json:"reason"`
+	ProviderReference *string      `gorm:"type:text" json:"provider_reference,omitempty"`
+	Status            RefundStatus `gorm:"type:text;not null;default:'completed'" json:"status"`
+	LastError         *string      `gorm:"type:text" json:"last_error,omitempty"`
+	ActorID           *uuid.UUID   `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorRole         string       `gorm:"type:text;not null" json:"actor_role"`
+	CreatedAt         time.Time    `gorm:"autoCreateTime:milli" json:"created_at"`
+	Items             []RefundItem `gorm:"foreignKey:RefundID;constraint:OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+func (r *Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem is the quantity of an order item given back in a refund, counted in the
+// unit it was ordered in.
+type RefundItem struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	RefundID      uuid.UUID `gorm:"type:uuid;not null" json:"refund_id"`
+	OrderItemID   uuid.UUID `gorm:"type:uuid;not null" json:"order_item_id"`
+	Quantity      float64   `gorm:"type:numeric(12,2);not null;check:quantity > 0" json:"quantity"`
+	Amount        float64   `gorm:"type:numeric(12,2);not null" json:"amount"`
+	InsurerAmount float64   `gorm:"type:numeric(12,2);not null;default:0" json:"insurer_amount"`
+}
+
+func (ri *RefundItem) TableName() string {
+	return "refund_items"
+}
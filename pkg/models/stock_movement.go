@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockMovementType string
+
+const (
+	StockMovementReceive  StockMovementType = "receive"  // goods received into a batch
+	StockMovementReserve  StockMovementType = "reserve"  // held for an approved order
+	StockMovementRelease  StockMovementType = "release"  // hold dropped when an order is cancelled
+	StockMovementDispense StockMovementType = "dispense" // taken from a batch when an order is paid
+	StockMovementAdjust   StockMovementType = "adjust"   // stock-count correction
+	StockMovementReturn   StockMovementType = "return"   // dispensed stock put back into a batch
+)
+
+// StockMovement is an append-only ledger entry. StockDelta changes the on-hand quantity
+// and ReservedDelta the quantity held for approved orders; summing the ledger for a
+// medicine gives its current stock and reserved quantity.
+type StockMovement struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	MedicineID    uuid.UUID         `gorm:"type:uuid;not null" json:"medicine_id"`
+	BatchID       *uuid.UUID        `gorm:"type:uuid" json:"batch_id,omitempty"`
+	OrderID       *uuid.UUID        `gorm:"type:uuid" json:"order_id,omitempty"`
+	Type          StockMovementType `gorm:"column:movement_type;type:stock_movement_type;not null" json:"movement_type"`
+	StockDelta    float64           `gorm:"type:numeric(12,2);not null;default:0" json:"stock_delta"`
+	ReservedDelta float64           `gorm:"type:numeric(12,2);not null;default:0" json:"reserved_delta"`
+	ActorID       *uuid.UUID        `gorm:"type:uuid" json:"actor_id,omitempty"`
+	ActorRole     string            `gorm:"type:text;not null" json:"actor_role"`
+	Reason        string            `gorm:"type:text;not null" json:"reason"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime:milli" json:"created_at"`
+	Batch         *MedicineBatch    `gorm:"foreignKey:BatchID;references:ID" json:"batch,omitempty"`
+}
+
+func (sm *StockMovement) TableName() string {
+	return "stock_movements"
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaxDocumentType string
+
+const (
+	// TaxDocumentTypeTaxInvoice is a full tax invoice/receipt issued to a buyer who gave
+	// their tax ID and address.
+	TaxDocumentTypeTaxInvoice TaxDocumentType = "tax_invoice"
+	// TaxDocumentTypeReceipt is an abbreviated tax invoice/receipt for retail buyers.
+	TaxDocumentTypeReceipt TaxDocumentType = "receipt"
+)
+
+var taxDocumentPrefixes = map[TaxDocumentType]string{
+	TaxDocumentTypeTaxInvoice: "INV",
+	TaxDocumentTypeReceipt:    "RC",
+}
+
+// FormatNumber renders a running number of the document type, e.g. "INV2026-000042".
+// Each document type is numbered separately and starts again every year.
+func (t TaxDocumentType) FormatNumber(year, sequence int) string {
+	return fmt.Sprintf("%s%d-%06d", taxDocumentPrefixes[t], year, sequence)
+}
+
+// TaxInvoice is the tax invoice or receipt issued when an order is paid. The seller and
+// buyer details and the amounts are copied in at issue so the document never changes
+// afterwards; the database rejects updates and deletes.
+type TaxInvoice struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	Number        string          `gorm:"type:text;not null" json:"number"`
+	DocumentType  TaxDocumentType `gorm:"type:tax_document_type;not null" json:"document_type"`
+	Year          int             `gorm:"type:int;not null" json:"year"`
+	Sequence      int             `gorm:"type:int;not null" json:"sequence"`
+	OrderID       uuid.UUID       `gorm:"type:uuid;not null" json:"order_id"`
+	PaymentID     uuid.UUID       `gorm:"type:uuid;not null" json:"payment_id"`
+	SellerName    string          `gorm:"type:text;not null" json:"seller_name"`
+	SellerTaxID   string          `gorm:"column:seller_tax_id;type:text;not null" json:"seller_tax_id"`
+	SellerBranch  string          `gorm:"type:text;not null" json:"seller_branch"`
+	SellerAddress string          `gorm:"type:text;not null" json:"seller_address"`
+	BuyerName     string          `gorm:"type:text;not null" json:"buyer_name"`
+	BuyerTaxID    *string         `gorm:"column:buyer_tax_id;type:text" json:"buyer_tax_id,omitempty"`
+	BuyerBranch   *string         `gorm:"type:text" json:"buyer_branch,omitempty"`
+	BuyerAddress  *string         `gorm:"type:text" json:"buyer_address,omitempty"`
+	// SubtotalAmount and DiscountAmount include VAT; VatableAmount and ExemptAmount are
+	// the net sales before VAT at the standard rate and exempt from it.
+	SubtotalAmount float64          `gorm:"type:numeric(12,2);not null" json:"subtotal_amount"`
+	DiscountAmount float64          `gorm:"type:numeric(12,2);not null" json:"discount_amount"`
+	VatableAmount  float64          `gorm:"type:numeric(12,2);not null" json:"vatable_amount"`
+	ExemptAmount   float64          `gorm:"type:numeric(12,2);not null" json:"exempt_amount"`
+	VATRate        float64          `gorm:"column:vat_rate;type:numeric(5,2);not null" json:"vat_rate"`
+	VATAmount      float64          `gorm:"column:vat_amount;type:numeric(12,2);not null" json:"vat_amount"`
+	TotalAmount    float64          `gorm:"type:numeric(12,2);not null" json:"total_amount"`
+	DeliveryFee    float64          `gorm:"type:numeric(12,2);not null;default:0" json:"delivery_fee"`
+	PaymentMethod  *PaymentMethod   `gorm:"type:payment_method" json:"payment_method,omitempty"`
+	Entitlement    *string          `gorm:"type:text" json:"entitlement,omitempty"`
+	InsurerAmount  float64          `gorm:"type:numeric(12,2);not null" json:"insurer_amount"`
+	PatientAmount  float64          `gorm:"type:numeric(12,2);not null" json:"patient_amount"`
+	IssuedAt       time.Time        `gorm:"not null" json:"issued_at"`
+	CreatedAt      time.Time        `gorm:"autoCreateTime:milli" json:"created_at"`
+	Lines          []TaxInvoiceLine `gorm:"foreignKey:TaxInvoiceID" json:"lines,omitempty"`
+}
+
+func (i *TaxInvoice) TableName() string {
+	return "tax_invoices"
+}
+
+// TaxInvoiceLine is one item of a tax invoice. Amount is the price after discount and
+// includes VAT.
+type TaxInvoiceLine struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	TaxInvoiceID   uuid.UUID    `gorm:"type:uuid;not null" json:"tax_invoice_id"`
+	LineNo         int          `gorm:"type:int;not null" json:"line_no"`
+	OrderItemID    uuid.UUID    `gorm:"type:uuid;not null" json:"order_item_id"`
+	Description    string       `gorm:"type:text;not null" json:"description"`
+	Quantity       float64      `gorm:"type:numeric(12,2);not null" json:"quantity"`
+	UnitLabel      string       `gorm:"type:text;not null" json:"unit_label"`
+	UnitPrice      float64      `gorm:"type:numeric(12,2);not null" json:"unit_price"`
+	DiscountAmount float64      `gorm:"type:numeric(12,2);not null" json:"discount_amount"`
+	Amount         float64      `gorm:"type:numeric(12,2);not null" json:"amount"`
+	VATTreatment   VATTreatment `gorm:"column:vat_treatment;type:vat_treatment;not null" json:"vat_treatment"`
+	VATAmount      float64      `gorm:"column:vat_amount;type:numeric(12,2);not null" json:"vat_amount"`
+}
+
+func (l *TaxInvoiceLine) TableName() string {
+	return "tax_invoice_lines"
+}
+
+// TaxInvoiceDocument is the PDF of a tax invoice, kept from the first time it is
+// rendered so later downloads are byte for byte the same.
+type TaxInvoiceDocument struct {
+	TaxInvoiceID uuid.UUID `gorm:"type:uuid;primaryKey" json:"tax_invoice_id"`
+	ContentType  string    `gorm:"type:text;not null" json:"content_type"`
+	Content      []byte    `gorm:"type:bytea;not null" json:"-"`
+	SHA256       string    `gorm:"column:sha256;type:text;not null" json:"sha256"`
+	CreatedAt    time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (d *TaxInvoiceDocument) TableName() string {
+	return "tax_invoice_documents"
+}
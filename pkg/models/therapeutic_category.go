@@ -0,0 +1,15 @@
+package models
+
+// TherapeuticCategory is a node of the WHO ATC classification, e.g. "N" (nervous system)
+// or "N02BE01" (paracetamol). The hierarchy follows from the code: every prefix of a
+// code at a level boundary is its ancestor.
+type TherapeuticCategory struct {
+	Code   string  `gorm:"type:text;primaryKey" json:"code"`
+	Name   string  `gorm:"type:text;not null" json:"name"`
+	NameTh *string `gorm:"type:text" json:"name_th,omitempty"`
+	Level  int     `gorm:"type:smallint;not null" json:"level"`
+}
+
+func (c *TherapeuticCategory) TableName() string {
+	return "therapeutic_categories"
+}
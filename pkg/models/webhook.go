@@ -0,0 +1,104 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventFilter lists the event types a webhook subscription receives. An entry is an
+// event type, a prefix ending in ".*" such as "order.*", or "*" for every event; an
+// empty filter receives every event. It is stored as a JSON array.
+type EventFilter []string
+
+func (f EventFilter) Matches(eventType string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, pattern := range f {
+		if pattern == "*" || pattern == eventType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasSuffix(prefix, ".") && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f EventFilter) Value() (driver.Value, error) {
+	if f == nil {
+		f = EventFilter{}
+	}
+	data, err := json.Marshal([]string(f))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (f *EventFilter) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*f = nil
+		return nil
+	default:
+		return errors.New("unsupported type for EventFilter")
+	}
+	return json.Unmarshal(data, (*[]string)(f))
+}
+
+// WebhookSubscription is a partner endpoint that is sent the events matching its filter.
+// Deliveries are signed with Secret. A subscription that keeps failing is disabled
+// until an admin enables it again.
+type WebhookSubscription struct {
+	ID                  uuid.UUID   `gorm:"type:uuid;primaryKey" json:"id"`
+	Name                string      `gorm:"type:text;not null" json:"name"`
+	URL                 string      `gorm:"type:text;not null" json:"url"`
+	Secret              string      `gorm:"type:text;not null" json:"-"`
+	EventTypes          EventFilter `gorm:"type:jsonb;not null" json:"event_types"`
+	Active              bool        `gorm:"not null;default:true" json:"active"`
+	ConsecutiveFailures int         `gorm:"not null;default:0" json:"consecutive_failures"`
+	DisabledAt          *time.Time  `json:"disabled_at,omitempty"`
+	DisabledReason      *string     `gorm:"type:text" json:"disabled_reason,omitempty"`
+	CreatedBy           *uuid.UUID  `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt           time.Time   `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt           time.Time   `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryStatusFailed marks a delivery that failed every attempt. An admin
+	// can still replay it.
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription, and the outcome of its latest
+// attempt. Body is the exact JSON posted, so replays send the same bytes.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	SubscriptionID uuid.UUID             `gorm:"type:uuid;not null" json:"subscription_id"`
+	EventID        uuid.UUID             `gorm:"type:uuid;not null" json:"event_id"`
+	EventType      string                `gorm:"type:text;not null" json:"event_type"`
+	Body           json.RawMessage       `gorm:"type:jsonb;not null" json:"body"`
+	Status         WebhookDeliveryStatus `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null" json:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty"`
+	LastError      *string               `gorm:"type:text" json:"last_error,omitempty"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime:milli" json:"created_at"`
+}
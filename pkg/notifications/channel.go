@@ -0,0 +1,27 @@
+// Package notifications tells patients and doctors about changes to their orders, by
+// email, SMS and LINE, in Thai or English.
+package notifications
+
+import (
+	"context"
+)
+
+// Channels messages can be sent through.
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+	ChannelLINE  = "line"
+)
+
+// Message is a rendered notification addressed to one recipient on one channel: an
+// email address, a phone number in E.164 form or a LINE user ID.
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Channel delivers messages. Channels without subjects, such as SMS, send only the body.
+type Channel interface {
+	Send(ctx context.Context, message Message) error
+}
@@ -0,0 +1,71 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const linePushURL = "https://api.line.me/v2/bot/message/push"
+
+// LINEChannel pushes text messages to users of a LINE Official Account through the
+// Messaging API. Recipients are LINE user IDs of people who have added the account as a
+// friend; the subject is sent as the first line.
+type LINEChannel struct {
+	url         string
+	accessToken string
+	hc          *http.Client
+}
+
+func NewLINEChannel(accessToken string) *LINEChannel {
+	return &LINEChannel{
+		url:         linePushURL,
+		accessToken: accessToken,
+		hc: &http.Client{
+			Timeout: httpChannelTimeout,
+		},
+	}
+}
+
+type linePushRequest struct {
+	To       string            `json:"to"`
+	Messages []lineTextMessage `json:"messages"`
+}
+
+type lineTextMessage struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (c *LINEChannel) Send(ctx context.Context, message Message) error {
+	text := message.Body
+	if message.Subject != "" {
+		text = message.Subject + "\n\n" + message.Body
+	}
+	body, err := json.Marshal(linePushRequest{
+		To:       message.To,
+		Messages: []lineTextMessage{{Type: "text", Text: text}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal LINE message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send LINE message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("LINE Messaging API returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
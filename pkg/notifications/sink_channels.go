@@ -0,0 +1,60 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogChannel writes messages to the standard logger instead of sending them, for local
+// development.
+type LogChannel struct {
+	name string
+}
+
+func NewLogChannel(name string) *LogChannel {
+	return &LogChannel{name: name}
+}
+
+func (c *LogChannel) Send(ctx context.Context, message Message) error {
+	log.Printf("%s to %s: %s\n%s", c.name, message.To, message.Subject, message.Body)
+	return nil
+}
+
+// FileChannel appends messages as JSON lines to a file instead of sending them, for
+// local development and end-to-end tests.
+type FileChannel struct {
+	mu   sync.Mutex
+	name string
+	path string
+}
+
+func NewFileChannel(name, path string) *FileChannel {
+	return &FileChannel{name: name, path: path}
+}
+
+type fileMessage struct {
+	Channel string    `json:"channel"`
+	SentAt  time.Time `json:"sent_at"`
+	Message
+}
+
+func (c *FileChannel) Send(ctx context.Context, message Message) error {
+	line, err := json.Marshal(fileMessage{Channel: c.name, SentAt: time.Now(), Message: message})
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	f, err := os.OpenFile(c.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notification sink: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,63 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const httpChannelTimeout = 10 * time.Second
+
+// SMSChannel sends text messages through an HTTP SMS gateway. Each message is POSTed as
+// {"to", "sender", "message"} with the API key as a bearer token; any 2xx response
+// counts as accepted.
+type SMSChannel struct {
+	url    string
+	apiKey string
+	sender string
+	hc     *http.Client
+}
+
+func NewSMSChannel(url, apiKey, sender string) *SMSChannel {
+	return &SMSChannel{
+		url:    url,
+		apiKey: apiKey,
+		sender: sender,
+		hc: &http.Client{
+			Timeout: httpChannelTimeout,
+		},
+	}
+}
+
+func (c *SMSChannel) Send(ctx context.Context, message Message) error {
+	body, err := json.Marshal(map[string]string{
+		"to":      message.To,
+		"sender":  c.sender,
+		"message": message.Body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal SMS: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS gateway returned status code: %d", resp.StatusCode)
+	}
+	return nil
+}
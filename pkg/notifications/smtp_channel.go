@@ -0,0 +1,48 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPChannel sends messages as plain-text UTF-8 email.
+type SMTPChannel struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPChannel sends through host:port, authenticating with PLAIN auth when a
+// username is set. net/smtp upgrades to TLS when the server offers STARTTLS.
+func NewSMTPChannel(host string, port int, username, password, from string) *SMTPChannel {
+	c := &SMTPChannel{
+		addr: net.JoinHostPort(host, fmt.Sprint(port)),
+		from: from,
+	}
+	if username != "" {
+		c.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return c
+}
+
+func (c *SMTPChannel) Send(ctx context.Context, message Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", c.from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(c.addr, c.auth, c.from, []string{message.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
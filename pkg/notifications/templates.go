@@ -0,0 +1,183 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type Language string
+
+const (
+	LanguageThai    Language = "th"
+	LanguageEnglish Language = "en"
+)
+
+// Messages that can be rendered; each has a Thai and an English template.
+const (
+	MessageOrderPendingReview = "order.pending_review"
+	MessageOrderApproved      = "order.approved"
+	MessageOrderRejected      = "order.rejected"
+	MessageOrderPaid          = "order.paid"
+	MessageOrderDispatched    = "order.dispatched"
+	MessageOrderDelivered     = "order.delivered"
+	// MessageOrderChangesRequested goes to the patient when the doctor sends an order
+	// back, MessageOrderResubmitted to the doctor when the patient sends it again.
+	MessageOrderChangesRequested = "order.changes_requested"
+	MessageOrderResubmitted      = "order.resubmitted"
+	MessageOrderExpired          = "order.expired"
+)
+
+// TemplateData is what templates can refer to.
+type TemplateData struct {
+	// short order reference shown to people, the start of the order ID
+	OrderRef string
+	// amount the patient pays, delivery fee included, formatted with two decimals
+	Amount string
+	Reason string
+	// ReasonLabel names the reason code the doctor picked, in the message's language
+	ReasonLabel string
+	// PreviousStatus is the status the order was in before the change
+	PreviousStatus string
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var messageTemplates = map[string]map[Language]messageTemplate{
+	MessageOrderPendingReview: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} รอการตรวจสอบ",
+			"มีคำสั่งยาใหม่ #{{.OrderRef}} รอให้คุณตรวจสอบและอนุมัติ"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} is waiting for your review",
+			"A new medication order #{{.OrderRef}} is waiting for you to review and approve."),
+	},
+	MessageOrderApproved: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} ได้รับการอนุมัติแล้ว",
+			"แพทย์อนุมัติคำสั่งยา #{{.OrderRef}} แล้ว ยอดที่ต้องชำระ {{.Amount}} บาท กรุณาชำระเงินเพื่อให้เราจัดเตรียมยาให้คุณ"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} has been approved",
+			"Your doctor approved order #{{.OrderRef}}. Please pay {{.Amount}} THB so we can prepare your medication."),
+	},
+	MessageOrderRejected: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} ไม่ได้รับการอนุมัติ",
+			"แพทย์ไม่อนุมัติคำสั่งยา #{{.OrderRef}}{{if .ReasonLabel}} เหตุผล: {{.ReasonLabel}}{{end}}{{if .Reason}} หมายเหตุจากแพทย์: {{.Reason}}{{end}}"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} was not approved",
+			"Your doctor did not approve order #{{.OrderRef}}.{{if .ReasonLabel}} Reason: {{.ReasonLabel}}.{{end}}{{if .Reason}} Doctor's note: {{.Reason}}{{end}}"),
+	},
+	MessageOrderChangesRequested: {
+		LanguageThai: parseTemplate(
+			"แพทย์ขอให้แก้ไขคำสั่งยา #{{.OrderRef}}",
+			"แพทย์ขอให้คุณแก้ไขคำสั่งยา #{{.OrderRef}} ก่อนอนุมัติ กรุณาตอบกลับและส่งคำสั่งยาอีกครั้ง ความเห็นจากแพทย์: {{.Reason}}"),
+		LanguageEnglish: parseTemplate(
+			"Your doctor asked for changes to order #{{.OrderRef}}",
+			"Your doctor asked for changes to order #{{.OrderRef}} before approving it. Please reply and resubmit the order. Doctor's comment: {{.Reason}}"),
+	},
+	MessageOrderResubmitted: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} ถูกส่งกลับมาให้ตรวจสอบ",
+			"ผู้ป่วยแก้ไขและส่งคำสั่งยา #{{.OrderRef}} กลับมาให้คุณตรวจสอบอีกครั้ง{{if .Reason}} ข้อความจากผู้ป่วย: {{.Reason}}{{end}}"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} was resubmitted for your review",
+			"The patient updated order #{{.OrderRef}} and sent it back for you to review.{{if .Reason}} Their reply: {{.Reason}}{{end}}"),
+	},
+	MessageOrderExpired: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} หมดอายุแล้ว",
+			"คำสั่งยา #{{.OrderRef}} หมดอายุแล้ว{{if eq .PreviousStatus \"approved\"}} เนื่องจากไม่ได้ชำระเงินภายในเวลาที่กำหนด{{else if eq .PreviousStatus \"changes_requested\"}} เนื่องจากไม่ได้แก้ไขและส่งกลับภายในเวลาที่กำหนด{{else}} เนื่องจากแพทย์ไม่ได้ตรวจสอบภายในเวลาที่กำหนด{{end}} หากยังต้องการยา กรุณาสั่งใหม่อีกครั้ง"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} has expired",
+			"Order #{{.OrderRef}} has expired{{if eq .PreviousStatus \"approved\"}} because it was not paid in time{{else if eq .PreviousStatus \"changes_requested\"}} because it was not updated and resubmitted in time{{else}} because no doctor reviewed it in time{{end}}. If you still need the medication, please place a new order."),
+	},
+	MessageOrderPaid: {
+		LanguageThai: parseTemplate(
+			"ได้รับชำระเงินสำหรับคำสั่งยา #{{.OrderRef}} แล้ว",
+			"เราได้รับชำระเงิน {{.Amount}} บาท สำหรับคำสั่งยา #{{.OrderRef}} แล้ว และกำลังจัดเตรียมยาให้คุณ"),
+		LanguageEnglish: parseTemplate(
+			"Payment received for order #{{.OrderRef}}",
+			"We received your payment of {{.Amount}} THB for order #{{.OrderRef}} and are preparing your medication."),
+	},
+	MessageOrderDispatched: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} ถูกจัดส่งแล้ว",
+			"คำสั่งยา #{{.OrderRef}} ออกจากร้านยาแล้วและกำลังจัดส่งถึงคุณ"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} has been dispatched",
+			"Order #{{.OrderRef}} has left the pharmacy and is on its way to you."),
+	},
+	MessageOrderDelivered: {
+		LanguageThai: parseTemplate(
+			"คำสั่งยา #{{.OrderRef}} จัดส่งถึงแล้ว",
+			"คำสั่งยา #{{.OrderRef}} จัดส่งถึงคุณเรียบร้อยแล้ว ขอบคุณที่ใช้บริการ"),
+		LanguageEnglish: parseTemplate(
+			"Order #{{.OrderRef}} has been delivered",
+			"Order #{{.OrderRef}} has been delivered. Thank you for using our pharmacy."),
+	},
+}
+
+// reasonLabels names the rejection reason codes; other has no label, the doctor's note
+// says it all.
+var reasonLabels = map[Language]map[string]string{
+	LanguageThai: {
+		"not_indicated":            "ยาไม่เหมาะกับอาการของคุณ",
+		"contraindicated":          "มีข้อห้ามใช้ยานี้กับคุณ",
+		"allergy":                  "คุณมีประวัติแพ้ยานี้",
+		"drug_interaction":         "ยานี้อาจเกิดปฏิกิริยากับยาที่คุณใช้อยู่",
+		"duplicate_therapy":        "ยานี้ซ้ำซ้อนกับยาที่คุณได้รับอยู่แล้ว",
+		"consultation_required":    "ต้องพบแพทย์เพื่อประเมินอาการก่อน",
+		"insufficient_information": "ข้อมูลไม่เพียงพอสำหรับการอนุมัติ",
+	},
+	LanguageEnglish: {
+		"not_indicated":            "the medication is not indicated for your condition",
+		"contraindicated":          "the medication is contraindicated for you",
+		"allergy":                  "you have a recorded allergy to the medication",
+		"drug_interaction":         "the medication interacts with medication you take",
+		"duplicate_therapy":        "the medication duplicates medication you already take",
+		"consultation_required":    "you need a consultation before it can be prescribed",
+		"insufficient_information": "there was not enough information to approve it",
+	},
+}
+
+// ReasonLabel returns the label of a rejection reason code in the given language, falling
+// back to Thai, or "" for codes without one.
+func ReasonLabel(code string, language Language) string {
+	labels, ok := reasonLabels[language]
+	if !ok {
+		labels = reasonLabels[LanguageThai]
+	}
+	return labels[code]
+}
+
+func parseTemplate(subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// Render returns the subject and body of a message in the given language, falling back
+// to Thai for languages without a template.
+func Render(name string, language Language, data TemplateData) (string, string, error) {
+	byLanguage, ok := messageTemplates[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown message %q", name)
+	}
+	tmpl, ok := byLanguage[language]
+	if !ok {
+		tmpl = byLanguage[LanguageThai]
+	}
+	var subject, body strings.Builder
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s body: %w", name, err)
+	}
+	return subject.String(), body.String(), nil
+}
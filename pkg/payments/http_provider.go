@@ -0,0 +1,63 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const httpProviderTimeout = 30 * time.Second
+
+// HTTPProvider calls a payment gateway's REST API. Refunds are posted as JSON to
+// <baseURL>/refunds with the refund ID as the idempotency key.
+type HTTPProvider struct {
+	baseURL string
+	apiKey  string
+	hc      *http.Client
+}
+
+func NewHTTPProvider(baseURL, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		hc: &http.Client{
+			Timeout: httpProviderTimeout,
+		},
+	}
+}
+
+func (p *HTTPProvider) Refund(ctx context.Context, req RefundRequest) (*RefundResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal refund: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/refunds", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Idempotency-Key", req.RefundID)
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.hc.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send refund: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("payment provider returned status code: %d", resp.StatusCode)
+	}
+	var result RefundResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode refund response: %w", err)
+	}
+	return &result, nil
+}
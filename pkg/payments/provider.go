@@ -0,0 +1,25 @@
+// Package payments talks to the payment provider that takes patients' payments.
+package payments
+
+import "context"
+
+// RefundRequest asks the provider to give back part or all of a captured payment.
+// RefundID is unique per refund so a retried request is not paid out twice.
+type RefundRequest struct {
+	RefundID  string  `json:"refund_id"`
+	PaymentID string  `json:"payment_id"`
+	OrderID   string  `json:"order_id"`
+	Method    string  `json:"method,omitempty"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+}
+
+// RefundResult is the provider's record of a refund it has made.
+type RefundResult struct {
+	Reference string `json:"reference"`
+}
+
+// Provider reverses charges made through the payment provider.
+type Provider interface {
+	Refund(ctx context.Context, req RefundRequest) (*RefundResult, error)
+}
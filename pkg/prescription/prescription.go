@@ -0,0 +1,96 @@
+package prescription
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"order-service/pkg/models"
+)
+
+// RequiredQuantity returns the number of units a regimen consumes over its full duration.
+func RequiredQuantity(dose, frequencyPerDay float64, durationDays int) float64 {
+	return dose * frequencyPerDay * float64(durationDays)
+}
+
+// ValidateQuantity checks that the ordered quantity covers the full regimen.
+func ValidateQuantity(quantity, dose, frequencyPerDay float64, durationDays int) error {
+	required := RequiredQuantity(dose, frequencyPerDay, durationDays)
+	// quantities are stored with two decimals
+	if quantity+0.005 < required {
+		return fmt.Errorf("quantity %g does not cover %g x %g a day for %d days (%g needed)", quantity, dose, frequencyPerDay, durationDays, math.Ceil(required*100)/100)
+	}
+	return nil
+}
+
+// FormatInstructions renders the regimen of an order item as a sentence, e.g.
+// "1 tablet 3 times a day after meals for 5 days (oral)". Items without a regimen
+// render only their free-text instructions.
+func FormatInstructions(item *models.OrderItem, unit string) string {
+	parts := []string{}
+	if item.Dose != nil {
+		parts = append(parts, fmt.Sprintf("%g %s", *item.Dose, unit))
+	}
+	if item.FrequencyPerDay != nil {
+		parts = append(parts, formatFrequency(*item.FrequencyPerDay))
+	}
+	if item.Instructions != nil && strings.TrimSpace(*item.Instructions) != "" {
+		parts = append(parts, strings.TrimSpace(*item.Instructions))
+	}
+	if item.DurationDays != nil {
+		if *item.DurationDays == 1 {
+			parts = append(parts, "for 1 day")
+		} else {
+			parts = append(parts, fmt.Sprintf("for %d days", *item.DurationDays))
+		}
+	}
+	if item.Route != nil && *item.Route != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", *item.Route))
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatFrequency(perDay float64) string {
+	switch perDay {
+	case 1:
+		return "once a day"
+	case 2:
+		return "twice a day"
+	}
+	if perDay < 1 {
+		return fmt.Sprintf("every %g days", math.Round(1/perDay*10)/10)
+	}
+	return fmt.Sprintf("%g times a day", perDay)
+}
+
+// RenderLabels renders one printable plain-text label per order item.
+func RenderLabels(order *models.Order, patientName string, printedAt time.Time) string {
+	var b strings.Builder
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		medicineName, unit := item.MedicineID.String(), ""
+		if item.Medicine != nil {
+			medicineName, unit = item.Medicine.Name, item.Medicine.Unit
+		}
+
+		b.WriteString("========================================\n")
+		fmt.Fprintf(&b, "Patient: %s\n", patientName)
+		fmt.Fprintf(&b, "Order:   %s\n", order.ID)
+		fmt.Fprintf(&b, "Date:    %s\n", printedAt.Format("2006-01-02"))
+		b.WriteString("----------------------------------------\n")
+		fmt.Fprintf(&b, "%s\n", medicineName)
+		quantityUnit := unit
+		if item.Unit != nil {
+			quantityUnit = item.Unit.Label(unit)
+		}
+		fmt.Fprintf(&b, "Qty: %g %s\n", item.Quantity, quantityUnit)
+		if instructions := FormatInstructions(item, unit); instructions != "" {
+			fmt.Fprintf(&b, "%s\n", instructions)
+		}
+	}
+	if len(order.OrderItems) > 0 {
+		b.WriteString("========================================\n")
+	}
+	return b.String()
+}
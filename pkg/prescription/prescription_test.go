@@ -0,0 +1,44 @@
+package prescription
+
+import "testing"
+
+func TestValidateQuantity(t *testing.T) {
+	tests := []struct {
+		name            string
+		quantity        float64
+		dose            float64
+		frequencyPerDay float64
+		durationDays    int
+		wantErr         bool
+	}{
+		{"exact course", 15, 1, 3, 5, false},
+		{"more than the course", 20, 1, 3, 5, false},
+		{"one unit short", 14, 1, 3, 5, true},
+		{"half tablets", 5, 0.5, 2, 5, false},
+		{"half tablets short", 4.5, 0.5, 2, 5, true},
+		{"every other day", 4, 1, 0.5, 7, false},
+		{"every other day short", 3, 1, 0.5, 7, true},
+		// 0.1 x 3 x 7 is slightly above 2.1 in floating point
+		{"floating point noise", 2.1, 0.1, 3, 7, false},
+		{"short by a hundredth", 2.09, 0.1, 3, 7, true},
+		{"liquid dose in ml", 150, 7.5, 2, 10, false},
+		{"no duration", 0, 1, 3, 0, false},
+	}
+	for _, tt := range tests {
+		err := ValidateQuantity(tt.quantity, tt.dose, tt.frequencyPerDay, tt.durationDays)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateQuantity(%g, %g, %g, %d) = %v, want error %t", tt.name, tt.quantity, tt.dose, tt.frequencyPerDay, tt.durationDays, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateQuantityMessage(t *testing.T) {
+	err := ValidateQuantity(10, 1, 3, 5)
+	if err == nil {
+		t.Fatal("ValidateQuantity accepted 10 units for 15 needed")
+	}
+	want := "quantity 10 does not cover 1 x 3 a day for 5 days (15 needed)"
+	if err.Error() != want {
+		t.Errorf("message = %q, want %q", err.Error(), want)
+	}
+}
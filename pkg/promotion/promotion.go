@@ -0,0 +1,154 @@
+// Package promotion works out the discounts promotions give on an order.
+package promotion
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Line is the discount one promotion gives on one order item.
+type Line struct {
+	OrderItemID uuid.UUID
+	Promotion   *models.Promotion
+	Amount      float64
+}
+
+// Result is the breakdown of an order's total after discounts.
+type Result struct {
+	Subtotal float64
+	Discount float64
+	Total    float64
+	Lines    []Line
+}
+
+// ItemDiscounts sums the discount lines per order item.
+func (r *Result) ItemDiscounts() map[uuid.UUID]float64 {
+	discounts := make(map[uuid.UUID]float64)
+	for _, line := range r.Lines {
+		discounts[line.OrderItemID] += line.Amount
+	}
+	return discounts
+}
+
+// Apply works out the discounts the promotions give on the items at the given time.
+// Promotions that are not running, do not reach their minimum order amount or do not
+// match any item are skipped. Stackable promotions are combined; a promotion that is
+// not stackable is only used on its own, and whichever option saves the patient more
+// wins. Medicine discounts are taken before order discounts, which are spread over the
+// items in proportion to what is left of their price.
+func Apply(items []models.OrderItem, promotions []models.Promotion, at time.Time) Result {
+	var subtotal float64
+	for i := range items {
+		subtotal += round(items[i].LineTotal())
+	}
+	subtotal = round(subtotal)
+
+	var stackable []*models.Promotion
+	var exclusive []*models.Promotion
+	for i := range promotions {
+		promotion := &promotions[i]
+		if !promotion.IsRunningAt(at) {
+			continue
+		}
+		if promotion.MinOrderAmount != nil && subtotal < *promotion.MinOrderAmount {
+			continue
+		}
+		if promotion.Stackable {
+			stackable = append(stackable, promotion)
+		} else {
+			exclusive = append(exclusive, promotion)
+		}
+	}
+
+	best := discount(items, stackable)
+	for _, promotion := range exclusive {
+		if lines := discount(items, []*models.Promotion{promotion}); total(lines) > total(best) {
+			best = lines
+		}
+	}
+
+	result := Result{Subtotal: subtotal, Lines: best}
+	result.Discount = round(total(best))
+	result.Total = round(subtotal - result.Discount)
+	return result
+}
+
+func discount(items []models.OrderItem, promotions []*models.Promotion) []Line {
+	// medicine discounts first, then order discounts on what remains
+	sorted := make([]*models.Promotion, len(promotions))
+	copy(sorted, promotions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Scope == models.PromotionScopeMedicine && sorted[j].Scope != models.PromotionScopeMedicine
+	})
+
+	remaining := make([]float64, len(items))
+	for i := range items {
+		remaining[i] = round(items[i].LineTotal())
+	}
+
+	var lines []Line
+	for _, promotion := range sorted {
+		// the items the promotion applies to and what is left of their price
+		var base float64
+		var matched []int
+		for i := range items {
+			if promotion.Scope == models.PromotionScopeMedicine && (promotion.MedicineID == nil || *promotion.MedicineID != items[i].MedicineID) {
+				continue
+			}
+			if remaining[i] <= 0 {
+				continue
+			}
+			matched = append(matched, i)
+			base += remaining[i]
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		amount := promotion.Value
+		if promotion.Kind == models.PromotionKindPercentage {
+			amount = base * promotion.Value / 100
+		}
+		if promotion.MaxDiscount != nil {
+			amount = math.Min(amount, *promotion.MaxDiscount)
+		}
+		amount = round(math.Min(amount, base))
+		if amount <= 0 {
+			continue
+		}
+
+		// spread the amount in proportion to price; the last item takes the rounding
+		left := amount
+		for n, i := range matched {
+			share := round(amount * remaining[i] / base)
+			if n == len(matched)-1 {
+				share = round(left)
+			}
+			share = math.Min(share, remaining[i])
+			if share <= 0 {
+				continue
+			}
+			remaining[i] = round(remaining[i] - share)
+			left = round(left - share)
+			lines = append(lines, Line{OrderItemID: items[i].ID, Promotion: promotion, Amount: share})
+		}
+	}
+	return lines
+}
+
+func total(lines []Line) float64 {
+	var sum float64
+	for _, line := range lines {
+		sum += line.Amount
+	}
+	return sum
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
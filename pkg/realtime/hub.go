@@ -0,0 +1,92 @@
+// Package realtime fans events out to the users connected to this replica, for the
+// order status stream.
+package realtime
+
+import (
+	"sync"
+
+	"order-service/pkg/events"
+)
+
+// subscriptionBuffer is how many events a slow connection may fall behind before it is
+// dropped. Clients reconnect with Last-Event-ID and catch up from the database.
+const subscriptionBuffer = 64
+
+// Subscription receives the events addressed to one user until it is closed. C is
+// closed when the subscription ends, including when the hub drops a connection that
+// fell behind.
+type Subscription struct {
+	C      <-chan events.Event
+	c      chan events.Event
+	userID string
+	hub    *Hub
+	once   sync.Once
+}
+
+// Close ends the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[string]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs: make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe returns a subscription to the events addressed to userID.
+func (h *Hub) Subscribe(userID string) *Subscription {
+	c := make(chan events.Event, subscriptionBuffer)
+	sub := &Subscription{C: c, c: c, userID: userID, hub: h}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Publish sends the event to every subscription of the given users. It never blocks; a
+// subscription whose buffer is full is dropped.
+func (h *Hub) Publish(userIDs []string, event events.Event) {
+	var dropped []*Subscription
+	h.mu.RLock()
+	for _, userID := range userIDs {
+		for sub := range h.subs[userID] {
+			select {
+			case sub.c <- event:
+			default:
+				dropped = append(dropped, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+	for _, sub := range dropped {
+		h.remove(sub)
+	}
+}
+
+// Empty reports whether nobody is subscribed.
+func (h *Hub) Empty() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs) == 0
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		h.mu.Lock()
+		delete(h.subs[sub.userID], sub)
+		if len(h.subs[sub.userID]) == 0 {
+			delete(h.subs, sub.userID)
+		}
+		h.mu.Unlock()
+		close(sub.c)
+	})
+}
@@ -0,0 +1,257 @@
+// Package receipt renders issued tax invoices as PDF receipts. Text is set in a TrueType
+// font loaded from disk so Thai names and addresses print correctly.
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/pkg/models"
+	"order-service/pkg/tax"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ContentType is the media type of rendered receipts.
+const ContentType = "application/pdf"
+
+const fontFamily = "receipt"
+
+// page size and margin of A4 pages in millimetres
+const (
+	pageHeight = 297.0
+	margin     = 15.0
+)
+
+// bangkok is Thai time; Thailand has no daylight saving so a fixed zone always matches.
+var bangkok = time.FixedZone("ICT", 7*60*60)
+
+var documentTitles = map[models.TaxDocumentType]string{
+	models.TaxDocumentTypeTaxInvoice: "ใบเสร็จรับเงิน/ใบกำกับภาษี  Receipt / Tax Invoice",
+	models.TaxDocumentTypeReceipt:    "ใบเสร็จรับเงิน/ใบกำกับภาษีอย่างย่อ  Receipt / Abbreviated Tax Invoice",
+}
+
+var paymentMethodLabels = map[models.PaymentMethod]string{
+	models.PaymentMethodCash:         "เงินสด Cash",
+	models.PaymentMethodCard:         "บัตร Card",
+	models.PaymentMethodPromptPay:    "พร้อมเพย์ PromptPay",
+	models.PaymentMethodBankTransfer: "โอนเงิน Bank transfer",
+}
+
+// Fonts holds the TrueType files receipts are set in.
+type Fonts struct {
+	Regular []byte
+	Bold    []byte
+}
+
+// LoadFonts reads the regular and bold TrueType fonts. The regular font is also used
+// for bold text when boldPath is empty.
+func LoadFonts(regularPath, boldPath string) (Fonts, error) {
+	regular, err := os.ReadFile(regularPath)
+	if err != nil {
+		return Fonts{}, fmt.Errorf("read receipt font: %w", err)
+	}
+	fonts := Fonts{Regular: regular, Bold: regular}
+	if boldPath != "" {
+		if fonts.Bold, err = os.ReadFile(boldPath); err != nil {
+			return Fonts{}, fmt.Errorf("read receipt bold font: %w", err)
+		}
+	}
+	return fonts, nil
+}
+
+// Renderer turns tax invoices into PDF documents.
+type Renderer struct {
+	fonts Fonts
+}
+
+// NewRenderer returns a Renderer that sets receipts in the given fonts.
+func NewRenderer(fonts Fonts) *Renderer {
+	return &Renderer{fonts: fonts}
+}
+
+// column is one column of the item table.
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+var itemColumns = []column{
+	{"#", 8, "C"},
+	{"รายการ Description", 64, "L"},
+	{"จำนวน Qty", 18, "R"},
+	{"หน่วย Unit", 22, "L"},
+	{"ราคา/หน่วย Price", 24, "R"},
+	{"ส่วนลด Discount", 20, "R"},
+	{"จำนวนเงิน Amount", 24, "R"},
+}
+
+// Render lays out the invoice on A4 pages. The output only depends on the invoice, so
+// rendering the same invoice again gives the same bytes.
+func (r *Renderer) Render(invoice *models.TaxInvoice) ([]byte, error) {
+	issuedAt := invoice.IssuedAt.In(bangkok)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(issuedAt)
+	pdf.SetModificationDate(issuedAt)
+	pdf.SetTitle(invoice.Number, true)
+	pdf.SetAuthor(invoice.SellerName, true)
+	pdf.AddUTF8FontFromBytes(fontFamily, "", r.fonts.Regular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", r.fonts.Bold)
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.AddPage()
+
+	// seller
+	pdf.SetFont(fontFamily, "B", 14)
+	pdf.MultiCell(0, 7, invoice.SellerName, "", "L", false)
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.MultiCell(0, 5, invoice.SellerAddress, "", "L", false)
+	pdf.CellFormat(0, 5, "เลขประจำตัวผู้เสียภาษี Tax ID: "+invoice.SellerTaxID+"  "+branchLabel(invoice.SellerBranch), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// document
+	pdf.SetFont(fontFamily, "B", 13)
+	pdf.CellFormat(0, 7, documentTitles[invoice.DocumentType], "", 1, "C", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(90, 5, "เลขที่ No.: "+invoice.Number, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 5, "วันที่ Date: "+issuedAt.Format("02/01/2006 15:04"), "", 1, "R", false, 0, "")
+	pdf.CellFormat(0, 5, "คำสั่งซื้อ Order: "+invoice.OrderID.String(), "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	// buyer
+	pdf.CellFormat(0, 5, "ลูกค้า Customer: "+invoice.BuyerName, "", 1, "L", false, 0, "")
+	if invoice.BuyerTaxID != nil {
+		line := "เลขประจำตัวผู้เสียภาษี Tax ID: " + *invoice.BuyerTaxID
+		if invoice.BuyerBranch != nil {
+			line += "  " + branchLabel(*invoice.BuyerBranch)
+		}
+		pdf.CellFormat(0, 5, line, "", 1, "L", false, 0, "")
+	}
+	if invoice.BuyerAddress != nil {
+		pdf.MultiCell(0, 5, "ที่อยู่ Address: "+*invoice.BuyerAddress, "", "L", false)
+	}
+	pdf.Ln(3)
+
+	// items
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetFillColor(235, 235, 235)
+	for _, col := range itemColumns {
+		pdf.CellFormat(col.width, 7, col.title, "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 9)
+	for _, line := range invoice.Lines {
+		description := line.Description
+		if line.VATTreatment == models.VATTreatmentExempt {
+			description += " (ยกเว้น VAT exempt)"
+		}
+		cells := []string{
+			strconv.Itoa(line.LineNo),
+			description,
+			strconv.FormatFloat(line.Quantity, 'f', -1, 64),
+			line.UnitLabel,
+			formatAmount(line.UnitPrice),
+			formatAmount(line.DiscountAmount),
+			formatAmount(line.Amount),
+		}
+		// the description may wrap; the other cells are as tall as it
+		lineCount := len(pdf.SplitText(description, itemColumns[1].width-2))
+		if lineCount < 1 {
+			lineCount = 1
+		}
+		height := 6 * float64(lineCount)
+		if pdf.GetY()+height > pageHeight-margin {
+			pdf.AddPage()
+		}
+		x, y := pdf.GetX(), pdf.GetY()
+		for i, col := range itemColumns {
+			if i == 1 {
+				pdf.MultiCell(col.width, 6, cells[i], "1", col.align, false)
+				pdf.SetXY(x+col.width, y)
+			} else {
+				pdf.CellFormat(col.width, height, cells[i], "1", 0, col.align, false, 0, "")
+			}
+			x = pdf.GetX()
+		}
+		pdf.SetXY(margin, y+height)
+	}
+	pdf.Ln(3)
+
+	// totals
+	totals := [][2]string{
+		{"รวมเป็นเงิน Subtotal", formatAmount(invoice.SubtotalAmount)},
+		{"ส่วนลด Discount", formatAmount(invoice.DiscountAmount)},
+		{"ค่าจัดส่ง Delivery fee", formatAmount(invoice.DeliveryFee)},
+		{"ยอดรวมทั้งสิ้น Grand total", formatAmount(invoice.TotalAmount + invoice.DeliveryFee)},
+		{"มูลค่าสินค้าที่เสียภาษี Vatable amount", formatAmount(invoice.VatableAmount)},
+		{"มูลค่าสินค้าที่ได้รับยกเว้น Exempt amount", formatAmount(invoice.ExemptAmount)},
+		{"ภาษีมูลค่าเพิ่ม VAT " + strconv.FormatFloat(invoice.VATRate, 'f', -1, 64) + "%", formatAmount(invoice.VATAmount)},
+	}
+	if invoice.InsurerAmount > 0 {
+		entitlement := ""
+		if invoice.Entitlement != nil {
+			entitlement = " (" + *invoice.Entitlement + ")"
+		}
+		totals = append(totals, [2]string{"สิทธิการรักษา Covered by entitlement" + entitlement, formatAmount(invoice.InsurerAmount)})
+	}
+	totals = append(totals, [2]string{"ชำระโดยผู้ป่วย Paid by patient", formatAmount(invoice.PatientAmount + invoice.DeliveryFee)})
+	for i, total := range totals {
+		style := ""
+		if i == 3 || i == len(totals)-1 {
+			style = "B"
+		}
+		pdf.SetFont(fontFamily, style, 10)
+		pdf.CellFormat(140, 6, total[0], "", 0, "R", false, 0, "")
+		pdf.CellFormat(0, 6, total[1], "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	pdf.SetFont(fontFamily, "", 10)
+	method := "-"
+	if invoice.PaymentMethod != nil {
+		method = paymentMethodLabels[*invoice.PaymentMethod]
+	}
+	pdf.CellFormat(0, 5, "ชำระโดย Payment method: "+method, "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "", 8)
+	pdf.CellFormat(0, 5, "ราคาสินค้ารวมภาษีมูลค่าเพิ่มแล้ว  Prices include VAT.", "", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("render receipt %s: %w", invoice.Number, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// branchLabel names a branch the way the Revenue Department asks for on tax invoices.
+func branchLabel(branch string) string {
+	if branch == "" || branch == tax.HeadOfficeBranch {
+		return "สำนักงานใหญ่ Head office"
+	}
+	return "สาขาที่ Branch " + branch
+}
+
+// formatAmount renders money with two decimals and thousands separators, e.g. "1,234.50".
+func formatAmount(amount float64) string {
+	s := strconv.FormatFloat(amount, 'f', 2, 64)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, fraction := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if negative {
+		return "-" + b.String() + fraction
+	}
+	return b.String() + fraction
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CancellationRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewCancellationRuleRepository(db *gorm.DB) *CancellationRuleRepository {
+	return &CancellationRuleRepository{
+		db: db,
+	}
+}
+
+func (r *CancellationRuleRepository) Transaction(ctx context.Context, fn func(repo *CancellationRuleRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *CancellationRuleRepository) withTx(tx *gorm.DB) *CancellationRuleRepository {
+	return &CancellationRuleRepository{db: tx}
+}
+
+func (r *CancellationRuleRepository) FindAll(ctx context.Context) ([]models.CancellationRule, error) {
+	var rules []models.CancellationRule
+	if err := r.db.WithContext(ctx).Order("status ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *CancellationRuleRepository) FindByStatus(ctx context.Context, status models.OrderStatus) (*models.CancellationRule, error) {
+	var rule models.CancellationRule
+	if err := r.db.WithContext(ctx).Where("status = ?", status).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Save creates the rule for its status or replaces the one already set.
+func (r *CancellationRuleRepository) Save(ctx context.Context, rule *models.CancellationRule) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "status"}},
+		DoUpdates: clause.AssignmentColumns([]string{"window_minutes", "fee_percent", "fee_amount", "updated_at"}),
+	}).Create(rule).Error
+}
+
+func (r *CancellationRuleRepository) Delete(ctx context.Context, status models.OrderStatus) error {
+	return r.db.WithContext(ctx).Where("status = ?", status).Delete(&models.CancellationRule{}).Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CoverageRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewCoverageRuleRepository(db *gorm.DB) *CoverageRuleRepository {
+	return &CoverageRuleRepository{
+		db: db,
+	}
+}
+
+func (r *CoverageRuleRepository) Transaction(ctx context.Context, fn func(repo *CoverageRuleRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *CoverageRuleRepository) withTx(tx *gorm.DB) *CoverageRuleRepository {
+	return &CoverageRuleRepository{db: tx}
+}
+
+func (r *CoverageRuleRepository) Create(ctx context.Context, rule *models.CoverageRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *CoverageRuleRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.CoverageRule, error) {
+	var rule models.CoverageRule
+	if err := r.db.WithContext(ctx).Preload("Medicine").Where("id = ?", id).First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindAll returns the rules of one entitlement, or of every entitlement when it is empty.
+// Default rules come before medicine rules.
+func (r *CoverageRuleRepository) FindAll(ctx context.Context, entitlement string) ([]models.CoverageRule, error) {
+	var rules []models.CoverageRule
+	query := r.db.WithContext(ctx).Preload("Medicine")
+	if entitlement != "" {
+		query = query.Where("entitlement = ?", entitlement)
+	}
+	if err := query.Order("entitlement ASC, medicine_id ASC NULLS FIRST").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *CoverageRuleRepository) FindByEntitlements(ctx context.Context, entitlements []string) ([]models.CoverageRule, error) {
+	var rules []models.CoverageRule
+	if len(entitlements) == 0 {
+		return rules, nil
+	}
+	if err := r.db.WithContext(ctx).Where("entitlement IN ?", entitlements).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindByEntitlementAndMedicine returns the entitlement's rule for the medicine, or its
+// default rule when medicineID is nil.
+func (r *CoverageRuleRepository) FindByEntitlementAndMedicine(ctx context.Context, entitlement string, medicineID *uuid.UUID) (*models.CoverageRule, error) {
+	var rule models.CoverageRule
+	query := r.db.WithContext(ctx).Where("entitlement = ?", entitlement)
+	if medicineID == nil {
+		query = query.Where("medicine_id IS NULL")
+	} else {
+		query = query.Where("medicine_id = ?", *medicineID)
+	}
+	if err := query.First(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *CoverageRuleRepository) Update(ctx context.Context, rule *models.CoverageRule) error {
+	return r.db.WithContext(ctx).Model(rule).
+		Select("covered_percent", "copay", "excluded", "annual_cap", "updated_at").
+		Updates(rule).Error
+}
+
+func (r *CoverageRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.CoverageRule{}).Error
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MedicineBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicineBatchRepository(db *gorm.DB) *MedicineBatchRepository {
+	return &MedicineBatchRepository{
+		db: db,
+	}
+}
+
+func (r *MedicineBatchRepository) Transaction(ctx context.Context, fn func(repo *MedicineBatchRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *MedicineBatchRepository) withTx(tx *gorm.DB) *MedicineBatchRepository {
+	return &MedicineBatchRepository{db: tx}
+}
+
+func (r *MedicineBatchRepository) Create(ctx context.Context, batch *models.MedicineBatch) error {
+	return r.db.WithContext(ctx).Create(batch).Error
+}
+
+func (r *MedicineBatchRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.MedicineBatch, error) {
+	var batch models.MedicineBatch
+	if err := r.db.WithContext(ctx).Preload("Medicine").Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// FindAvailableByMedicineIDForUpdate locks the medicine's non-empty batches until the
+// surrounding transaction ends, so concurrent allocations cannot overdraw a batch.
+func (r *MedicineBatchRepository) FindAvailableByMedicineIDForUpdate(ctx context.Context, medicineID uuid.UUID) ([]models.MedicineBatch, error) {
+	var batches []models.MedicineBatch
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("medicine_id = ? AND quantity > 0", medicineID).
+		Order("expiry_date ASC NULLS LAST, received_at ASC").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// FindExpiringBefore returns non-empty batches whose expiry date is on or before cutoff,
+// including batches that have already expired.
+func (r *MedicineBatchRepository) FindExpiringBefore(ctx context.Context, cutoff time.Time) ([]models.MedicineBatch, error) {
+	var batches []models.MedicineBatch
+	if err := r.db.WithContext(ctx).
+		Preload("Medicine").
+		Where("quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", cutoff.Format("2006-01-02")).
+		Order("expiry_date ASC, received_at ASC").
+		Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *MedicineBatchRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.MedicineBatch, error) {
+	var batch models.MedicineBatch
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (r *MedicineBatchRepository) FindByLotForUpdate(ctx context.Context, medicineID uuid.UUID, lotNumber string) (*models.MedicineBatch, error) {
+	var batch models.MedicineBatch
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("medicine_id = ? AND lot_number = ?", medicineID, lotNumber).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// AddQuantity adds delta (negative to consume) to the batch's remaining quantity.
+func (r *MedicineBatchRepository) AddQuantity(ctx context.Context, id uuid.UUID, delta float64) error {
+	return r.db.WithContext(ctx).Model(&models.MedicineBatch{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"quantity":   gorm.Expr("quantity + ?", delta),
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// AddReceived records a further delivery into an existing lot.
+func (r *MedicineBatchRepository) AddReceived(ctx context.Context, id uuid.UUID, quantity float64) error {
+	return r.db.WithContext(ctx).Model(&models.MedicineBatch{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"quantity":          gorm.Expr("quantity + ?", quantity),
+			"received_quantity": gorm.Expr("received_quantity + ?", quantity),
+			"updated_at":        time.Now(),
+		}).Error
+}
+
+// SumQuantityByMedicine returns the remaining batch quantity per medicine.
+func (r *MedicineBatchRepository) SumQuantityByMedicine(ctx context.Context) (map[uuid.UUID]float64, error) {
+	var rows []struct {
+		MedicineID uuid.UUID
+		Quantity   float64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.MedicineBatch{}).
+		Select("medicine_id, COALESCE(SUM(quantity), 0) AS quantity").
+		Group("medicine_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uuid.UUID]float64, len(rows))
+	for _, row := range rows {
+		result[row.MedicineID] = row.Quantity
+	}
+	return result, nil
+}
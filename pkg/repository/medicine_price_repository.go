@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MedicinePriceRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicinePriceRepository(db *gorm.DB) *MedicinePriceRepository {
+	return &MedicinePriceRepository{
+		db: db,
+	}
+}
+
+func (r *MedicinePriceRepository) Transaction(ctx context.Context, fn func(repo *MedicinePriceRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *MedicinePriceRepository) withTx(tx *gorm.DB) *MedicinePriceRepository {
+	return &MedicinePriceRepository{db: tx}
+}
+
+func (r *MedicinePriceRepository) Create(ctx context.Context, price *models.MedicinePrice) error {
+	return r.db.WithContext(ctx).Create(price).Error
+}
+
+func (r *MedicinePriceRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.MedicinePrice, error) {
+	var price models.MedicinePrice
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&price).Error; err != nil {
+		return nil, err
+	}
+	return &price, nil
+}
+
+// FindByUnitID returns the price ranges of a unit, oldest first.
+func (r *MedicinePriceRepository) FindByUnitID(ctx context.Context, unitID uuid.UUID) ([]models.MedicinePrice, error) {
+	var prices []models.MedicinePrice
+	if err := r.db.WithContext(ctx).Where("unit_id = ?", unitID).Order("effective_from ASC").Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// FindByMedicineID returns the price history of every unit of a medicine, newest first.
+func (r *MedicinePriceRepository) FindByMedicineID(ctx context.Context, medicineID uuid.UUID) ([]models.MedicinePrice, error) {
+	var prices []models.MedicinePrice
+	if err := r.db.WithContext(ctx).
+		Preload("Unit").
+		Where("medicine_id = ?", medicineID).
+		Order("effective_from DESC, created_at DESC").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// FindEffective returns the prices in effect at the given time for the given units.
+// Units without a price at that time are left out.
+func (r *MedicinePriceRepository) FindEffective(ctx context.Context, unitIDs []uuid.UUID, at time.Time) ([]models.MedicinePrice, error) {
+	var prices []models.MedicinePrice
+	if len(unitIDs) == 0 {
+		return prices, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("unit_id IN ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", unitIDs, at, at).
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *MedicinePriceRepository) UpdateEffectiveTo(ctx context.Context, id uuid.UUID, effectiveTo *time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.MedicinePrice{}).Where("id = ?", id).
+		UpdateColumn("effective_to", effectiveTo).Error
+}
+
+func (r *MedicinePriceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.MedicinePrice{}).Error
+}
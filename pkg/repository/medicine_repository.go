@@ -3,9 +3,12 @@ package repository
 import (
 	"context"
 	"order-service/pkg/models"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MedicineRepository struct {
@@ -78,3 +81,188 @@ func (r *MedicineRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]
 	}
 	return medicines, nil
 }
+
+// FindByIDForUpdate locks the medicine row until the surrounding transaction ends.
+func (r *MedicineRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Medicine, error) {
+	var medicine models.Medicine
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&medicine).Error; err != nil {
+		return nil, err
+	}
+	return &medicine, nil
+}
+
+// ApplyStockDelta adds the deltas to the medicine's on-hand and reserved quantities.
+// Callers record the matching stock movement in the same transaction.
+func (r *MedicineRepository) ApplyStockDelta(ctx context.Context, id uuid.UUID, stockDelta, reservedDelta float64) error {
+	return r.db.WithContext(ctx).Model(&models.Medicine{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"stock":    gorm.Expr("stock + ?", stockDelta),
+			"reserved": gorm.Expr("reserved + ?", reservedDelta),
+		}).Error
+}
+
+func (r *MedicineRepository) FindAllIncludingDeleted(ctx context.Context) ([]models.Medicine, error) {
+	var medicines []models.Medicine
+	if err := r.db.WithContext(ctx).Unscoped().Order("name ASC").Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+// FindLowStock returns medicines whose available stock (on hand minus reserved) is at or
+// below their reorder point, lowest coverage first.
+func (r *MedicineRepository) FindLowStock(ctx context.Context) ([]models.Medicine, error) {
+	var medicines []models.Medicine
+	if err := r.db.WithContext(ctx).
+		Where("deleted_at IS NULL AND reorder_point IS NOT NULL AND stock - reserved <= reorder_point").
+		Order("(stock - reserved) - reorder_point ASC, name ASC").
+		Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+func (r *MedicineRepository) UpdateReorderLevels(ctx context.Context, id uuid.UUID, reorderPoint, reorderQuantity *float64) error {
+	return r.db.WithContext(ctx).Model(&models.Medicine{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reorder_point":    reorderPoint,
+			"reorder_quantity": reorderQuantity,
+			"updated_at":       time.Now(),
+		}).Error
+}
+
+// MedicineSearchFilter narrows a catalog search. Empty fields do not filter.
+type MedicineSearchFilter struct {
+	Query          string
+	Unit           string
+	Classification string
+	// ATC code or code prefix, e.g. "N02" matches every analgesic
+	Category    string
+	InStockOnly bool
+	Limit       int
+	Offset      int
+}
+
+// fuzzyMatchThreshold is the minimum pg_trgm word similarity for a typo to still match;
+// the extension default of 0.6 misses common misspellings of short drug names.
+const fuzzyMatchThreshold = "0.4"
+
+// Search matches the query against the full-text vector, as a substring of any name, and
+// by trigram word similarity to tolerate typos. Trigrams only cover Latin script in most
+// database locales, so Thai names rely on the full-text and substring matches. Results are
+// ranked by the best match, then by name.
+func (r *MedicineRepository) Search(ctx context.Context, filter MedicineSearchFilter) ([]models.Medicine, int64, error) {
+	var medicines []models.Medicine
+	var total int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&models.Medicine{}).Where("deleted_at IS NULL")
+		if filter.Unit != "" {
+			q = q.Where("LOWER(unit) = LOWER(?)", filter.Unit)
+		}
+		if filter.Classification != "" {
+			q = q.Where("classification = ?", filter.Classification)
+		}
+		if filter.Category != "" {
+			q = q.Where("atc_code LIKE ?", escapeLike(strings.ToUpper(filter.Category))+"%")
+		}
+		if filter.InStockOnly {
+			q = q.Where("stock - reserved > 0")
+		}
+
+		order := clause.OrderBy{Columns: []clause.OrderByColumn{{Column: clause.Column{Name: "name"}}}}
+		if filter.Query != "" {
+			if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", fuzzyMatchThreshold).Error; err != nil {
+				return err
+			}
+			like := "%" + escapeLike(filter.Query) + "%"
+			q = q.Where(`search_vector @@ websearch_to_tsquery('simple', ?)
+				OR name ILIKE ? OR generic_name ILIKE ? OR brand_name ILIKE ? OR name_th ILIKE ?
+				OR ? <% name OR ? <% generic_name OR ? <% brand_name`,
+				filter.Query, like, like, like, like, filter.Query, filter.Query, filter.Query)
+			order = clause.OrderBy{Expression: clause.Expr{
+				SQL: `GREATEST(
+					ts_rank(search_vector, websearch_to_tsquery('simple', ?)),
+					CASE WHEN name ILIKE ? OR generic_name ILIKE ? OR brand_name ILIKE ? OR name_th ILIKE ? THEN 1 ELSE 0 END,
+					word_similarity(?, name),
+					word_similarity(?, coalesce(generic_name, '')),
+					word_similarity(?, coalesce(brand_name, ''))
+				) DESC, name ASC`,
+				Vars:               []interface{}{filter.Query, like, like, like, like, filter.Query, filter.Query, filter.Query},
+				WithoutParentheses: true,
+			}}
+		}
+
+		// a fresh session per query so Count does not leak into Find
+		q = q.Session(&gorm.Session{})
+		if err := q.Count(&total).Error; err != nil {
+			return err
+		}
+		return q.Clauses(order).Preload("Category").Preload("Units", orderUnitsByFactor).
+			Limit(filter.Limit).Offset(filter.Offset).Find(&medicines).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return medicines, total, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// FindByIDWithDetails loads the medicine with its category and sellable units.
+func (r *MedicineRepository) FindByIDWithDetails(ctx context.Context, id uuid.UUID) (*models.Medicine, error) {
+	var medicine models.Medicine
+	if err := r.db.WithContext(ctx).Preload("Category").Preload("Units", orderUnitsByFactor).
+		Where("id = ?", id).First(&medicine).Error; err != nil {
+		return nil, err
+	}
+	return &medicine, nil
+}
+
+func orderUnitsByFactor(db *gorm.DB) *gorm.DB {
+	return db.Order("factor ASC")
+}
+
+func (r *MedicineRepository) UpdatePrice(ctx context.Context, id uuid.UUID, price float64) error {
+	return r.db.WithContext(ctx).Model(&models.Medicine{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"price":      price,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// FindBySKUs returns the medicines with the given SKUs, including deleted ones, with
+// their units.
+func (r *MedicineRepository) FindBySKUs(ctx context.Context, skus []string) ([]models.Medicine, error) {
+	var medicines []models.Medicine
+	if len(skus) == 0 {
+		return medicines, nil
+	}
+	if err := r.db.WithContext(ctx).Unscoped().
+		Preload("Units", orderUnitsByFactor).
+		Where("sku IN ?", skus).
+		Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+// FindAllWithUnits returns every medicine that is not deleted with its units, ordered by SKU.
+func (r *MedicineRepository) FindAllWithUnits(ctx context.Context) ([]models.Medicine, error) {
+	var medicines []models.Medicine
+	if err := r.db.WithContext(ctx).
+		Preload("Units", orderUnitsByFactor).
+		Order("sku ASC").
+		Find(&medicines).Error; err != nil {
+		return nil, err
+	}
+	return medicines, nil
+}
+
+// UpdateFields sets the given columns of a medicine. Values may be nil to clear a column.
+func (r *MedicineRepository) UpdateFields(ctx context.Context, id uuid.UUID, fields map[string]interface{}) error {
+	fields["updated_at"] = time.Now()
+	return r.db.WithContext(ctx).Model(&models.Medicine{}).Where("id = ?", id).Updates(fields).Error
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MedicineUnitRepository struct {
+	db *gorm.DB
+}
+
+func NewMedicineUnitRepository(db *gorm.DB) *MedicineUnitRepository {
+	return &MedicineUnitRepository{
+		db: db,
+	}
+}
+
+func (r *MedicineUnitRepository) Transaction(ctx context.Context, fn func(repo *MedicineUnitRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *MedicineUnitRepository) withTx(tx *gorm.DB) *MedicineUnitRepository {
+	return &MedicineUnitRepository{db: tx}
+}
+
+func (r *MedicineUnitRepository) Create(ctx context.Context, unit *models.MedicineUnit) error {
+	return r.db.WithContext(ctx).Create(unit).Error
+}
+
+func (r *MedicineUnitRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.MedicineUnit, error) {
+	var unit models.MedicineUnit
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&unit).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+// FindByIDForUpdate locks the unit until the surrounding transaction ends. Price changes
+// take this lock so concurrent changes to the same unit's price ranges are serialized.
+func (r *MedicineUnitRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.MedicineUnit, error) {
+	var unit models.MedicineUnit
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&unit).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (r *MedicineUnitRepository) FindByMedicineID(ctx context.Context, medicineID uuid.UUID) ([]models.MedicineUnit, error) {
+	var units []models.MedicineUnit
+	if err := r.db.WithContext(ctx).Where("medicine_id = ?", medicineID).Order("factor ASC").Find(&units).Error; err != nil {
+		return nil, err
+	}
+	return units, nil
+}
+
+func (r *MedicineUnitRepository) FindBaseByMedicineID(ctx context.Context, medicineID uuid.UUID) (*models.MedicineUnit, error) {
+	var unit models.MedicineUnit
+	if err := r.db.WithContext(ctx).Where("medicine_id = ? AND is_base", medicineID).First(&unit).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (r *MedicineUnitRepository) FindByMedicineAndName(ctx context.Context, medicineID uuid.UUID, name string) (*models.MedicineUnit, error) {
+	var unit models.MedicineUnit
+	if err := r.db.WithContext(ctx).Where("medicine_id = ? AND name = ?", medicineID, name).First(&unit).Error; err != nil {
+		return nil, err
+	}
+	return &unit, nil
+}
+
+func (r *MedicineUnitRepository) Update(ctx context.Context, unit *models.MedicineUnit) error {
+	return r.db.WithContext(ctx).Model(unit).Select("factor", "price", "updated_at").Updates(unit).Error
+}
+
+func (r *MedicineUnitRepository) UpdatePrice(ctx context.Context, id uuid.UUID, price float64) error {
+	return r.db.WithContext(ctx).Model(&models.MedicineUnit{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"price":      price,
+			"updated_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationLogRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationLogRepository(db *gorm.DB) *NotificationLogRepository {
+	return &NotificationLogRepository{
+		db: db,
+	}
+}
+
+func (r *NotificationLogRepository) Transaction(ctx context.Context, fn func(repo *NotificationLogRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *NotificationLogRepository) withTx(tx *gorm.DB) *NotificationLogRepository {
+	return &NotificationLogRepository{db: tx}
+}
+
+// Exists reports whether a message for the event was already sent to the user on the
+// channel.
+func (r *NotificationLogRepository) Exists(ctx context.Context, eventID, userID uuid.UUID, channel string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.NotificationLog{}).
+		Where("event_id = ? AND user_id = ? AND channel = ?", eventID, userID, channel).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create logs a send; a message already logged for the event, user and channel is kept.
+func (r *NotificationLogRepository) Create(ctx context.Context, entry *models.NotificationLog) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "event_id"}, {Name: "user_id"}, {Name: "channel"}}, DoNothing: true}).
+		Create(entry).Error
+}
+
+// FindByUserID returns the latest messages sent to a user, newest first.
+func (r *NotificationLogRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]models.NotificationLog, error) {
+	var entries []models.NotificationLog
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationPreferenceRepository(db *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{
+		db: db,
+	}
+}
+
+func (r *NotificationPreferenceRepository) Transaction(ctx context.Context, fn func(repo *NotificationPreferenceRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *NotificationPreferenceRepository) withTx(tx *gorm.DB) *NotificationPreferenceRepository {
+	return &NotificationPreferenceRepository{db: tx}
+}
+
+func (r *NotificationPreferenceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.NotificationPreference, error) {
+	var preference models.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&preference).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+// Save creates or replaces the preferences of a user.
+func (r *NotificationPreferenceRepository) Save(ctx context.Context, preference *models.NotificationPreference) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"language", "email", "email_enabled", "phone", "sms_enabled", "line_user_id", "line_enabled", "updated_at"}),
+	}).Create(preference).Error
+}
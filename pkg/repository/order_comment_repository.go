@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderCommentRepository(db *gorm.DB) *OrderCommentRepository {
+	return &OrderCommentRepository{
+		db: db,
+	}
+}
+
+func (r *OrderCommentRepository) Transaction(ctx context.Context, fn func(repo *OrderCommentRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderCommentRepository) withTx(tx *gorm.DB) *OrderCommentRepository {
+	return &OrderCommentRepository{db: tx}
+}
+
+func (r *OrderCommentRepository) Create(ctx context.Context, comment *models.OrderComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+// FindByOrderID returns the comments on an order, oldest first.
+func (r *OrderCommentRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderComment, error) {
+	var comments []models.OrderComment
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// liveOrderStatuses are the statuses of approved orders that have not been cancelled.
+// Their coverage counts towards annual caps and their discounts towards usage limits.
+var liveOrderStatuses = []models.OrderStatus{
+	models.OrderStatusApproved,
+	models.OrderStatusPaid,
+	models.OrderStatusProcessing,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+	models.OrderStatusPartiallyRefunded,
+}
+
+type OrderCoverageLineRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderCoverageLineRepository(db *gorm.DB) *OrderCoverageLineRepository {
+	return &OrderCoverageLineRepository{
+		db: db,
+	}
+}
+
+func (r *OrderCoverageLineRepository) Transaction(ctx context.Context, fn func(repo *OrderCoverageLineRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderCoverageLineRepository) withTx(tx *gorm.DB) *OrderCoverageLineRepository {
+	return &OrderCoverageLineRepository{db: tx}
+}
+
+func (r *OrderCoverageLineRepository) Create(ctx context.Context, line *models.OrderCoverageLine) error {
+	return r.db.WithContext(ctx).Create(line).Error
+}
+
+func (r *OrderCoverageLineRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderCoverageLine, error) {
+	var lines []models.OrderCoverageLine
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *OrderCoverageLineRepository) DeleteByOrderID(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&models.OrderCoverageLine{}).Error
+}
+
+// LockPatient serializes coverage of a patient's orders until the surrounding
+// transaction ends, so two approvals cannot both spend the same annual cap.
+func (r *OrderCoverageLineRepository) LockPatient(ctx context.Context, patientID uuid.UUID) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "coverage:"+patientID.String()).Error
+}
+
+// SumInsurerAmounts returns what each rule has paid for the patient's live orders since
+// the given time, keyed by rule ID.
+func (r *OrderCoverageLineRepository) SumInsurerAmounts(ctx context.Context, patientID uuid.UUID, ruleIDs []uuid.UUID, since time.Time) (map[uuid.UUID]float64, error) {
+	used := make(map[uuid.UUID]float64)
+	if len(ruleIDs) == 0 {
+		return used, nil
+	}
+	var rows []struct {
+		RuleID uuid.UUID
+		Total  float64
+	}
+	if err := r.db.WithContext(ctx).
+		Table("order_coverage_lines AS l").
+		Select("l.rule_id, SUM(l.insurer_amount) AS total").
+		Joins("JOIN orders o ON o.id = l.order_id").
+		Where("o.patient_id = ? AND l.rule_id IN ? AND l.created_at >= ? AND o.status IN ?", patientID, ruleIDs, since, liveOrderStatuses).
+		Group("l.rule_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		used[row.RuleID] = row.Total
+	}
+	return used, nil
+}
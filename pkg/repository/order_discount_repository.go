@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderDiscountRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderDiscountRepository(db *gorm.DB) *OrderDiscountRepository {
+	return &OrderDiscountRepository{
+		db: db,
+	}
+}
+
+func (r *OrderDiscountRepository) Transaction(ctx context.Context, fn func(repo *OrderDiscountRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderDiscountRepository) withTx(tx *gorm.DB) *OrderDiscountRepository {
+	return &OrderDiscountRepository{db: tx}
+}
+
+func (r *OrderDiscountRepository) Create(ctx context.Context, discount *models.OrderDiscount) error {
+	return r.db.WithContext(ctx).Create(discount).Error
+}
+
+func (r *OrderDiscountRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderDiscount, error) {
+	var discounts []models.OrderDiscount
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC").Find(&discounts).Error; err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+func (r *OrderDiscountRepository) DeleteByOrderID(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&models.OrderDiscount{}).Error
+}
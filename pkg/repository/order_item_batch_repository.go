@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderItemBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderItemBatchRepository(db *gorm.DB) *OrderItemBatchRepository {
+	return &OrderItemBatchRepository{
+		db: db,
+	}
+}
+
+func (r *OrderItemBatchRepository) Transaction(ctx context.Context, fn func(repo *OrderItemBatchRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderItemBatchRepository) withTx(tx *gorm.DB) *OrderItemBatchRepository {
+	return &OrderItemBatchRepository{db: tx}
+}
+
+func (r *OrderItemBatchRepository) Create(ctx context.Context, itemBatch *models.OrderItemBatch) error {
+	return r.db.WithContext(ctx).Create(itemBatch).Error
+}
+
+func (r *OrderItemBatchRepository) FindByOrderItemID(ctx context.Context, orderItemID uuid.UUID) ([]models.OrderItemBatch, error) {
+	var itemBatches []models.OrderItemBatch
+	if err := r.db.WithContext(ctx).Preload("Batch").Where("order_item_id = ?", orderItemID).Order("created_at ASC").Find(&itemBatches).Error; err != nil {
+		return nil, err
+	}
+	return itemBatches, nil
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"order-service/pkg/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepository struct {
@@ -46,7 +48,7 @@ func (r *OrderRepository) Create(ctx context.Context, order *models.Order) error
 
 func (r *OrderRepository) FindLatestOrderByPatientID(ctx context.Context, patientID uuid.UUID) (*models.Order, error) {
 	var order models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("patient_id = ? AND status != 'pending'", patientID).Order("created_at DESC").First(&order).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("patient_id = ? AND status != 'pending'", patientID).Order("created_at DESC").First(&order).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -57,7 +59,7 @@ func (r *OrderRepository) FindLatestOrderByPatientID(ctx context.Context, patien
 
 func (r *OrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Order, error) {
 	var order models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("id = ?", id).First(&order).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("id = ?", id).First(&order).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, err
 		}
@@ -66,9 +68,19 @@ func (r *OrderRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.O
 	return &order, nil
 }
 
+// FindByIDForUpdate loads the order like FindByID and locks its row until the
+// surrounding transaction ends.
+func (r *OrderRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*models.Order, error) {
+	var order models.Order
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *OrderRepository) FindByPatientID(ctx context.Context, patientID uuid.UUID) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("patient_id = ?", patientID).Order("created_at DESC").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("patient_id = ?", patientID).Order("created_at DESC").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -76,14 +88,40 @@ func (r *OrderRepository) FindByPatientID(ctx context.Context, patientID uuid.UU
 
 func (r *OrderRepository) FindAll(ctx context.Context) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
 
+// Update saves the order's own columns; items are managed through their repositories.
 func (r *OrderRepository) Update(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Model(order).Updates(order).Error
+	return r.db.WithContext(ctx).Model(order).Omit(clause.Associations).Updates(order).Error
+}
+
+// UpdateTotals saves the order's total breakdown and coupon, including zero discounts
+// and a removed coupon.
+func (r *OrderRepository) UpdateTotals(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Model(order).Omit(clause.Associations).
+		Select("subtotal_amount", "discount_amount", "total_amount", "coupon_code").Updates(order).Error
+}
+
+// UpdateCoverage saves how the order's total is split, including an empty split.
+func (r *OrderRepository) UpdateCoverage(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Model(order).Omit(clause.Associations).
+		Select("entitlement", "insurer_amount", "patient_amount").Updates(order).Error
+}
+
+// UpdateTax saves the VAT included in the order's total, including zero.
+func (r *OrderRepository) UpdateTax(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Model(order).Omit(clause.Associations).
+		Select("vat_amount").Updates(order).Error
+}
+
+// UpdateControlledApproval saves only the admin co-sign of the order.
+func (r *OrderRepository) UpdateControlledApproval(ctx context.Context, order *models.Order) error {
+	return r.db.WithContext(ctx).Model(order).Omit(clause.Associations).
+		Select("controlled_approved_by", "controlled_approved_at").Updates(order).Error
 }
 
 func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -92,15 +130,27 @@ func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *OrderRepository) FindByStatus(ctx context.Context, status models.OrderStatus) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("status = ?", status).Order("created_at DESC").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("status = ?", status).Order("created_at DESC").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
 
+// FindIDsWaitingBefore returns the orders in a status that have been waiting since
+// before the cutoff, counted from the later of their submission and their review.
+func (r *OrderRepository) FindIDsWaitingBefore(ctx context.Context, status models.OrderStatus, before time.Time) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("status = ? AND GREATEST(reviewed_at, submitted_at, created_at) < ?", status, before).
+		Order("created_at ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *OrderRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("id IN ?", ids).Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("id IN ?", ids).Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -108,7 +158,7 @@ func (r *OrderRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]mod
 
 func (r *OrderRepository) FindByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("doctor_id = ? AND status = 'pending'", doctorID).Order("created_at DESC").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("doctor_id = ? AND status = 'pending'", doctorID).Order("created_at DESC").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -116,7 +166,7 @@ func (r *OrderRepository) FindByDoctorID(ctx context.Context, doctorID uuid.UUID
 
 func (r *OrderRepository) FindByDoctorIDAndStatus(ctx context.Context, doctorID uuid.UUID, status models.OrderStatus) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("doctor_id = ? AND status = ?", doctorID, status).Order("created_at DESC").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("doctor_id = ? AND status = ?", doctorID, status).Order("created_at DESC").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
@@ -124,7 +174,7 @@ func (r *OrderRepository) FindByDoctorIDAndStatus(ctx context.Context, doctorID
 
 func (r *OrderRepository) FindByDoctorIDAndStatuses(ctx context.Context, doctorID uuid.UUID, statuses []models.OrderStatus) ([]models.Order, error) {
 	var orders []models.Order
-	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Where("doctor_id = ? AND status IN ?", doctorID, statuses).Order("created_at DESC").Find(&orders).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("OrderItems.Medicine").Preload("OrderItems.Unit").Preload("OrderItems.Batches.Batch").Preload("RequestedItems.Medicine").Preload("RequestedItems.Unit").Preload("Discounts").Where("doctor_id = ? AND status IN ?", doctorID, statuses).Order("created_at DESC").Find(&orders).Error; err != nil {
 		return nil, err
 	}
 	return orders, nil
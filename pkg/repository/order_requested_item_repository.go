@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderRequestedItemRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRequestedItemRepository(db *gorm.DB) *OrderRequestedItemRepository {
+	return &OrderRequestedItemRepository{
+		db: db,
+	}
+}
+
+func (r *OrderRequestedItemRepository) Transaction(ctx context.Context, fn func(repo *OrderRequestedItemRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderRequestedItemRepository) withTx(tx *gorm.DB) *OrderRequestedItemRepository {
+	return &OrderRequestedItemRepository{db: tx}
+}
+
+func (r *OrderRequestedItemRepository) Create(ctx context.Context, item *models.OrderRequestedItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *OrderRequestedItemRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderRequestedItem, error) {
+	var items []models.OrderRequestedItem
+	if err := r.db.WithContext(ctx).Preload("Medicine").Preload("Unit").Where("order_id = ?", orderID).Order("created_at ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *OrderRequestedItemRepository) Update(ctx context.Context, item *models.OrderRequestedItem) error {
+	return r.db.WithContext(ctx).Model(item).Select("status", "approved_quantity", "doctor_note", "updated_at").Updates(item).Error
+}
+
+func (r *OrderRequestedItemRepository) DeleteByOrderID(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&models.OrderRequestedItem{}).Error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderStatusChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderStatusChangeRepository(db *gorm.DB) *OrderStatusChangeRepository {
+	return &OrderStatusChangeRepository{
+		db: db,
+	}
+}
+
+func (r *OrderStatusChangeRepository) Transaction(ctx context.Context, fn func(repo *OrderStatusChangeRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderStatusChangeRepository) withTx(tx *gorm.DB) *OrderStatusChangeRepository {
+	return &OrderStatusChangeRepository{db: tx}
+}
+
+func (r *OrderStatusChangeRepository) Create(ctx context.Context, change *models.OrderStatusChange) error {
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// FindByOrderID returns the history of an order, oldest first.
+func (r *OrderStatusChangeRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderStatusChange, error) {
+	var changes []models.OrderStatusChange
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
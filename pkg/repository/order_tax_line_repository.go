@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OrderTaxLineRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderTaxLineRepository(db *gorm.DB) *OrderTaxLineRepository {
+	return &OrderTaxLineRepository{
+		db: db,
+	}
+}
+
+func (r *OrderTaxLineRepository) Transaction(ctx context.Context, fn func(repo *OrderTaxLineRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OrderTaxLineRepository) withTx(tx *gorm.DB) *OrderTaxLineRepository {
+	return &OrderTaxLineRepository{db: tx}
+}
+
+func (r *OrderTaxLineRepository) Create(ctx context.Context, line *models.OrderTaxLine) error {
+	return r.db.WithContext(ctx).Create(line).Error
+}
+
+func (r *OrderTaxLineRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.OrderTaxLine, error) {
+	var lines []models.OrderTaxLine
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *OrderTaxLineRepository) DeleteByOrderID(ctx context.Context, orderID uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("order_id = ?", orderID).Delete(&models.OrderTaxLine{}).Error
+}
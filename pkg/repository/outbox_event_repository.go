@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxEventRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxEventRepository(db *gorm.DB) *OutboxEventRepository {
+	return &OutboxEventRepository{
+		db: db,
+	}
+}
+
+func (r *OutboxEventRepository) Transaction(ctx context.Context, fn func(repo *OutboxEventRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *OutboxEventRepository) withTx(tx *gorm.DB) *OutboxEventRepository {
+	return &OutboxEventRepository{db: tx}
+}
+
+func (r *OutboxEventRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// FindDueForUpdate locks up to limit pending events whose next attempt is due, oldest
+// first. An event is left out while an older event of the same aggregate is still
+// pending, so each aggregate's events are published in order. Rows locked by another
+// relay are skipped.
+func (r *OutboxEventRepository) FindDueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxEventStatusPending, now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_events older WHERE older.aggregate_id = outbox_events.aggregate_id AND older.status = ? AND older.id < outbox_events.id)", models.OutboxEventStatusPending).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Lease pushes the next attempt of the events back to until, so they are not taken again
+// while the relay that claimed them is publishing them, and are retried if it stops.
+func (r *OutboxEventRepository) Lease(ctx context.Context, ids []uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("next_attempt_at", until).Error
+}
+
+func (r *OutboxEventRepository) MarkPublished(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.OutboxEventStatusPublished,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"published_at": at,
+		"last_error":   nil,
+	}).Error
+}
+
+// MarkFailed records a failed attempt. The event is retried at nextAttemptAt, or moved
+// to dead when dead is set.
+func (r *OutboxEventRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := models.OutboxEventStatusPending
+	if dead {
+		status = models.OutboxEventStatusDead
+	}
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}).Error
+}
+
+// FindCreatedSince returns up to limit events written at or after since, oldest first,
+// whatever their publishing status.
+func (r *OutboxEventRepository) FindCreatedSince(ctx context.Context, since time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	if err := r.db.WithContext(ctx).Where("created_at >= ?", since).Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FindOrderEventsForUserAfter returns up to limit order events written after the event
+// afterID whose patient or doctor is userID, oldest first.
+func (r *OutboxEventRepository) FindOrderEventsForUserAfter(ctx context.Context, userID string, afterID uuid.UUID, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("aggregate_type = ? AND id > ?", "order", afterID).
+		Where("(payload->>'patient_id' = ? OR payload->>'doctor_id' = ?)", userID, userID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{
+		db: db,
+	}
+}
+
+func (r *PromotionRepository) Transaction(ctx context.Context, fn func(repo *PromotionRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *PromotionRepository) withTx(tx *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{db: tx}
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Create(promotion).Error
+}
+
+func (r *PromotionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := r.db.WithContext(ctx).Preload("Medicine").Where("id = ?", id).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// FindAll returns every promotion, newest first.
+func (r *PromotionRepository) FindAll(ctx context.Context) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	if err := r.db.WithContext(ctx).Preload("Medicine").Order("created_at DESC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// FindByCouponCode looks a coupon up regardless of case.
+func (r *PromotionRepository) FindByCouponCode(ctx context.Context, code string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := r.db.WithContext(ctx).Where("upper(coupon_code) = ?", strings.ToUpper(code)).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// FindApplicable returns the automatic promotions running at the given time, and the
+// promotion of the coupon code when one is given, locked until the surrounding
+// transaction ends so usage limits are checked one order at a time.
+func (r *PromotionRepository) FindApplicable(ctx context.Context, couponCode *string, at time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	query := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("active AND starts_at <= ? AND (ends_at IS NULL OR ends_at > ?)", at, at)
+	if couponCode != nil {
+		query = query.Where("(coupon_code IS NULL OR upper(coupon_code) = ?)", strings.ToUpper(*couponCode))
+	} else {
+		query = query.Where("coupon_code IS NULL")
+	}
+	if err := query.Order("created_at ASC").Find(&promotions).Error; err != nil {
+		return nil, err
+	}
+	return promotions, nil
+}
+
+// CountUses returns how many live orders other than the given one used the promotion,
+// in total and by the patient.
+func (r *PromotionRepository) CountUses(ctx context.Context, promotionID, patientID, excludeOrderID uuid.UUID) (int64, int64, error) {
+	var counts struct {
+		Total   int64
+		Patient int64
+	}
+	if err := r.db.WithContext(ctx).
+		Table("orders AS o").
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE o.patient_id = ?) AS patient", patientID).
+		Where("o.id <> ? AND o.status IN ?", excludeOrderID, liveOrderStatuses).
+		Where("EXISTS (SELECT 1 FROM order_discounts d WHERE d.order_id = o.id AND d.promotion_id = ?)", promotionID).
+		Scan(&counts).Error; err != nil {
+		return 0, 0, err
+	}
+	return counts.Total, counts.Patient, nil
+}
+
+func (r *PromotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	return r.db.WithContext(ctx).Model(promotion).Omit(clause.Associations).
+		Select("name", "kind", "value", "scope", "medicine_id", "coupon_code", "min_order_amount", "max_discount",
+			"usage_limit", "per_patient_limit", "stackable", "active", "starts_at", "ends_at", "updated_at").
+		Updates(promotion).Error
+}
+
+func (r *PromotionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.Promotion{}).Error
+}
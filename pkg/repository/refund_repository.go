@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{
+		db: db,
+	}
+}
+
+func (r *RefundRepository) Transaction(ctx context.Context, fn func(repo *RefundRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *RefundRepository) withTx(tx *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: tx}
+}
+
+// Create saves the refund together with its items.
+func (r *RefundRepository) Create(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Create(refund).Error
+}
+
+func (r *RefundRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.Refund, error) {
+	var refunds []models.Refund
+	if err := r.db.WithContext(ctx).Preload("Items").Where("order_id = ?", orderID).Order("created_at ASC").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// SavePayout saves the outcome of paying the refund out: its status, provider reference
+// and last error.
+func (r *RefundRepository) SavePayout(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Model(refund).Omit(clause.Associations).Select("status", "provider_reference", "last_error").Updates(refund).Error
+}
+
+// FindPendingBefore returns refunds recorded before the given time that have not been
+// paid out yet, oldest first.
+func (r *RefundRepository) FindPendingBefore(ctx context.Context, before time.Time, limit int) ([]models.Refund, error) {
+	var refunds []models.Refund
+	if err := r.db.WithContext(ctx).Preload("Items").
+		Where("status = ? AND created_at < ?", models.RefundStatusPending, before).
+		Order("created_at ASC").Limit(limit).Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
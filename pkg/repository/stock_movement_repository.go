@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LedgerBalance is the stock and reserved quantity of a medicine as derived from the ledger.
+type LedgerBalance struct {
+	MedicineID uuid.UUID
+	Stock      float64
+	Reserved   float64
+}
+
+type StockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{
+		db: db,
+	}
+}
+
+func (r *StockMovementRepository) Transaction(ctx context.Context, fn func(repo *StockMovementRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *StockMovementRepository) withTx(tx *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: tx}
+}
+
+func (r *StockMovementRepository) Create(ctx context.Context, movement *models.StockMovement) error {
+	return r.db.WithContext(ctx).Create(movement).Error
+}
+
+func (r *StockMovementRepository) FindByMedicineID(ctx context.Context, medicineID uuid.UUID, limit int) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	if err := r.db.WithContext(ctx).Preload("Batch").Where("medicine_id = ?", medicineID).
+		Order("created_at DESC").Limit(limit).Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+func (r *StockMovementRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC").Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	return movements, nil
+}
+
+// SumByMedicine totals the ledger per medicine.
+func (r *StockMovementRepository) SumByMedicine(ctx context.Context) (map[uuid.UUID]LedgerBalance, error) {
+	var rows []LedgerBalance
+	if err := r.db.WithContext(ctx).Model(&models.StockMovement{}).
+		Select("medicine_id, COALESCE(SUM(stock_delta), 0) AS stock, COALESCE(SUM(reserved_delta), 0) AS reserved").
+		Group("medicine_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[uuid.UUID]LedgerBalance, len(rows))
+	for _, row := range rows {
+		result[row.MedicineID] = row
+	}
+	return result, nil
+}
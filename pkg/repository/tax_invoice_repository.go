@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TaxInvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxInvoiceRepository(db *gorm.DB) *TaxInvoiceRepository {
+	return &TaxInvoiceRepository{
+		db: db,
+	}
+}
+
+func (r *TaxInvoiceRepository) Transaction(ctx context.Context, fn func(repo *TaxInvoiceRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *TaxInvoiceRepository) withTx(tx *gorm.DB) *TaxInvoiceRepository {
+	return &TaxInvoiceRepository{db: tx}
+}
+
+// Create inserts the invoice together with its lines.
+func (r *TaxInvoiceRepository) Create(ctx context.Context, invoice *models.TaxInvoice) error {
+	return r.db.WithContext(ctx).Create(invoice).Error
+}
+
+// NextSequence takes the next running number of the document type for the year. The
+// sequence row stays locked until the surrounding transaction ends, so a rolled back
+// payment gives its number back instead of leaving a gap.
+func (r *TaxInvoiceRepository) NextSequence(ctx context.Context, documentType models.TaxDocumentType, year int) (int, error) {
+	var sequence int
+	err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO tax_invoice_sequences (document_type, year, last_number)
+		VALUES (?, ?, 1)
+		ON CONFLICT (document_type, year)
+		DO UPDATE SET last_number = tax_invoice_sequences.last_number + 1
+		RETURNING last_number`, documentType, year).Scan(&sequence).Error
+	return sequence, err
+}
+
+func (r *TaxInvoiceRepository) FindByOrderID(ctx context.Context, orderID uuid.UUID) ([]models.TaxInvoice, error) {
+	var invoices []models.TaxInvoice
+	if err := r.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line_no ASC")
+	}).Where("order_id = ?", orderID).Order("issued_at ASC").Find(&invoices).Error; err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *TaxInvoiceRepository) FindDocument(ctx context.Context, invoiceID uuid.UUID) (*models.TaxInvoiceDocument, error) {
+	var document models.TaxInvoiceDocument
+	if err := r.db.WithContext(ctx).Where("tax_invoice_id = ?", invoiceID).First(&document).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// CreateDocument keeps the rendered document of an invoice unless one was kept already,
+// and returns the one that is kept. When two downloads render the same invoice at once
+// both get the document that was stored first.
+func (r *TaxInvoiceRepository) CreateDocument(ctx context.Context, document *models.TaxInvoiceDocument) (*models.TaxInvoiceDocument, error) {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(document).Error; err != nil {
+		return nil, err
+	}
+	return r.FindDocument(ctx, document.TaxInvoiceID)
+}
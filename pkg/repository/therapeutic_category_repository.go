@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+type TherapeuticCategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewTherapeuticCategoryRepository(db *gorm.DB) *TherapeuticCategoryRepository {
+	return &TherapeuticCategoryRepository{
+		db: db,
+	}
+}
+
+func (r *TherapeuticCategoryRepository) FindAll(ctx context.Context) ([]models.TherapeuticCategory, error) {
+	var categories []models.TherapeuticCategory
+	if err := r.db.WithContext(ctx).Order("code ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *TherapeuticCategoryRepository) FindByCode(ctx context.Context, code string) (*models.TherapeuticCategory, error) {
+	var category models.TherapeuticCategory
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
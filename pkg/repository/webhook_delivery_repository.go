@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookDeliveryRepository(db *gorm.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookDeliveryRepository) Transaction(ctx context.Context, fn func(repo *WebhookDeliveryRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *WebhookDeliveryRepository) withTx(tx *gorm.DB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: tx}
+}
+
+// CreateIfAbsent queues the delivery unless the event was already queued for the
+// subscription.
+func (r *WebhookDeliveryRepository) CreateIfAbsent(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(delivery).Error
+}
+
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindBySubscriptionID returns the latest deliveries of a subscription, newest first,
+// optionally only those with the given status.
+func (r *WebhookDeliveryRepository) FindBySubscriptionID(ctx context.Context, subscriptionID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindDueForUpdate locks up to limit pending deliveries of active subscriptions whose
+// next attempt is due, oldest first. Rows locked by another worker are skipped.
+func (r *WebhookDeliveryRepository) FindDueForUpdate(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, now).
+		Where("subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)").
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Lease pushes the next attempt of the deliveries back to until, so they are not taken
+// again while they are being sent, and are retried if the sender stops.
+func (r *WebhookDeliveryRepository) Lease(ctx context.Context, ids []uuid.UUID, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", until).Error
+}
+
+// SaveAttempt records the outcome of an attempt: its status, attempt count, next
+// attempt time and last response.
+func (r *WebhookDeliveryRepository) SaveAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_status_code", "last_error", "last_attempt_at", "delivered_at").
+		Updates(delivery).Error
+}
+
+// Requeue makes a delivery due again with a fresh set of attempts.
+func (r *WebhookDeliveryRepository) Requeue(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.WebhookDeliveryStatusPending,
+		"attempts":        0,
+		"next_attempt_at": at,
+	}).Error
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"order-service/pkg/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookSubscriptionRepository(db *gorm.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{
+		db: db,
+	}
+}
+
+func (r *WebhookSubscriptionRepository) Transaction(ctx context.Context, fn func(repo *WebhookSubscriptionRepository) (interface{}, error)) (interface{}, error) {
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	repoWithTx := r.withTx(tx)
+
+	result, err := fn(repoWithTx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *WebhookSubscriptionRepository) withTx(tx *gorm.DB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: tx}
+}
+
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Create(subscription).Error
+}
+
+func (r *WebhookSubscriptionRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *WebhookSubscriptionRepository) FindAll(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) FindActive(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.WithContext(ctx).Where("active").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if len(ids) == 0 {
+		return subscriptions, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, subscription *models.WebhookSubscription) error {
+	return r.db.WithContext(ctx).Model(subscription).
+		Select("name", "url", "secret", "event_types", "active", "consecutive_failures", "disabled_at", "disabled_reason", "updated_at").
+		Updates(subscription).Error
+}
+
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.WebhookSubscription{}).Error
+}
+
+// RecordSuccess clears the subscription's run of failed deliveries.
+func (r *WebhookSubscriptionRepository) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.WebhookSubscription{}).
+		Where("id = ? AND consecutive_failures > 0", id).
+		Update("consecutive_failures", 0).Error
+}
+
+// RecordFailure counts a failed delivery and disables the subscription once
+// disableAfter deliveries in a row have failed. It reports whether this call disabled it.
+func (r *WebhookSubscriptionRepository) RecordFailure(ctx context.Context, id uuid.UUID, disableAfter int, reason string, at time.Time) (bool, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.WithContext(ctx).Model(&subscription).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "consecutive_failures"}, {Name: "active"}}}).
+		Where("id = ?", id).
+		Update("consecutive_failures", gorm.Expr("consecutive_failures + 1")).Error
+	if err != nil {
+		return false, err
+	}
+	if !subscription.Active || disableAfter <= 0 || subscription.ConsecutiveFailures < disableAfter {
+		return false, nil
+	}
+	err = r.db.WithContext(ctx).Model(&models.WebhookSubscription{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"active":          false,
+		"disabled_at":     at,
+		"disabled_reason": reason,
+	}).Error
+	return err == nil, err
+}
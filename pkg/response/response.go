@@ -7,7 +7,8 @@ type BaseResponse struct {
 }
 
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error   string         `json:"error"`
+	Details map[string]any `json:"details,omitempty"`
 }
 
 func OK[T any](c *fiber.Ctx, data T) error {
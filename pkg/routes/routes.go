@@ -12,7 +12,7 @@ import (
 	"github.com/gofiber/swagger"
 )
 
-func SetupRoutes(app *fiber.App, orderHandler *handlers.OrderHandler, medicineHandler *handlers.MedicineHandler, deliveryInfoHandler *handlers.DeliveryInfoHandler, jwtSvc *jwt.JwtService) {
+func SetupRoutes(app *fiber.App, orderHandler *handlers.OrderHandler, orderStreamHandler *handlers.OrderStreamHandler, medicineHandler *handlers.MedicineHandler, inventoryHandler *handlers.InventoryHandler, catalogHandler *handlers.CatalogHandler, coverageHandler *handlers.CoverageHandler, cancellationHandler *handlers.CancellationHandler, webhookHandler *handlers.WebhookHandler, notificationHandler *handlers.NotificationHandler, promotionHandler *handlers.PromotionHandler, deliveryInfoHandler *handlers.DeliveryInfoHandler, jwtSvc *jwt.JwtService) {
 
 	api := app.Group("/api")
 
@@ -26,20 +26,77 @@ func SetupRoutes(app *fiber.App, orderHandler *handlers.OrderHandler, medicineHa
 	orderV1.Put("/orders", orderHandler.UpdateOrder)
 	orderV1.Delete("/orders", orderHandler.CancelOrder)
 	orderV1.Post("/orders/confirm", orderHandler.ApproveOrder)
+	orderV1.Post("/orders/confirm/controlled", orderHandler.ApproveControlledOrder)
 	orderV1.Post("/orders/reject", orderHandler.RejectOrder)
+	orderV1.Post("/orders/request-changes", orderHandler.RequestOrderChanges)
+	orderV1.Post("/orders/resubmit", orderHandler.ResubmitOrder)
 	orderV1.Post("/orders/pay", orderHandler.PayOrder)
+	orderV1.Post("/orders/refund", orderHandler.RefundOrder)
+	orderV1.Post("/orders/delivery", orderHandler.UpdateOrderDelivery)
+	orderV1.Post("/orders/coupon", orderHandler.ApplyCoupon)
+	orderV1.Delete("/orders/coupon", orderHandler.RemoveCoupon)
 	orderV1.Get("/orders/latest", orderHandler.GetLatestOrder)
+	orderV1.Get("/orders/stream", orderStreamHandler.StreamOrders)
+	orderV1.Get("/orders/stream/ws", orderStreamHandler.UpgradeOrderStream, orderStreamHandler.StreamOrdersWebSocket())
 	orderV1.Get("/orders/latest/:patient_id", orderHandler.GetLatestOrderByPatientID)
 	orderV1.Get("/orders", orderHandler.GetAllOrdersHistory)
 	orderV1.Get("/orders/doctor", orderHandler.GetAllOrdersForDoctor)
 	orderV1.Get("/orders/doctor/history", orderHandler.GetAllOrdersHistoryForDoctor)
 	orderV1.Get("/orders/:id", orderHandler.GetOrder)
+	orderV1.Get("/orders/:id/labels", orderHandler.GetOrderLabels)
+	orderV1.Get("/orders/:id/history", orderHandler.GetOrderHistory)
+	orderV1.Get("/orders/:id/comments", orderHandler.GetOrderComments)
+	orderV1.Post("/orders/:id/comments", orderHandler.AddOrderComment)
+	orderV1.Get("/orders/:id/invoices", orderHandler.GetTaxInvoices)
+	orderV1.Get("/orders/:id/receipt.pdf", orderHandler.GetReceiptPDF)
+	orderV1.Post("/clinical/interactions/reload", orderHandler.ReloadClinicalTable)
+	orderV1.Get("/coverage/rules", coverageHandler.GetCoverageRules)
+	orderV1.Post("/coverage/rules", coverageHandler.CreateCoverageRule)
+	orderV1.Put("/coverage/rules/:id", coverageHandler.UpdateCoverageRule)
+	orderV1.Delete("/coverage/rules/:id", coverageHandler.DeleteCoverageRule)
+	orderV1.Get("/cancellation/rules", cancellationHandler.GetCancellationRules)
+	orderV1.Put("/cancellation/rules/:status", cancellationHandler.SaveCancellationRule)
+	orderV1.Delete("/cancellation/rules/:status", cancellationHandler.DeleteCancellationRule)
+	orderV1.Get("/webhooks", webhookHandler.GetWebhookSubscriptions)
+	orderV1.Post("/webhooks", webhookHandler.CreateWebhookSubscription)
+	orderV1.Post("/webhooks/deliveries/:deliveryId/replay", webhookHandler.ReplayWebhookDelivery)
+	orderV1.Put("/webhooks/:id", webhookHandler.UpdateWebhookSubscription)
+	orderV1.Delete("/webhooks/:id", webhookHandler.DeleteWebhookSubscription)
+	orderV1.Get("/webhooks/:id/deliveries", webhookHandler.GetWebhookDeliveries)
+	orderV1.Get("/notifications", notificationHandler.GetNotificationLog)
+	orderV1.Get("/notifications/preferences", notificationHandler.GetNotificationPreferences)
+	orderV1.Put("/notifications/preferences", notificationHandler.SaveNotificationPreferences)
+	orderV1.Get("/promotions", promotionHandler.GetPromotions)
+	orderV1.Post("/promotions", promotionHandler.CreatePromotion)
+	orderV1.Put("/promotions/:id", promotionHandler.UpdatePromotion)
+	orderV1.Delete("/promotions/:id", promotionHandler.DeletePromotion)
 
 	// Medicine Routes
 	medicine := api.Group("/medicine")
 	medicineV1 := medicine.Group("/v1")
-	medicineV1.Get("/medicines", medicineHandler.GetAllMedicines)
+	medicineV1.Get("/medicines", medicineHandler.SearchMedicines)
+	// static paths must be registered before /medicines/:id
+	medicineV1.Get("/medicines/low-stock", middleware.JwtMiddleware(jwtSvc), medicineHandler.GetLowStockMedicines)
+	medicineV1.Get("/medicines/categories", medicineHandler.GetTherapeuticCategories)
 	medicineV1.Get("/medicines/:id", medicineHandler.GetMedicineByID)
+	medicineV1.Put("/medicines/:id/reorder-levels", middleware.JwtMiddleware(jwtSvc), medicineHandler.UpdateReorderLevels)
+	medicineV1.Post("/medicines/:id/units", middleware.JwtMiddleware(jwtSvc), medicineHandler.UpsertMedicineUnit)
+	medicineV1.Get("/medicines/:id/prices", middleware.JwtMiddleware(jwtSvc), medicineHandler.GetPriceHistory)
+	medicineV1.Post("/medicines/:id/prices", middleware.JwtMiddleware(jwtSvc), medicineHandler.SchedulePriceChange)
+	medicineV1.Delete("/medicines/:id/prices/:priceId", middleware.JwtMiddleware(jwtSvc), medicineHandler.CancelPriceChange)
+	medicineV1.Get("/medicines/:id/movements", middleware.JwtMiddleware(jwtSvc), inventoryHandler.GetStockMovements)
+	medicineV1.Get("/batches/expiring", middleware.JwtMiddleware(jwtSvc), medicineHandler.GetExpiringBatches)
+
+	// Inventory Routes
+	stockV1 := medicineV1.Group("/stock", middleware.JwtMiddleware(jwtSvc))
+	stockV1.Post("/receipts", inventoryHandler.ReceiveStock)
+	stockV1.Post("/adjustments", inventoryHandler.AdjustStock)
+	stockV1.Get("/reconciliation", inventoryHandler.GetStockReconciliation)
+
+	// Catalog Routes
+	catalogV1 := medicineV1.Group("/catalog", middleware.JwtMiddleware(jwtSvc))
+	catalogV1.Post("/import", catalogHandler.ImportCatalog)
+	catalogV1.Get("/export", catalogHandler.ExportCatalog)
 
 	// Delivery Routes
 	delivery := api.Group("/delivery")
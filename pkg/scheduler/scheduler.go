@@ -0,0 +1,140 @@
+// Package scheduler runs background jobs on one replica at a time. Replicas compete for
+// a Postgres advisory lock; the one holding it is the leader and runs the jobs while the
+// others keep trying to take it over.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is work the leader runs immediately and then on every interval.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	db            *sql.DB
+	lockKey       int64
+	retryInterval time.Duration
+	jobs          []Job
+}
+
+// New returns a scheduler that leads while it holds the session advisory lock lockKey.
+// Followers try to take the lock, and the leader checks it still holds it, every
+// retryInterval.
+func New(db *sql.DB, lockKey int64, retryInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		db:            db,
+		lockKey:       lockKey,
+		retryInterval: retryInterval,
+	}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+// Start competes for leadership until ctx is done, running the jobs whenever this
+// replica leads.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.retryInterval)
+	defer ticker.Stop()
+	for {
+		conn, err := s.acquire(ctx)
+		if err != nil {
+			log.Printf("scheduler: failed to take leader lock: %v", err)
+		}
+		if conn != nil {
+			log.Printf("scheduler: leading, running %d jobs", len(s.jobs))
+			s.lead(ctx, conn)
+			log.Printf("scheduler: no longer leading")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// acquire tries to take the advisory lock on a connection of its own, since the lock
+// belongs to the database session. It returns nil when another replica holds the lock.
+func (s *Scheduler) acquire(ctx context.Context) (*sql.Conn, error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", s.lockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, nil
+	}
+	return conn, nil
+}
+
+// lead runs the jobs until ctx is done or the lock's session is lost, then releases the
+// lock and waits for the jobs to stop.
+func (s *Scheduler) lead(ctx context.Context, conn *sql.Conn) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			runJob(leaderCtx, job)
+		}(job)
+	}
+
+	ticker := time.NewTicker(s.retryInterval)
+	for leading := true; leading; {
+		select {
+		case <-ctx.Done():
+			leading = false
+		case <-ticker.C:
+			if err := conn.PingContext(ctx); err != nil {
+				log.Printf("scheduler: lost leader lock session: %v", err)
+				leading = false
+			}
+		}
+	}
+	ticker.Stop()
+	cancel()
+	wg.Wait()
+
+	// closing a sql.Conn returns it to the pool with the session still open, so when the
+	// unlock fails the connection is thrown away to end the session and its lock
+	unlockCtx, unlockCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unlockCancel()
+	if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", s.lockKey); err != nil {
+		log.Printf("scheduler: failed to release leader lock: %v", err)
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	conn.Close()
+}
+
+func runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %s failed: %v", job.Name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cancellableStatuses are the statuses patients may cancel orders in; the paid ones are
+// refunded less the cancellation fee.
+var cancellableStatuses = map[models.OrderStatus]bool{
+	models.OrderStatusPending:          false,
+	models.OrderStatusChangesRequested: false,
+	models.OrderStatusApproved:         false,
+	models.OrderStatusPaid:             true,
+	models.OrderStatusProcessing:       true,
+}
+
+// CancellationService manages the policy for patients cancelling their orders. Changes
+// apply to cancellations made afterwards.
+type CancellationService struct {
+	cancellationRuleRepository *repository.CancellationRuleRepository
+}
+
+func NewCancellationService(cancellationRuleRepo *repository.CancellationRuleRepository) *CancellationService {
+	return &CancellationService{
+		cancellationRuleRepository: cancellationRuleRepo,
+	}
+}
+
+func (s *CancellationService) GetCancellationRules(ctx context.Context) (*dto.GetCancellationRulesResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view cancellation rules", nil)
+	}
+	rules, err := s.cancellationRuleRepository.FindAll(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve cancellation rules", err)
+	}
+	res := &dto.GetCancellationRulesResponseDto{Rules: make([]dto.CancellationRuleDto, len(rules))}
+	for i := range rules {
+		res.Rules[i] = dto.ToCancellationRuleDto(&rules[i])
+	}
+	res.Total = len(res.Rules)
+	return res, nil
+}
+
+func (s *CancellationService) SaveCancellationRule(ctx context.Context, status string, body dto.CancellationRuleRequestDto) (*dto.CancellationRuleDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change cancellation rules", nil)
+	}
+	orderStatus := models.OrderStatus(status)
+	paid, ok := cancellableStatuses[orderStatus]
+	if !ok {
+		return nil, apperr.New(apperr.CodeBadRequest, "patients cannot cancel orders that are "+status, nil)
+	}
+	if body.WindowMinutes != nil && *body.WindowMinutes < 0 {
+		return nil, apperr.New(apperr.CodeBadRequest, "window_minutes cannot be negative", nil)
+	}
+	if body.FeePercent < 0 || body.FeePercent > 100 || body.FeeAmount < 0 {
+		return nil, apperr.New(apperr.CodeBadRequest, "fee_percent must be between 0 and 100 and fee_amount cannot be negative", nil)
+	}
+	if !paid && (body.FeePercent > 0 || body.FeeAmount > 0) {
+		return nil, apperr.New(apperr.CodeBadRequest, "fees only apply to orders that have been paid", nil)
+	}
+
+	rule := &models.CancellationRule{
+		Status:        orderStatus,
+		WindowMinutes: body.WindowMinutes,
+		FeePercent:    body.FeePercent,
+		FeeAmount:     body.FeeAmount,
+	}
+	if err := s.cancellationRuleRepository.Save(ctx, rule); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to save cancellation rule", err)
+	}
+	saved, err := s.cancellationRuleRepository.FindByStatus(ctx, orderStatus)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve cancellation rule", err)
+	}
+	res := dto.ToCancellationRuleDto(saved)
+	return &res, nil
+}
+
+func (s *CancellationService) DeleteCancellationRule(ctx context.Context, status string) (*dto.DeleteCancellationRuleResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change cancellation rules", nil)
+	}
+	orderStatus := models.OrderStatus(status)
+	if _, ok := cancellableStatuses[orderStatus]; !ok {
+		return nil, apperr.New(apperr.CodeNotFound, "cancellation rule not found", nil)
+	}
+	if _, err := s.cancellationRuleRepository.FindByStatus(ctx, orderStatus); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.New(apperr.CodeNotFound, "cancellation rule not found", err)
+		}
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve cancellation rule", err)
+	}
+	if err := s.cancellationRuleRepository.Delete(ctx, orderStatus); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to delete cancellation rule", err)
+	}
+	return &dto.DeleteCancellationRuleResponseDto{
+		OrderStatus: status,
+		Status:      "deleted",
+	}, nil
+}
+
+// checkCancellationPolicy checks that a patient may still cancel the order under the rule
+// for its status and returns the fee kept from their payment. Paid orders can only be
+// cancelled until they are dispatched.
+func (s *OrderService) checkCancellationPolicy(ctx context.Context, tx *gorm.DB, order *models.Order, now time.Time) (float64, error) {
+	paid := cancellableStatuses[order.Status]
+	var patientPayment *models.Payment
+	if paid {
+		delivery, err := repository.NewDeliveryRepository(tx).FindByOrderID(ctx, order.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, apperr.New(apperr.CodeInternal, "failed to retrieve delivery", err)
+		}
+		if delivery != nil && delivery.Status != models.DeliveryStatusPending {
+			return 0, apperr.New(apperr.CodeConflict, "order has already been dispatched", nil)
+		}
+		orderPayments, err := repository.NewPaymentRepository(tx).FindByOrderID(ctx, order.ID)
+		if err != nil {
+			return 0, apperr.New(apperr.CodeInternal, "failed to retrieve payments", err)
+		}
+		for i := range orderPayments {
+			if orderPayments[i].Payer == models.PaymentPayerPatient && orderPayments[i].Status == models.PaymentStatusCaptured {
+				patientPayment = &orderPayments[i]
+			}
+		}
+		if patientPayment == nil {
+			return 0, apperr.New(apperr.CodeConflict, "order has no captured payment to refund", nil)
+		}
+	}
+
+	rule, err := repository.NewCancellationRuleRepository(tx).FindByStatus(ctx, order.Status)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, apperr.New(apperr.CodeInternal, "failed to retrieve cancellation rule", err)
+	}
+
+	if rule.WindowMinutes != nil {
+		// the window runs from when the order reached its status
+		since := order.CreatedAt
+		switch {
+		case patientPayment != nil:
+			since = patientPayment.CreatedAt
+		case order.Status == models.OrderStatusApproved && order.ReviewedAt != nil:
+			since = *order.ReviewedAt
+		case order.SubmittedAt != nil:
+			since = *order.SubmittedAt
+		}
+		if now.After(since.Add(time.Duration(*rule.WindowMinutes) * time.Minute)) {
+			return 0, apperr.New(apperr.CodeConflict, fmt.Sprintf("orders that are %s can only be cancelled within %d minutes", order.Status, *rule.WindowMinutes), nil)
+		}
+	}
+	if !paid {
+		return 0, nil
+	}
+	return rule.Fee(patientPayment.Amount), nil
+}
@@ -0,0 +1,398 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"order-service/pkg/apperr"
+	"order-service/pkg/catalog"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const catalogImportReason = "catalog import"
+
+type CatalogService struct {
+	db                 *gorm.DB
+	medicineRepository *repository.MedicineRepository
+	categoryRepository *repository.TherapeuticCategoryRepository
+	priceRepository    *repository.MedicinePriceRepository
+}
+
+func NewCatalogService(
+	db *gorm.DB,
+	medicineRepo *repository.MedicineRepository,
+	categoryRepo *repository.TherapeuticCategoryRepository,
+	priceRepo *repository.MedicinePriceRepository,
+) *CatalogService {
+	return &CatalogService{
+		db:                 db,
+		medicineRepository: medicineRepo,
+		categoryRepository: categoryRepo,
+		priceRepository:    priceRepo,
+	}
+}
+
+// catalogChange is a validated row together with the medicine it updates, if any, and
+// the column values that differ.
+type catalogChange struct {
+	row      catalog.Row
+	medicine *models.Medicine
+	fields   map[string]interface{}
+	changes  []dto.CatalogFieldChangeDto
+	price    bool
+}
+
+// UploadCatalog imports an uploaded catalog file (admin only).
+func (s *CatalogService) UploadCatalog(ctx context.Context, filename string, r io.Reader, dryRun bool) (*dto.CatalogImportResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can import the catalog", nil)
+	}
+	format, err := catalog.FormatFromFilename(filename)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, err.Error(), nil)
+	}
+	return s.ImportCatalog(ctx, format, r, dryRun)
+}
+
+// ImportCatalog creates or updates medicines from a catalog file, matching them by SKU.
+// Every row is validated and compared with the current catalog first; the changes are
+// only applied, all in one transaction, when no row has an error and dryRun is false.
+// Price changes take effect immediately and are recorded in the price history. Stock is
+// not part of the catalog and is received through stock receipts.
+func (s *CatalogService) ImportCatalog(ctx context.Context, format catalog.Format, r io.Reader, dryRun bool) (*dto.CatalogImportResponseDto, error) {
+	rows, rowErrors, err := catalog.Read(r, format)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid catalog file: "+err.Error(), nil)
+	}
+
+	skus := make([]string, len(rows))
+	for i := range rows {
+		skus[i] = rows[i].SKU
+	}
+	existing, err := s.medicineRepository.FindBySKUs(ctx, skus)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve medicines", err)
+	}
+	existingPtrs := make([]*models.Medicine, len(existing))
+	bySKU := make(map[string]*models.Medicine, len(existing))
+	for i := range existing {
+		existingPtrs[i] = &existing[i]
+		bySKU[existing[i].SKU] = &existing[i]
+	}
+	now := time.Now()
+	if err := applyEffectiveMedicinePrices(ctx, s.priceRepository, existingPtrs, now); err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve categories", err)
+	}
+	categoryCodes := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		categoryCodes[category.Code] = true
+	}
+
+	res := &dto.CatalogImportResponseDto{
+		DryRun: dryRun,
+		Rows:   []dto.CatalogRowResultDto{},
+		Errors: append([]catalog.RowError{}, rowErrors...),
+	}
+	var changes []*catalogChange
+	seen := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if line, ok := seen[row.SKU]; ok {
+			res.Errors = append(res.Errors, rowError(row, catalog.ColumnSKU, fmt.Sprintf("sku is also used on line %d", line)))
+			continue
+		}
+		seen[row.SKU] = row.Line
+
+		if errs := validateCatalogRow(row, bySKU[row.SKU], categoryCodes); len(errs) > 0 {
+			res.Errors = append(res.Errors, errs...)
+			continue
+		}
+		change := diffCatalogRow(row, bySKU[row.SKU])
+		changes = append(changes, change)
+
+		result := dto.CatalogRowResultDto{
+			Line:    row.Line,
+			SKU:     row.SKU,
+			Action:  dto.CatalogActionUpdate,
+			Changes: change.changes,
+		}
+		switch {
+		case change.medicine == nil:
+			result.Action = dto.CatalogActionCreate
+			res.Summary.Created++
+		case len(change.changes) == 0:
+			result.Action = dto.CatalogActionUnchanged
+			res.Summary.Unchanged++
+		default:
+			res.Summary.Updated++
+		}
+		res.Rows = append(res.Rows, result)
+	}
+	failedLines := make(map[int]bool, len(res.Errors))
+	for _, rowErr := range res.Errors {
+		failedLines[rowErr.Line] = true
+	}
+	res.Summary.Errors = len(failedLines)
+	res.Summary.Rows = len(res.Rows) + res.Summary.Errors
+
+	if dryRun || len(res.Errors) > 0 {
+		return res, nil
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			if err := applyCatalogChange(ctx, tx, change, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.Applied = true
+	return res, nil
+}
+
+// DownloadCatalog writes the catalog in the given format (admin only).
+func (s *CatalogService) DownloadCatalog(ctx context.Context, w io.Writer, format string) error {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return apperr.New(apperr.CodeForbidden, "only admins can export the catalog", nil)
+	}
+	parsed, err := catalog.ParseFormat(format)
+	if err != nil {
+		return apperr.New(apperr.CodeBadRequest, err.Error(), nil)
+	}
+	return s.ExportCatalog(ctx, w, parsed)
+}
+
+// ExportCatalog writes every medicine that is not deleted, priced at the current base
+// unit price, in the same layout ImportCatalog reads.
+func (s *CatalogService) ExportCatalog(ctx context.Context, w io.Writer, format catalog.Format) error {
+	medicines, err := s.medicineRepository.FindAllWithUnits(ctx)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve medicines", err)
+	}
+	medicinePtrs := make([]*models.Medicine, len(medicines))
+	for i := range medicines {
+		medicinePtrs[i] = &medicines[i]
+	}
+	if err := applyEffectiveMedicinePrices(ctx, s.priceRepository, medicinePtrs, time.Now()); err != nil {
+		return err
+	}
+
+	rows := make([]catalog.Row, len(medicines))
+	for i := range medicines {
+		medicine := &medicines[i]
+		rows[i] = catalog.Row{
+			SKU:                 medicine.SKU,
+			Name:                medicine.Name,
+			GenericName:         medicine.GenericName,
+			BrandName:           medicine.BrandName,
+			NameTh:              medicine.NameTh,
+			ATCCode:             medicine.ATCCode,
+			StrengthValue:       medicine.StrengthValue,
+			StrengthUnit:        medicine.StrengthUnit,
+			Unit:                medicine.Unit,
+			Price:               medicine.Price,
+			Classification:      string(medicine.Classification),
+			VATTreatment:        string(medicine.VATTreatment),
+			MaxQuantityPerOrder: medicine.MaxQuantityPerOrder,
+			ReorderPoint:        medicine.ReorderPoint,
+			ReorderQuantity:     medicine.ReorderQuantity,
+		}
+	}
+	if err := catalog.Write(w, format, rows); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to write catalog", err)
+	}
+	return nil
+}
+
+func validateCatalogRow(row catalog.Row, medicine *models.Medicine, categoryCodes map[string]bool) []catalog.RowError {
+	var errs []catalog.RowError
+	if medicine != nil && medicine.DeletedAt.Valid {
+		errs = append(errs, rowError(row, catalog.ColumnSKU, "sku belongs to a deleted medicine"))
+	}
+	if medicine != nil && medicine.Unit != row.Unit {
+		errs = append(errs, rowError(row, catalog.ColumnUnit, fmt.Sprintf("unit cannot be changed from %s by an import", medicine.Unit)))
+	}
+	if row.Classification != "" && !models.DrugClassification(row.Classification).IsValid() {
+		errs = append(errs, rowError(row, catalog.ColumnClassification, "classification must be one of otc, pharmacy_only, prescription_only, controlled"))
+	}
+	if row.VATTreatment != "" && !models.VATTreatment(row.VATTreatment).IsValid() {
+		errs = append(errs, rowError(row, catalog.ColumnVATTreatment, "vat_treatment must be one of standard, exempt"))
+	}
+	if row.ATCCode != nil && !categoryCodes[*row.ATCCode] {
+		errs = append(errs, rowError(row, catalog.ColumnATCCode, "atc_code is not a known category"))
+	}
+	if row.ReorderQuantity != nil && *row.ReorderQuantity == 0 {
+		errs = append(errs, rowError(row, catalog.ColumnReorderQuantity, "reorder_quantity must be greater than 0"))
+	}
+	if row.Has(catalog.ColumnStrengthValue) && row.Has(catalog.ColumnStrengthUnit) && (row.StrengthValue == nil) != (row.StrengthUnit == nil) {
+		errs = append(errs, rowError(row, catalog.ColumnStrengthUnit, "strength_value and strength_unit must be given together"))
+	}
+	return errs
+}
+
+func rowError(row catalog.Row, column, message string) catalog.RowError {
+	return catalog.RowError{Line: row.Line, SKU: row.SKU, Column: column, Message: message}
+}
+
+// diffCatalogRow lists the columns the row changes. Columns the file does not have are
+// left alone, and an empty classification or VAT treatment keeps the current one.
+func diffCatalogRow(row catalog.Row, medicine *models.Medicine) *catalogChange {
+	change := &catalogChange{row: row, medicine: medicine, fields: map[string]interface{}{}}
+	current := &models.Medicine{}
+	if medicine != nil {
+		current = medicine
+	}
+
+	setText := func(column string, from string, to string) {
+		if from != to {
+			change.fields[column] = to
+			change.changes = append(change.changes, dto.CatalogFieldChangeDto{Field: column, From: from, To: to})
+		}
+	}
+	setOptionalText := func(column string, from, to *string) {
+		if row.Has(column) && !equalText(from, to) {
+			change.fields[column] = to
+			change.changes = append(change.changes, dto.CatalogFieldChangeDto{Field: column, From: from, To: to})
+		}
+	}
+	setOptionalNumber := func(column string, from, to *float64) {
+		if row.Has(column) && !equalNumber(from, to) {
+			change.fields[column] = to
+			change.changes = append(change.changes, dto.CatalogFieldChangeDto{Field: column, From: from, To: to})
+		}
+	}
+
+	setText(catalog.ColumnName, current.Name, row.Name)
+	setOptionalText(catalog.ColumnGenericName, current.GenericName, row.GenericName)
+	setOptionalText(catalog.ColumnBrandName, current.BrandName, row.BrandName)
+	setOptionalText(catalog.ColumnNameTh, current.NameTh, row.NameTh)
+	setOptionalText(catalog.ColumnATCCode, current.ATCCode, row.ATCCode)
+	setOptionalNumber(catalog.ColumnStrengthValue, current.StrengthValue, row.StrengthValue)
+	setOptionalText(catalog.ColumnStrengthUnit, current.StrengthUnit, row.StrengthUnit)
+	if medicine == nil {
+		setText(catalog.ColumnUnit, "", row.Unit)
+	}
+	if row.Classification != "" {
+		setText(catalog.ColumnClassification, string(current.Classification), row.Classification)
+	}
+	if row.VATTreatment != "" {
+		setText(catalog.ColumnVATTreatment, string(current.VATTreatment), row.VATTreatment)
+	}
+	setOptionalNumber(catalog.ColumnMaxQuantityPerOrder, current.MaxQuantityPerOrder, row.MaxQuantityPerOrder)
+	setOptionalNumber(catalog.ColumnReorderPoint, current.ReorderPoint, row.ReorderPoint)
+	setOptionalNumber(catalog.ColumnReorderQuantity, current.ReorderQuantity, row.ReorderQuantity)
+
+	if medicine == nil {
+		change.price = true
+		change.changes = append(change.changes, dto.CatalogFieldChangeDto{Field: catalog.ColumnPrice, To: row.Price})
+	} else if current.Price != row.Price {
+		change.price = true
+		change.changes = append(change.changes, dto.CatalogFieldChangeDto{Field: catalog.ColumnPrice, From: current.Price, To: row.Price})
+	}
+	return change
+}
+
+func applyCatalogChange(ctx context.Context, tx *gorm.DB, change *catalogChange, now time.Time) error {
+	row := change.row
+	medicineRepository := repository.NewMedicineRepository(tx)
+	unitRepository := repository.NewMedicineUnitRepository(tx)
+	reason := catalogImportReason
+
+	if change.medicine == nil {
+		classification := models.DrugClassificationPrescriptionOnly
+		if row.Classification != "" {
+			classification = models.DrugClassification(row.Classification)
+		}
+		vatTreatment := models.VATTreatmentStandard
+		if row.VATTreatment != "" {
+			vatTreatment = models.VATTreatment(row.VATTreatment)
+		}
+		medicine := &models.Medicine{
+			ID:                  utils.GenerateUUIDv7(),
+			SKU:                 row.SKU,
+			Name:                row.Name,
+			GenericName:         row.GenericName,
+			BrandName:           row.BrandName,
+			NameTh:              row.NameTh,
+			ATCCode:             row.ATCCode,
+			StrengthValue:       row.StrengthValue,
+			StrengthUnit:        row.StrengthUnit,
+			Price:               row.Price,
+			Unit:                row.Unit,
+			Classification:      classification,
+			VATTreatment:        vatTreatment,
+			MaxQuantityPerOrder: row.MaxQuantityPerOrder,
+			ReorderPoint:        row.ReorderPoint,
+			ReorderQuantity:     row.ReorderQuantity,
+		}
+		if err := medicineRepository.Create(ctx, medicine); err != nil {
+			return apperr.New(apperr.CodeInternal, fmt.Sprintf("line %d: failed to create medicine", row.Line), err)
+		}
+		unit := &models.MedicineUnit{
+			ID:         utils.GenerateUUIDv7(),
+			MedicineID: medicine.ID,
+			Name:       row.Unit,
+			Factor:     1,
+			Price:      row.Price,
+			IsBase:     true,
+		}
+		if err := unitRepository.Create(ctx, unit); err != nil {
+			return apperr.New(apperr.CodeInternal, fmt.Sprintf("line %d: failed to create medicine unit", row.Line), err)
+		}
+		return recordPriceChange(ctx, tx, unit, &models.MedicinePrice{
+			Price:         row.Price,
+			EffectiveFrom: now,
+			Reason:        &reason,
+		}, now)
+	}
+
+	if len(change.fields) > 0 {
+		if err := medicineRepository.UpdateFields(ctx, change.medicine.ID, change.fields); err != nil {
+			return apperr.New(apperr.CodeInternal, fmt.Sprintf("line %d: failed to update medicine", row.Line), err)
+		}
+	}
+	if !change.price {
+		return nil
+	}
+	base, err := unitRepository.FindBaseByMedicineID(ctx, change.medicine.ID)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, fmt.Sprintf("line %d: medicine has no base unit", row.Line), err)
+	}
+	if base, err = unitRepository.FindByIDForUpdate(ctx, base.ID); err != nil {
+		return apperr.New(apperr.CodeInternal, fmt.Sprintf("line %d: failed to lock medicine unit", row.Line), err)
+	}
+	return recordPriceChange(ctx, tx, base, &models.MedicinePrice{
+		Price:         row.Price,
+		EffectiveFrom: now,
+		Reason:        &reason,
+	}, now)
+}
+
+func equalText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalNumber(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
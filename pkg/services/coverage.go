@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"log"
+	"order-service/pkg/apperr"
+	"order-service/pkg/coverage"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// patientEntitlements returns the healthcare entitlements of a patient. When the user
+// service cannot be reached the order is not covered and the patient pays in full.
+func (s *OrderService) patientEntitlements(ctx context.Context, patientID uuid.UUID) []string {
+	record, err := s.userClient.GetPatientEntitlements(ctx, patientID.String())
+	if err != nil {
+		log.Printf("failed to retrieve entitlements for patient %s: %v", patientID, err)
+		return nil
+	}
+	return record.HealthcareEntitlements
+}
+
+// applyCoverage splits the total of an order that is being approved between the
+// patient's best entitlement and the patient, and records the split of each item.
+// Items are covered at their price after the order's discounts.
+func applyCoverage(ctx context.Context, tx *gorm.DB, order *models.Order, entitlements []string, now time.Time) error {
+	coverageLineRepository := repository.NewOrderCoverageLineRepository(tx)
+
+	var rules []models.CoverageRule
+	used := map[uuid.UUID]float64{}
+	if len(entitlements) > 0 {
+		if err := coverageLineRepository.LockPatient(ctx, order.PatientID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to lock patient coverage", err)
+		}
+		var err error
+		rules, err = repository.NewCoverageRuleRepository(tx).FindByEntitlements(ctx, entitlements)
+		if err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve coverage rules", err)
+		}
+		ruleIDs := make([]uuid.UUID, len(rules))
+		for i, rule := range rules {
+			ruleIDs[i] = rule.ID
+		}
+		yearStart := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+		used, err = coverageLineRepository.SumInsurerAmounts(ctx, order.PatientID, ruleIDs, yearStart)
+		if err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve coverage used this year", err)
+		}
+	}
+
+	result := coverage.Best(entitlements, coverage.Items(order.OrderItems, order.Discounts), rules, used)
+
+	if err := coverageLineRepository.DeleteByOrderID(ctx, order.ID); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to clear order coverage", err)
+	}
+	var entitlement *string
+	if result.Entitlement != "" {
+		entitlement = &result.Entitlement
+	}
+	for _, line := range result.Lines {
+		if err := coverageLineRepository.Create(ctx, &models.OrderCoverageLine{
+			ID:            utils.GenerateUUIDv7(),
+			OrderID:       order.ID,
+			OrderItemID:   line.OrderItemID,
+			Entitlement:   entitlement,
+			RuleID:        line.RuleID,
+			LineTotal:     line.LineTotal,
+			InsurerAmount: line.InsurerAmount,
+			PatientAmount: line.PatientAmount,
+		}); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to record order coverage", err)
+		}
+	}
+
+	order.Entitlement = entitlement
+	order.InsurerAmount = result.InsurerAmount
+	order.PatientAmount = result.PatientAmount
+	if err := repository.NewOrderRepository(tx).UpdateCoverage(ctx, order); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to save order coverage", err)
+	}
+	return nil
+}
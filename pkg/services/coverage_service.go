@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CoverageService manages the coverage rules of healthcare entitlements. Changes to a
+// rule apply to orders approved afterwards; approved orders keep their split.
+type CoverageService struct {
+	coverageRuleRepository *repository.CoverageRuleRepository
+	medicineRepository     *repository.MedicineRepository
+}
+
+func NewCoverageService(coverageRuleRepo *repository.CoverageRuleRepository, medicineRepo *repository.MedicineRepository) *CoverageService {
+	return &CoverageService{
+		coverageRuleRepository: coverageRuleRepo,
+		medicineRepository:     medicineRepo,
+	}
+}
+
+func (s *CoverageService) GetCoverageRules(ctx context.Context, entitlement string) (*dto.GetCoverageRulesResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view coverage rules", nil)
+	}
+	rules, err := s.coverageRuleRepository.FindAll(ctx, strings.TrimSpace(entitlement))
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve coverage rules", err)
+	}
+	res := &dto.GetCoverageRulesResponseDto{Rules: make([]dto.CoverageRuleDto, len(rules))}
+	for i := range rules {
+		res.Rules[i] = dto.ToCoverageRuleDto(&rules[i])
+	}
+	res.Total = len(res.Rules)
+	return res, nil
+}
+
+func (s *CoverageService) CreateCoverageRule(ctx context.Context, body dto.CoverageRuleRequestDto) (*dto.CoverageRuleDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change coverage rules", nil)
+	}
+	entitlement := strings.TrimSpace(body.Entitlement)
+	if entitlement == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "entitlement is required", nil)
+	}
+	if body.MedicineID != nil {
+		if _, err := s.medicineRepository.FindByID(ctx, *body.MedicineID); err != nil {
+			return nil, apperr.New(apperr.CodeNotFound, "medicine not found", err)
+		}
+	}
+	_, err := s.coverageRuleRepository.FindByEntitlementAndMedicine(ctx, entitlement, body.MedicineID)
+	if err == nil {
+		return nil, apperr.New(apperr.CodeConflict, "a coverage rule already exists for this entitlement and medicine", nil)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve coverage rules", err)
+	}
+
+	rule := &models.CoverageRule{
+		ID:             utils.GenerateUUIDv7(),
+		Entitlement:    entitlement,
+		MedicineID:     body.MedicineID,
+		CoveredPercent: body.CoveredPercent,
+		Copay:          body.Copay,
+		Excluded:       body.Excluded,
+		AnnualCap:      body.AnnualCap,
+	}
+	if err := s.coverageRuleRepository.Create(ctx, rule); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to create coverage rule", err)
+	}
+	return s.getCoverageRule(ctx, rule.ID)
+}
+
+func (s *CoverageService) UpdateCoverageRule(ctx context.Context, ruleID string, body dto.UpdateCoverageRuleRequestDto) (*dto.CoverageRuleDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change coverage rules", nil)
+	}
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid coverage rule ID", err)
+	}
+	rule, err := s.coverageRuleRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "coverage rule not found", err)
+	}
+
+	rule.CoveredPercent = body.CoveredPercent
+	rule.Copay = body.Copay
+	rule.Excluded = body.Excluded
+	rule.AnnualCap = body.AnnualCap
+	if err := s.coverageRuleRepository.Update(ctx, rule); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to update coverage rule", err)
+	}
+	return s.getCoverageRule(ctx, rule.ID)
+}
+
+func (s *CoverageService) DeleteCoverageRule(ctx context.Context, ruleID string) (*dto.DeleteCoverageRuleResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change coverage rules", nil)
+	}
+	id, err := uuid.Parse(ruleID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid coverage rule ID", err)
+	}
+	if _, err := s.coverageRuleRepository.FindByID(ctx, id); err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "coverage rule not found", err)
+	}
+	if err := s.coverageRuleRepository.Delete(ctx, id); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to delete coverage rule", err)
+	}
+	return &dto.DeleteCoverageRuleResponseDto{
+		RuleID: id.String(),
+		Status: "deleted",
+	}, nil
+}
+
+func (s *CoverageService) getCoverageRule(ctx context.Context, id uuid.UUID) (*dto.CoverageRuleDto, error) {
+	rule, err := s.coverageRuleRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve coverage rule", err)
+	}
+	res := dto.ToCoverageRuleDto(rule)
+	return &res, nil
+}
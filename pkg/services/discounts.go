@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	"order-service/pkg/models"
+	"order-service/pkg/promotion"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// applyDiscounts works out the discounts of an approved order from the automatic
+// promotions and the order's coupon, replaces the discount lines recorded on it and
+// saves its subtotal, discount and total. A coupon that has reached its usage limit is
+// an error; automatic promotions that have are skipped.
+func applyDiscounts(ctx context.Context, tx *gorm.DB, order *models.Order, now time.Time) (*promotion.Result, error) {
+	promotionRepository := repository.NewPromotionRepository(tx)
+	candidates, err := promotionRepository.FindApplicable(ctx, order.CouponCode, now)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve promotions", err)
+	}
+
+	promotions := make([]models.Promotion, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.UsageLimit != nil || candidate.PerPatientLimit != nil {
+			uses, patientUses, err := promotionRepository.CountUses(ctx, candidate.ID, order.PatientID, order.ID)
+			if err != nil {
+				return nil, apperr.New(apperr.CodeInternal, "failed to count promotion uses", err)
+			}
+			exhausted := (candidate.UsageLimit != nil && uses >= int64(*candidate.UsageLimit)) ||
+				(candidate.PerPatientLimit != nil && patientUses >= int64(*candidate.PerPatientLimit))
+			if exhausted {
+				if candidate.CouponCode != nil {
+					return nil, apperr.New(apperr.CodeConflict, "coupon has reached its usage limit", nil)
+				}
+				continue
+			}
+		}
+		promotions = append(promotions, candidate)
+	}
+
+	result := promotion.Apply(order.OrderItems, promotions, now)
+
+	discountRepository := repository.NewOrderDiscountRepository(tx)
+	if err := discountRepository.DeleteByOrderID(ctx, order.ID); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to clear order discounts", err)
+	}
+	order.Discounts = make([]models.OrderDiscount, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		promotionID := line.Promotion.ID
+		discount := models.OrderDiscount{
+			ID:            utils.GenerateUUIDv7(),
+			OrderID:       order.ID,
+			OrderItemID:   line.OrderItemID,
+			PromotionID:   &promotionID,
+			PromotionName: line.Promotion.Name,
+			CouponCode:    line.Promotion.CouponCode,
+			Amount:        line.Amount,
+		}
+		if err := discountRepository.Create(ctx, &discount); err != nil {
+			return nil, apperr.New(apperr.CodeInternal, "failed to record order discount", err)
+		}
+		order.Discounts = append(order.Discounts, discount)
+	}
+
+	order.SubtotalAmount = result.Subtotal
+	order.DiscountAmount = result.Discount
+	order.TotalAmount = result.Total
+	if err := repository.NewOrderRepository(tx).UpdateTotals(ctx, order); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to save order totals", err)
+	}
+	return &result, nil
+}
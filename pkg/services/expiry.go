@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"log"
+	"order-service/pkg/apperr"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ExpiredOrder is an order the expiry job expired and why.
+type ExpiredOrder struct {
+	Order  models.Order
+	Reason string
+}
+
+// ExpireStaleOrders expires pending orders submitted before pendingBefore that no doctor
+// has reviewed, approved orders reviewed before approvedBefore that were never paid,
+// releasing the stock held for them, and orders sent back to the patient before
+// changesRequestedBefore that were never resubmitted. It returns the orders it expired.
+// An order that fails to expire is logged and tried again on the next run.
+func (s *OrderService) ExpireStaleOrders(ctx context.Context, pendingBefore, approvedBefore, changesRequestedBefore time.Time) ([]ExpiredOrder, error) {
+	stale := []struct {
+		status models.OrderStatus
+		before time.Time
+		reason string
+	}{
+		{models.OrderStatusPending, pendingBefore, "not reviewed by a doctor in time"},
+		{models.OrderStatusApproved, approvedBefore, "not paid in time"},
+		{models.OrderStatusChangesRequested, changesRequestedBefore, "not resubmitted by the patient in time"},
+	}
+
+	var expired []ExpiredOrder
+	for _, group := range stale {
+		ids, err := s.orderRepository.FindIDsWaitingBefore(ctx, group.status, group.before)
+		if err != nil {
+			return expired, apperr.New(apperr.CodeInternal, "failed to retrieve stale orders", err)
+		}
+		for _, id := range ids {
+			order, err := s.expireOrder(ctx, id, group.status, group.before, group.reason)
+			if err != nil {
+				log.Printf("failed to expire order %s: %v", id, err)
+				continue
+			}
+			if order != nil {
+				expired = append(expired, ExpiredOrder{Order: *order, Reason: group.reason})
+			}
+		}
+	}
+	return expired, nil
+}
+
+// expireOrder expires one order if it is still waiting in the status it was found in.
+// It returns nil when the order has moved on in the meantime.
+func (s *OrderService) expireOrder(ctx context.Context, orderID uuid.UUID, status models.OrderStatus, before time.Time, reason string) (*models.Order, error) {
+	var order *models.Order
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, orderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		// the latest of the submission and the review: a resubmitted order waits from its
+		// resubmission, one sent back to the patient from the change request
+		waitingSince := order.CreatedAt
+		if order.SubmittedAt != nil && order.SubmittedAt.After(waitingSince) {
+			waitingSince = *order.SubmittedAt
+		}
+		if order.ReviewedAt != nil && order.ReviewedAt.After(waitingSince) {
+			waitingSince = *order.ReviewedAt
+		}
+		if order.Status != status || !waitingSince.Before(before) {
+			order = nil
+			return nil
+		}
+		if err := checkTransition(order, models.OrderStatusExpired); err != nil {
+			return err
+		}
+
+		if order.Status == models.OrderStatusApproved {
+			if err := releaseOrderItems(ctx, tx, order, "order expired"); err != nil {
+				return err
+			}
+		}
+		order.Status = models.OrderStatusExpired
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to expire order", err)
+		}
+		return recordStatusChange(ctx, tx, order, &status, &reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return order, nil
+}
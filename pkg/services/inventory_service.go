@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"math"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const defaultStockMovementLimit = 100
+
+type InventoryService struct {
+	db                      *gorm.DB
+	medicineRepository      *repository.MedicineRepository
+	batchRepository         *repository.MedicineBatchRepository
+	stockMovementRepository *repository.StockMovementRepository
+}
+
+func NewInventoryService(
+	db *gorm.DB,
+	medicineRepo *repository.MedicineRepository,
+	batchRepo *repository.MedicineBatchRepository,
+	stockMovementRepo *repository.StockMovementRepository,
+) *InventoryService {
+	return &InventoryService{
+		db:                      db,
+		medicineRepository:      medicineRepo,
+		batchRepository:         batchRepo,
+		stockMovementRepository: stockMovementRepo,
+	}
+}
+
+// ReceiveStock books a goods receipt into a lot, creating the lot on first delivery.
+func (s *InventoryService) ReceiveStock(ctx context.Context, body dto.ReceiveStockRequestDto) (*dto.ReceiveStockResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can receive stock", nil)
+	}
+
+	var expiryDate *time.Time
+	if body.ExpiryDate != nil {
+		parsed, err := time.Parse("2006-01-02", *body.ExpiryDate)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid expiry date", err)
+		}
+		expiryDate = &parsed
+	}
+	reason := "goods receipt"
+	if body.Reason != nil && *body.Reason != "" {
+		reason = *body.Reason
+	}
+
+	var batch *models.MedicineBatch
+	var medicine *models.Medicine
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		medicineRepository := repository.NewMedicineRepository(tx)
+		batchRepository := repository.NewMedicineBatchRepository(tx)
+
+		if _, err := medicineRepository.FindByIDForUpdate(ctx, body.MedicineID); err != nil {
+			return apperr.New(apperr.CodeNotFound, "medicine not found", err)
+		}
+
+		existing, err := batchRepository.FindByLotForUpdate(ctx, body.MedicineID, body.LotNumber)
+		switch {
+		case err == nil:
+			if !sameDate(existing.ExpiryDate, expiryDate) {
+				return apperr.New(apperr.CodeConflict, "lot already exists with a different expiry date", nil)
+			}
+			if err := batchRepository.AddReceived(ctx, existing.ID, body.Quantity); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to update medicine batch", err)
+			}
+			batch = existing
+		case err == gorm.ErrRecordNotFound:
+			batch = &models.MedicineBatch{
+				ID:               utils.GenerateUUIDv7(),
+				MedicineID:       body.MedicineID,
+				LotNumber:        body.LotNumber,
+				ExpiryDate:       expiryDate,
+				ReceivedQuantity: body.Quantity,
+				Quantity:         body.Quantity,
+				ReceivedAt:       time.Now(),
+			}
+			if err := batchRepository.Create(ctx, batch); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to create medicine batch", err)
+			}
+		default:
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine batch", err)
+		}
+
+		batchID := batch.ID
+		if err := recordStockMovement(ctx, tx, &models.StockMovement{
+			MedicineID: body.MedicineID,
+			BatchID:    &batchID,
+			Type:       models.StockMovementReceive,
+			StockDelta: body.Quantity,
+			Reason:     reason,
+		}); err != nil {
+			return err
+		}
+
+		if batch, err = batchRepository.FindByID(ctx, batchID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine batch", err)
+		}
+		if medicine, err = medicineRepository.FindByID(ctx, body.MedicineID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ReceiveStockResponseDto{
+		Batch:         dto.ToMedicineBatchDto(batch, today()),
+		MedicineStock: medicine.Stock,
+	}, nil
+}
+
+// AdjustStock corrects a lot to the quantity found in a physical stock count.
+func (s *InventoryService) AdjustStock(ctx context.Context, body dto.StockAdjustmentRequestDto) (*dto.StockAdjustmentResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can adjust stock", nil)
+	}
+
+	var batch *models.MedicineBatch
+	var medicine *models.Medicine
+	var delta float64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		medicineRepository := repository.NewMedicineRepository(tx)
+		batchRepository := repository.NewMedicineBatchRepository(tx)
+
+		locked, err := batchRepository.FindByIDForUpdate(ctx, body.BatchID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "medicine batch not found", err)
+		}
+		delta = body.CountedQuantity - locked.Quantity
+		if math.Abs(delta) >= stockEpsilon {
+			if err := batchRepository.AddQuantity(ctx, locked.ID, delta); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to update medicine batch", err)
+			}
+			batchID := locked.ID
+			if err := recordStockMovement(ctx, tx, &models.StockMovement{
+				MedicineID: locked.MedicineID,
+				BatchID:    &batchID,
+				Type:       models.StockMovementAdjust,
+				StockDelta: delta,
+				Reason:     body.Reason,
+			}); err != nil {
+				return err
+			}
+		}
+
+		if batch, err = batchRepository.FindByID(ctx, locked.ID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine batch", err)
+		}
+		if medicine, err = medicineRepository.FindByID(ctx, locked.MedicineID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.StockAdjustmentResponseDto{
+		Batch:         dto.ToMedicineBatchDto(batch, today()),
+		Delta:         delta,
+		MedicineStock: medicine.Stock,
+	}, nil
+}
+
+func (s *InventoryService) GetStockMovements(ctx context.Context, medicineID string, limit int) (*dto.GetStockMovementsResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view stock movements", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+	if limit <= 0 {
+		limit = defaultStockMovementLimit
+	}
+
+	movements, err := s.stockMovementRepository.FindByMedicineID(ctx, id, limit)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve stock movements", err)
+	}
+
+	movementList := make([]dto.StockMovementResponseDto, len(movements))
+	for i, movement := range movements {
+		item := dto.StockMovementResponseDto{
+			ID:            movement.ID.String(),
+			MedicineID:    movement.MedicineID.String(),
+			MovementType:  string(movement.Type),
+			StockDelta:    movement.StockDelta,
+			ReservedDelta: movement.ReservedDelta,
+			ActorRole:     movement.ActorRole,
+			Reason:        movement.Reason,
+			CreatedAt:     movement.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+		if movement.BatchID != nil {
+			batchID := movement.BatchID.String()
+			item.BatchID = &batchID
+		}
+		if movement.Batch != nil {
+			item.LotNumber = &movement.Batch.LotNumber
+		}
+		if movement.OrderID != nil {
+			orderID := movement.OrderID.String()
+			item.OrderID = &orderID
+		}
+		if movement.ActorID != nil {
+			actorID := movement.ActorID.String()
+			item.ActorID = &actorID
+		}
+		movementList[i] = item
+	}
+
+	return &dto.GetStockMovementsResponseDto{
+		Movements: movementList,
+		Total:     len(movementList),
+	}, nil
+}
+
+func (s *InventoryService) GetStockReconciliation(ctx context.Context) (*dto.StockReconciliationResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can reconcile stock", nil)
+	}
+	return s.ReconcileStock(ctx)
+}
+
+// ReconcileStock compares medicines.stock and medicines.reserved with the totals of the
+// ledger and of the remaining batch quantities, and reports every medicine that disagrees.
+// It only reports; fixing drift is done with stock adjustments.
+func (s *InventoryService) ReconcileStock(ctx context.Context) (*dto.StockReconciliationResponseDto, error) {
+	medicines, err := s.medicineRepository.FindAllIncludingDeleted(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve medicines", err)
+	}
+	ledger, err := s.stockMovementRepository.SumByMedicine(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to total stock movements", err)
+	}
+	batches, err := s.batchRepository.SumQuantityByMedicine(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to total medicine batches", err)
+	}
+
+	drifts := []dto.StockDriftDto{}
+	for _, medicine := range medicines {
+		balance := ledger[medicine.ID]
+		batchQuantity := batches[medicine.ID]
+		if math.Abs(medicine.Stock-balance.Stock) < stockEpsilon &&
+			math.Abs(medicine.Reserved-balance.Reserved) < stockEpsilon &&
+			math.Abs(medicine.Stock-batchQuantity) < stockEpsilon {
+			continue
+		}
+		drifts = append(drifts, dto.StockDriftDto{
+			MedicineID:     medicine.ID.String(),
+			MedicineName:   medicine.Name,
+			Stock:          medicine.Stock,
+			LedgerStock:    balance.Stock,
+			Reserved:       medicine.Reserved,
+			LedgerReserved: balance.Reserved,
+			BatchQuantity:  batchQuantity,
+		})
+	}
+
+	return &dto.StockReconciliationResponseDto{
+		CheckedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
+		Medicines: len(medicines),
+		Drifts:    drifts,
+	}, nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
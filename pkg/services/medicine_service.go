@@ -2,49 +2,106 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
 	"order-service/pkg/dto"
+	"order-service/pkg/models"
 	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultMedicinePageSize = 20
+	maxMedicinePageSize     = 100
 )
 
 type MedicineService struct {
+	db                 *gorm.DB
 	medicineRepository *repository.MedicineRepository
+	batchRepository    *repository.MedicineBatchRepository
+	unitRepository     *repository.MedicineUnitRepository
+	categoryRepository *repository.TherapeuticCategoryRepository
+	priceRepository    *repository.MedicinePriceRepository
 }
 
-func NewMedicineService(medicineRepo *repository.MedicineRepository) *MedicineService {
+func NewMedicineService(
+	db *gorm.DB,
+	medicineRepo *repository.MedicineRepository,
+	batchRepo *repository.MedicineBatchRepository,
+	unitRepo *repository.MedicineUnitRepository,
+	categoryRepo *repository.TherapeuticCategoryRepository,
+	priceRepo *repository.MedicinePriceRepository,
+) *MedicineService {
 	return &MedicineService{
+		db:                 db,
 		medicineRepository: medicineRepo,
+		batchRepository:    batchRepo,
+		unitRepository:     unitRepo,
+		categoryRepository: categoryRepo,
+		priceRepository:    priceRepo,
 	}
 }
 
-func (s *MedicineService) GetAllMedicines(ctx context.Context) (*dto.GetAllMedicinesResponseDto, error) {
-	medicines, err := s.medicineRepository.FindAll(ctx)
+// SearchMedicines returns one page of the catalog, optionally matched against a search
+// term in English or Thai and filtered by unit, classification and availability.
+func (s *MedicineService) SearchMedicines(ctx context.Context, query dto.SearchMedicinesQueryDto) (*dto.GetAllMedicinesResponseDto, error) {
+	page, pageSize := query.Page, query.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultMedicinePageSize
+	}
+	if page < 1 || pageSize < 1 || pageSize > maxMedicinePageSize {
+		return nil, apperr.New(apperr.CodeBadRequest, fmt.Sprintf("page must be at least 1 and page_size between 1 and %d", maxMedicinePageSize), nil)
+	}
+	if query.Classification != "" && !models.DrugClassification(query.Classification).IsValid() {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid classification", nil)
+	}
+
+	medicines, total, err := s.medicineRepository.Search(ctx, repository.MedicineSearchFilter{
+		Query:          strings.TrimSpace(query.Q),
+		Unit:           strings.TrimSpace(query.Unit),
+		Classification: query.Classification,
+		Category:       strings.TrimSpace(query.Category),
+		InStockOnly:    query.InStock,
+		Limit:          pageSize,
+		Offset:         (page - 1) * pageSize,
+	})
 	if err != nil {
 		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve medicines", err)
 	}
 
+	medicinePtrs := make([]*models.Medicine, len(medicines))
+	for i := range medicines {
+		medicinePtrs[i] = &medicines[i]
+	}
+	if err := applyEffectiveMedicinePrices(ctx, s.priceRepository, medicinePtrs, time.Now()); err != nil {
+		return nil, err
+	}
+
 	medicineList := make([]dto.MedicineResponseDto, len(medicines))
-	for i, medicine := range medicines {
-		medicineList[i] = dto.MedicineResponseDto{
-			ID:        medicine.ID.String(),
-			Name:      medicine.Name,
-			Price:     medicine.Price,
-			Stock:     medicine.Stock,
-			Unit:      medicine.Unit,
-			CreatedAt: medicine.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: medicine.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		}
+	for i := range medicines {
+		medicineList[i] = dto.ToMedicineDto(&medicines[i])
 	}
 
 	return &dto.GetAllMedicinesResponseDto{
 		Medicines: medicineList,
-		Total:     len(medicineList),
+		Total:     int(total),
+		Page:      page,
+		PageSize:  pageSize,
 	}, nil
 }
 
-func (s *MedicineService) GetMedicineByID(ctx context.Context, medicineID string) (*dto.GetMedicineByIDResponseDto, error) {
+// GetMedicineByID returns a medicine with its units priced at the given time, or at the
+// current time when at is nil.
+func (s *MedicineService) GetMedicineByID(ctx context.Context, medicineID string, at *time.Time) (*dto.GetMedicineByIDResponseDto, error) {
 	if medicineID == "" {
 		return nil, apperr.New(apperr.CodeBadRequest, "Medicine ID is required", nil)
 	}
@@ -54,20 +111,331 @@ func (s *MedicineService) GetMedicineByID(ctx context.Context, medicineID string
 		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
 	}
 
-	medicine, err := s.medicineRepository.FindByID(ctx, id)
+	medicine, err := s.medicineRepository.FindByIDWithDetails(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "Medicine not found", err)
+	}
+	pricedAt := time.Now()
+	if at != nil {
+		pricedAt = *at
+	}
+	if err := applyEffectiveMedicinePrices(ctx, s.priceRepository, []*models.Medicine{medicine}, pricedAt); err != nil {
+		return nil, err
+	}
+
+	return &dto.GetMedicineByIDResponseDto{
+		Medicine: dto.ToMedicineDto(medicine),
+	}, nil
+}
+
+// GetExpiringBatches lists batches with stock left that expire within the given number
+// of days, including batches that have already expired.
+func (s *MedicineService) GetExpiringBatches(ctx context.Context, days int) (*dto.GetExpiringBatchesResponseDto, error) {
+	role := contextUtils.GetRole(ctx)
+	if role != "admin" && role != "doctor" {
+		return nil, apperr.New(apperr.CodeForbidden, "only staff can view expiring batches", nil)
+	}
+	if days < 0 {
+		return nil, apperr.New(apperr.CodeBadRequest, "days must not be negative", nil)
+	}
+
+	day := today()
+	batches, err := s.batchRepository.FindExpiringBefore(ctx, day.AddDate(0, 0, days))
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve medicine batches", err)
+	}
+
+	batchList := make([]dto.MedicineBatchResponseDto, len(batches))
+	for i := range batches {
+		batchList[i] = dto.ToMedicineBatchDto(&batches[i], day)
+	}
+
+	return &dto.GetExpiringBatchesResponseDto{
+		Days:    days,
+		Batches: batchList,
+		Total:   len(batchList),
+	}, nil
+}
+
+// GetLowStockMedicines lists medicines whose available stock, after subtracting what is
+// reserved for approved orders, is at or below their reorder point.
+func (s *MedicineService) GetLowStockMedicines(ctx context.Context) (*dto.GetLowStockMedicinesResponseDto, error) {
+	role := contextUtils.GetRole(ctx)
+	if role != "admin" && role != "doctor" {
+		return nil, apperr.New(apperr.CodeForbidden, "only staff can view low-stock medicines", nil)
+	}
+
+	medicines, err := s.medicineRepository.FindLowStock(ctx)
 	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve low-stock medicines", err)
+	}
+
+	medicineList := make([]dto.LowStockMedicineDto, len(medicines))
+	for i := range medicines {
+		medicine := &medicines[i]
+		item := dto.LowStockMedicineDto{
+			MedicineResponseDto: dto.ToMedicineDto(medicine),
+			Shortfall:           *medicine.ReorderPoint - medicine.Available(),
+		}
+		if medicine.ReorderQuantity != nil {
+			item.SuggestedOrderQuantity = *medicine.ReorderQuantity
+		}
+		medicineList[i] = item
+	}
+
+	return &dto.GetLowStockMedicinesResponseDto{
+		Medicines: medicineList,
+		Total:     len(medicineList),
+	}, nil
+}
+
+func (s *MedicineService) UpdateReorderLevels(ctx context.Context, medicineID string, body dto.UpdateReorderLevelsRequestDto) (*dto.GetMedicineByIDResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change reorder levels", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+	if _, err := s.medicineRepository.FindByID(ctx, id); err != nil {
 		return nil, apperr.New(apperr.CodeNotFound, "Medicine not found", err)
 	}
 
+	if err := s.medicineRepository.UpdateReorderLevels(ctx, id, body.ReorderPoint, body.ReorderQuantity); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to update reorder levels", err)
+	}
+
+	medicine, err := s.medicineRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve medicine", err)
+	}
 	return &dto.GetMedicineByIDResponseDto{
-		Medicine: dto.MedicineResponseDto{
-			ID:        medicine.ID.String(),
-			Name:      medicine.Name,
-			Price:     medicine.Price,
-			Stock:     medicine.Stock,
-			Unit:      medicine.Unit,
-			CreatedAt: medicine.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt: medicine.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		},
+		Medicine: dto.ToMedicineDto(medicine),
+	}, nil
+}
+
+func (s *MedicineService) GetTherapeuticCategories(ctx context.Context) (*dto.GetTherapeuticCategoriesResponseDto, error) {
+	categories, err := s.categoryRepository.FindAll(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve categories", err)
+	}
+
+	categoryList := make([]dto.TherapeuticCategoryDto, len(categories))
+	for i, category := range categories {
+		categoryList[i] = dto.TherapeuticCategoryDto{
+			Code:   category.Code,
+			Name:   category.Name,
+			NameTh: category.NameTh,
+			Level:  category.Level,
+		}
+	}
+
+	return &dto.GetTherapeuticCategoriesResponseDto{
+		Categories: categoryList,
+		Total:      len(categoryList),
 	}, nil
 }
+
+// UpsertMedicineUnit adds a sellable unit to a medicine or updates the one with the same
+// name. A new price takes effect immediately and is recorded in the price history.
+func (s *MedicineService) UpsertMedicineUnit(ctx context.Context, medicineID string, body dto.UpsertMedicineUnitRequestDto) (*dto.GetMedicineByIDResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change medicine units", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+	name := strings.TrimSpace(body.Name)
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		medicineRepository := repository.NewMedicineRepository(tx)
+		unitRepository := repository.NewMedicineUnitRepository(tx)
+		now := time.Now()
+
+		if _, err := medicineRepository.FindByID(ctx, id); err != nil {
+			return apperr.New(apperr.CodeNotFound, "Medicine not found", err)
+		}
+
+		unit, err := unitRepository.FindByMedicineAndName(ctx, id, name)
+		if err == gorm.ErrRecordNotFound {
+			unit = &models.MedicineUnit{
+				ID:         utils.GenerateUUIDv7(),
+				MedicineID: id,
+				Name:       name,
+				Factor:     body.Factor,
+				Price:      body.Price,
+			}
+			if err := unitRepository.Create(ctx, unit); err != nil {
+				return apperr.New(apperr.CodeInternal, "Failed to create medicine unit", err)
+			}
+			reason := "unit added"
+			return recordPriceChange(ctx, tx, unit, &models.MedicinePrice{
+				Price:         body.Price,
+				EffectiveFrom: now,
+				Reason:        &reason,
+			}, now)
+		}
+		if err != nil {
+			return apperr.New(apperr.CodeInternal, "Failed to retrieve medicine unit", err)
+		}
+
+		if unit.IsBase && body.Factor != 1 {
+			return apperr.New(apperr.CodeBadRequest, "the base unit's factor must be 1", nil)
+		}
+		if unit, err = unitRepository.FindByIDForUpdate(ctx, unit.ID); err != nil {
+			return apperr.New(apperr.CodeInternal, "Failed to retrieve medicine unit", err)
+		}
+		if err := applyEffectivePrices(ctx, repository.NewMedicinePriceRepository(tx), []*models.MedicineUnit{unit}, now); err != nil {
+			return err
+		}
+		currentPrice := unit.Price
+		unit.Factor = body.Factor
+		if err := unitRepository.Update(ctx, unit); err != nil {
+			return apperr.New(apperr.CodeInternal, "Failed to update medicine unit", err)
+		}
+		if body.Price == currentPrice {
+			return nil
+		}
+		reason := "unit price updated"
+		return recordPriceChange(ctx, tx, unit, &models.MedicinePrice{
+			Price:         body.Price,
+			EffectiveFrom: now,
+			Reason:        &reason,
+		}, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetMedicineByID(ctx, medicineID, nil)
+}
+
+// GetPriceHistory lists every price range of every unit of a medicine, newest first,
+// including scheduled changes that have not taken effect yet.
+func (s *MedicineService) GetPriceHistory(ctx context.Context, medicineID string) (*dto.GetMedicinePriceHistoryResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view price history", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+	if _, err := s.medicineRepository.FindByID(ctx, id); err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "Medicine not found", err)
+	}
+
+	prices, err := s.priceRepository.FindByMedicineID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "Failed to retrieve price history", err)
+	}
+
+	now := time.Now()
+	priceList := make([]dto.MedicinePriceDto, len(prices))
+	for i := range prices {
+		priceList[i] = dto.ToMedicinePriceDto(&prices[i], now)
+	}
+
+	return &dto.GetMedicinePriceHistoryResponseDto{
+		MedicineID: id.String(),
+		Prices:     priceList,
+		Total:      len(priceList),
+	}, nil
+}
+
+// SchedulePriceChange sets the price of a unit from the given time on. Without a time the
+// change applies immediately; changes cannot be backdated.
+func (s *MedicineService) SchedulePriceChange(ctx context.Context, medicineID string, body dto.ScheduleMedicinePriceRequestDto) (*dto.MedicinePriceDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change prices", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+
+	now := time.Now()
+	effectiveFrom := now
+	if body.EffectiveFrom != nil {
+		effectiveFrom, err = time.Parse(time.RFC3339, *body.EffectiveFrom)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "effective_from must be an RFC 3339 timestamp", err)
+		}
+		if effectiveFrom.Before(now) {
+			return nil, apperr.New(apperr.CodeBadRequest, "price changes cannot be backdated", nil)
+		}
+	}
+
+	change := &models.MedicinePrice{
+		Price:         body.Price,
+		EffectiveFrom: effectiveFrom,
+		Reason:        body.Reason,
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unit, err := s.lockUnit(ctx, tx, id, body.UnitID)
+		if err != nil {
+			return err
+		}
+		change.Unit = unit
+		return recordPriceChange(ctx, tx, unit, change, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := dto.ToMedicinePriceDto(change, now)
+	return &res, nil
+}
+
+// CancelPriceChange removes a scheduled price change before it takes effect.
+func (s *MedicineService) CancelPriceChange(ctx context.Context, medicineID string, priceID string) (*dto.CancelMedicinePriceResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change prices", nil)
+	}
+	id, err := uuid.Parse(medicineID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid medicine ID format", err)
+	}
+	parsedPriceID, err := uuid.Parse(priceID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "Invalid price ID format", err)
+	}
+
+	price, err := s.priceRepository.FindByID(ctx, parsedPriceID)
+	if err != nil || price.MedicineID != id {
+		return nil, apperr.New(apperr.CodeNotFound, "price change not found", err)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unit, err := s.lockUnit(ctx, tx, id, &price.UnitID)
+		if err != nil {
+			return err
+		}
+		return cancelPriceChange(ctx, tx, unit, price.ID, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.CancelMedicinePriceResponseDto{
+		PriceID: price.ID.String(),
+		Status:  "cancelled",
+	}, nil
+}
+
+// lockUnit locks a unit of the medicine for a price change; a nil unit ID means the base unit.
+func (s *MedicineService) lockUnit(ctx context.Context, tx *gorm.DB, medicineID uuid.UUID, unitID *uuid.UUID) (*models.MedicineUnit, error) {
+	unitRepository := repository.NewMedicineUnitRepository(tx)
+	if unitID == nil {
+		base, err := unitRepository.FindBaseByMedicineID(ctx, medicineID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeNotFound, "Medicine not found", err)
+		}
+		unitID = &base.ID
+	}
+	unit, err := unitRepository.FindByIDForUpdate(ctx, *unitID)
+	if err != nil || unit.MedicineID != medicineID {
+		return nil, apperr.New(apperr.CodeNotFound, "unit not found for this medicine", err)
+	}
+	return unit, nil
+}
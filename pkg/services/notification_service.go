@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/events"
+	"order-service/pkg/models"
+	"order-service/pkg/notifications"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const defaultNotificationLogLimit = 50
+
+// NotificationService tells patients and doctors about their orders on the channels
+// they chose, in their language. It is a publisher for the outbox relay; every message
+// is written to the send log, and a message that fails is logged as failed rather than
+// retried so one broken channel does not hold up the others.
+type NotificationService struct {
+	notificationPreferenceRepository *repository.NotificationPreferenceRepository
+	notificationLogRepository        *repository.NotificationLogRepository
+	channels                         map[string]notifications.Channel
+}
+
+// NewNotificationService sends through the given channels, keyed by
+// notifications.ChannelEmail, ChannelSMS and ChannelLINE. Channels left out are not used.
+func NewNotificationService(notificationPreferenceRepo *repository.NotificationPreferenceRepository, notificationLogRepo *repository.NotificationLogRepository, channels map[string]notifications.Channel) *NotificationService {
+	return &NotificationService{
+		notificationPreferenceRepository: notificationPreferenceRepo,
+		notificationLogRepository:        notificationLogRepo,
+		channels:                         channels,
+	}
+}
+
+// GetNotificationPreferences returns the caller's preferences, or the defaults when
+// they have not set any.
+func (s *NotificationService) GetNotificationPreferences(ctx context.Context) (*dto.NotificationPreferenceDto, error) {
+	userID, err := uuid.Parse(contextUtils.GetUserId(ctx))
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	preference, err := s.notificationPreferenceRepository.FindByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		preference = &models.NotificationPreference{UserID: userID, Language: string(notifications.LanguageThai)}
+	} else if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve notification preferences", err)
+	}
+	res := dto.ToNotificationPreferenceDto(preference)
+	return &res, nil
+}
+
+func (s *NotificationService) SaveNotificationPreferences(ctx context.Context, body dto.NotificationPreferenceRequestDto) (*dto.NotificationPreferenceDto, error) {
+	userID, err := uuid.Parse(contextUtils.GetUserId(ctx))
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	email := trimmedOrNil(body.Email)
+	phone := trimmedOrNil(body.Phone)
+	lineUserID := trimmedOrNil(body.LINEUserID)
+	if body.EmailEnabled && email == nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "email is required to turn on email notifications", nil)
+	}
+	if body.SMSEnabled && phone == nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "phone is required to turn on SMS notifications", nil)
+	}
+	if body.LINEEnabled && lineUserID == nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "line_user_id is required to turn on LINE notifications", nil)
+	}
+
+	preference := &models.NotificationPreference{
+		UserID:       userID,
+		Language:     body.Language,
+		Email:        email,
+		EmailEnabled: body.EmailEnabled,
+		Phone:        phone,
+		SMSEnabled:   body.SMSEnabled,
+		LINEUserID:   lineUserID,
+		LINEEnabled:  body.LINEEnabled,
+	}
+	if err := s.notificationPreferenceRepository.Save(ctx, preference); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to save notification preferences", err)
+	}
+	saved, err := s.notificationPreferenceRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve notification preferences", err)
+	}
+	res := dto.ToNotificationPreferenceDto(saved)
+	return &res, nil
+}
+
+// GetNotificationLog returns the latest messages sent to the caller, newest first.
+// Admins can pass another user's ID.
+func (s *NotificationService) GetNotificationLog(ctx context.Context, userID string, limit int) (*dto.GetNotificationLogResponseDto, error) {
+	if userID == "" {
+		userID = contextUtils.GetUserId(ctx)
+	} else if userID != contextUtils.GetUserId(ctx) && contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view other users' notifications", nil)
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	if limit <= 0 {
+		limit = defaultNotificationLogLimit
+	}
+
+	entries, err := s.notificationLogRepository.FindByUserID(ctx, id, limit)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve notifications", err)
+	}
+	res := &dto.GetNotificationLogResponseDto{
+		UserID:        id.String(),
+		Notifications: make([]dto.NotificationLogDto, len(entries)),
+	}
+	for i := range entries {
+		res.Notifications[i] = dto.ToNotificationLogDto(&entries[i])
+	}
+	res.Total = len(res.Notifications)
+	return res, nil
+}
+
+// orderMessage returns the message an order event sends and who receives it, or an
+// empty message for events nobody is notified of.
+func orderMessage(eventType string, payload *events.OrderPayload) (string, *string) {
+	switch eventType {
+	case events.OrderCreated:
+		// over-the-counter carts are created approved and announced by order.approved
+		if payload.Status == string(models.OrderStatusPending) && payload.DoctorID != nil {
+			return notifications.MessageOrderPendingReview, payload.DoctorID
+		}
+	case events.OrderApproved:
+		return notifications.MessageOrderApproved, &payload.PatientID
+	case events.OrderRejected:
+		return notifications.MessageOrderRejected, &payload.PatientID
+	case events.OrderPaid:
+		return notifications.MessageOrderPaid, &payload.PatientID
+	case events.OrderShipped:
+		return notifications.MessageOrderDispatched, &payload.PatientID
+	case events.OrderDelivered:
+		return notifications.MessageOrderDelivered, &payload.PatientID
+	case events.OrderChangesRequested:
+		return notifications.MessageOrderChangesRequested, &payload.PatientID
+	case events.OrderExpired:
+		return notifications.MessageOrderExpired, &payload.PatientID
+	case events.OrderPending:
+		// only resubmissions move an existing order back to pending
+		if payload.DoctorID != nil {
+			return notifications.MessageOrderResubmitted, payload.DoctorID
+		}
+	}
+	return "", nil
+}
+
+// Publish sends the message for an order event to its recipient on each channel they
+// turned on. Messages already in the send log for the event are not sent again.
+func (s *NotificationService) Publish(ctx context.Context, event events.Event) error {
+	if event.AggregateType != outboxAggregateOrder {
+		return nil
+	}
+	var payload events.OrderPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return fmt.Errorf("failed to decode order event: %w", err)
+	}
+	message, recipient := orderMessage(event.Type, &payload)
+	if message == "" {
+		return nil
+	}
+	eventID, err := uuid.Parse(event.ID)
+	if err != nil {
+		return fmt.Errorf("invalid event ID %q: %w", event.ID, err)
+	}
+	userID, err := uuid.Parse(*recipient)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", *recipient, err)
+	}
+
+	preference, err := s.notificationPreferenceRepository.FindByUserID(ctx, userID)
+	if err == gorm.ErrRecordNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notification preferences: %w", err)
+	}
+
+	language := notifications.Language(preference.Language)
+	data := notifications.TemplateData{
+		OrderRef: orderRef(payload.OrderID),
+		Amount:   fmt.Sprintf("%.2f", payload.PatientAmount+payload.DeliveryFee),
+	}
+	if payload.Reason != nil {
+		data.Reason = *payload.Reason
+	}
+	if payload.PreviousStatus != nil {
+		data.PreviousStatus = *payload.PreviousStatus
+	}
+	if payload.ReasonCode != nil {
+		data.ReasonLabel = notifications.ReasonLabel(*payload.ReasonCode, language)
+	}
+	subject, body, err := notifications.Render(message, language, data)
+	if err != nil {
+		return err
+	}
+
+	var orderID *uuid.UUID
+	if id, err := uuid.Parse(payload.OrderID); err == nil {
+		orderID = &id
+	}
+	for _, target := range preferredChannels(preference) {
+		channel, ok := s.channels[target.channel]
+		if !ok {
+			continue
+		}
+		sent, err := s.notificationLogRepository.Exists(ctx, eventID, userID, target.channel)
+		if err != nil {
+			return fmt.Errorf("failed to check notification log: %w", err)
+		}
+		if sent {
+			continue
+		}
+
+		entry := &models.NotificationLog{
+			ID:        utils.GenerateUUIDv7(),
+			UserID:    userID,
+			OrderID:   orderID,
+			EventID:   eventID,
+			Message:   message,
+			Channel:   target.channel,
+			Recipient: target.address,
+			Language:  string(language),
+			Subject:   subject,
+			Body:      body,
+			Status:    models.NotificationLogStatusSent,
+		}
+		if err := channel.Send(ctx, notifications.Message{To: target.address, Subject: subject, Body: body}); err != nil {
+			log.Printf("failed to send %s %s notification to user %s: %v", message, target.channel, userID, err)
+			sendErr := err.Error()
+			entry.Status = models.NotificationLogStatusFailed
+			entry.Error = &sendErr
+		}
+		if err := s.notificationLogRepository.Create(ctx, entry); err != nil {
+			return fmt.Errorf("failed to log notification: %w", err)
+		}
+	}
+	return nil
+}
+
+type channelTarget struct {
+	channel string
+	address string
+}
+
+// preferredChannels returns the channels a user turned on, with their addresses.
+func preferredChannels(preference *models.NotificationPreference) []channelTarget {
+	var targets []channelTarget
+	if preference.EmailEnabled && preference.Email != nil {
+		targets = append(targets, channelTarget{notifications.ChannelEmail, *preference.Email})
+	}
+	if preference.SMSEnabled && preference.Phone != nil {
+		targets = append(targets, channelTarget{notifications.ChannelSMS, *preference.Phone})
+	}
+	if preference.LINEEnabled && preference.LINEUserID != nil {
+		targets = append(targets, channelTarget{notifications.ChannelLINE, *preference.LINEUserID})
+	}
+	return targets
+}
+
+// orderRef is the short reference messages use for an order: the last eight characters
+// of its ID, which unlike the leading ones are random.
+func orderRef(orderID string) string {
+	if len(orderID) < 8 {
+		return strings.ToUpper(orderID)
+	}
+	return strings.ToUpper(orderID[len(orderID)-8:])
+}
+
+func trimmedOrNil(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*value)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"order-service/pkg/apperr"
+	"order-service/pkg/constants"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UpdateOrderDelivery moves a paid order to processing, shipped or delivered. Staff and
+// admins record it as the pharmacy packs the order, hands it to the carrier and the
+// carrier delivers it. Shipping creates the order's delivery record, addressed to the
+// given delivery information or the patient's latest delivery address; delivering it
+// marks the record delivered. Each change is recorded in the order history and
+// announced through the outbox like every other status change.
+func (s *OrderService) UpdateOrderDelivery(ctx context.Context, body dto.UpdateOrderDeliveryRequestDto) (*dto.UpdateOrderDeliveryResponseDto, error) {
+	role := contextUtils.GetRole(ctx)
+	if role != constants.RoleAdmin && role != constants.RoleStaff {
+		return nil, apperr.New(apperr.CodeForbidden, "only staff and admins can update order deliveries", nil)
+	}
+
+	parsedOrderID, err := uuid.Parse(body.OrderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+	next := models.OrderStatus(body.Status)
+	switch next {
+	case models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusDelivered:
+	default:
+		return nil, apperr.New(apperr.CodeBadRequest, "status must be processing, shipped or delivered", nil)
+	}
+	var deliveryInfoID *uuid.UUID
+	if body.DeliveryInformationID != nil {
+		id, err := uuid.Parse(*body.DeliveryInformationID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid delivery information ID", err)
+		}
+		deliveryInfoID = &id
+	}
+	trackingNumber := trimmedOrNil(body.TrackingNumber)
+
+	var order *models.Order
+	var delivery *models.Delivery
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if err := checkTransition(order, next); err != nil {
+			return err
+		}
+
+		deliveryRepo := repository.NewDeliveryRepository(tx)
+		now := time.Now()
+		switch next {
+		case models.OrderStatusShipped:
+			infoID, err := shippingAddress(ctx, tx, order, deliveryInfoID)
+			if err != nil {
+				return err
+			}
+			delivery = &models.Delivery{
+				ID:                  utils.GenerateUUIDv7(),
+				OrderID:             order.ID,
+				DeliveryInformation: infoID,
+				TrackingNumber:      trackingNumber,
+				Status:              models.DeliveryStatusInTransit,
+			}
+			if err := deliveryRepo.Create(ctx, delivery); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to record delivery", err)
+			}
+		case models.OrderStatusDelivered:
+			delivery, err = deliveryRepo.FindByOrderID(ctx, order.ID)
+			if err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to retrieve delivery", err)
+			}
+			delivery.Status = models.DeliveryStatusDelivered
+			delivery.DeliveredAt = &now
+			if trackingNumber != nil {
+				delivery.TrackingNumber = trackingNumber
+			}
+			if err := deliveryRepo.Update(ctx, delivery); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to update delivery", err)
+			}
+		}
+
+		from := order.Status
+		order.Status = next
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to update order status", err)
+		}
+		return recordStatusChange(ctx, tx, order, &from, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.UpdateOrderDeliveryResponseDto{
+		OrderID:  order.ID.String(),
+		Status:   string(order.Status),
+		Delivery: dto.ToOrderDeliveryDto(delivery),
+	}, nil
+}
+
+// shippingAddress returns the delivery information an order ships to: the one given,
+// which must be the patient's, or the patient's latest delivery address.
+func shippingAddress(ctx context.Context, tx *gorm.DB, order *models.Order, deliveryInfoID *uuid.UUID) (uuid.UUID, error) {
+	deliveryInfoRepo := repository.NewDeliveryInformationRepository(tx)
+	if deliveryInfoID != nil {
+		info, err := deliveryInfoRepo.FindByID(ctx, *deliveryInfoID)
+		if err != nil {
+			return uuid.Nil, apperr.New(apperr.CodeNotFound, "delivery information not found", err)
+		}
+		if info.UserID != order.PatientID {
+			return uuid.Nil, apperr.New(apperr.CodeBadRequest, "delivery information does not belong to the patient", nil)
+		}
+		return info.ID, nil
+	}
+	info, err := deliveryInfoRepo.FindLatestByUserIDAndDeliveryMethod(ctx, order.PatientID, models.DeliveryMethodFlash)
+	if err == gorm.ErrRecordNotFound {
+		return uuid.Nil, apperr.New(apperr.CodeConflict, "the patient has no delivery address", nil)
+	}
+	if err != nil {
+		return uuid.Nil, apperr.New(apperr.CodeInternal, "failed to retrieve delivery information", err)
+	}
+	return info.ID, nil
+}
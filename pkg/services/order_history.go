@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// recordStatusChange appends the order's move from one status to its current status to
+// its history, and the matching events to the outbox; from is nil when the order has
+// just been created. It runs in the transaction that saves the new status. The actor
+// is taken from the request context; calls without a user are recorded as system.
+func recordStatusChange(ctx context.Context, tx *gorm.DB, order *models.Order, from *models.OrderStatus, reason *string) error {
+	change := &models.OrderStatusChange{
+		ID:         utils.GenerateUUIDv7(),
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   order.Status,
+		ActorRole:  stockActorSystem,
+		Reason:     reason,
+	}
+	if role := contextUtils.GetRole(ctx); role != "" {
+		change.ActorRole = role
+	}
+	if actorID, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+		change.ActorID = &actorID
+	}
+	if err := repository.NewOrderStatusChangeRepository(tx).Create(ctx, change); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to record order history", err)
+	}
+	return enqueueOrderEvents(ctx, tx, order, change)
+}
+
+// GetOrderHistory returns every status an order has moved through, oldest first.
+func (s *OrderService) GetOrderHistory(ctx context.Context, orderID string) (*dto.GetOrderHistoryResponseDto, error) {
+	order, err := s.findReadableOrder(ctx, orderID, "history")
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := repository.NewOrderStatusChangeRepository(s.db).FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve order history", err)
+	}
+	res := &dto.GetOrderHistoryResponseDto{
+		OrderID: order.ID.String(),
+		Status:  string(order.Status),
+		Changes: make([]dto.OrderStatusChangeDto, len(changes)),
+	}
+	for i := range changes {
+		res.Changes[i] = dto.ToOrderStatusChangeDto(&changes[i])
+	}
+	return res, nil
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// addOrderComment appends a comment by the request's user to the order's thread, in the
+// given transaction.
+func addOrderComment(ctx context.Context, tx *gorm.DB, order *models.Order, kind models.OrderCommentKind, body string) (*models.OrderComment, error) {
+	authorID, err := uuid.Parse(contextUtils.GetUserId(ctx))
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	comment := &models.OrderComment{
+		ID:         utils.GenerateUUIDv7(),
+		OrderID:    order.ID,
+		AuthorID:   authorID,
+		AuthorRole: contextUtils.GetRole(ctx),
+		Kind:       kind,
+		Body:       body,
+	}
+	if err := repository.NewOrderCommentRepository(tx).Create(ctx, comment); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to save order comment", err)
+	}
+	return comment, nil
+}
+
+// RequestOrderChanges sends a pending order back to the patient with the doctor's
+// comment instead of rejecting it. The patient can resubmit it once they have answered.
+func (s *OrderService) RequestOrderChanges(ctx context.Context, body dto.RequestOrderChangesRequestDto) (*dto.OrderReviewResponseDto, error) {
+	userID := contextUtils.GetUserId(ctx)
+	role := contextUtils.GetRole(ctx)
+
+	if role != "doctor" {
+		return nil, apperr.New(apperr.CodeForbidden, "only doctors can request changes to orders", nil)
+	}
+	parsedOrderID, err := uuid.Parse(body.OrderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+	doctorID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	text := strings.TrimSpace(body.Comment)
+	if text == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "a comment is required when requesting changes", nil)
+	}
+
+	var order *models.Order
+	var comment *models.OrderComment
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.DoctorID == nil || *order.DoctorID != doctorID {
+			return apperr.New(apperr.CodeForbidden, "doctor can only request changes to their own orders", nil)
+		}
+		if err := checkTransition(order, models.OrderStatusChangesRequested); err != nil {
+			return err
+		}
+
+		from := order.Status
+		order.Status = models.OrderStatusChangesRequested
+		reviewedAt := time.Now()
+		order.ReviewedAt = &reviewedAt
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to request order changes", err)
+		}
+		if comment, err = addOrderComment(ctx, tx, order, models.OrderCommentKindChangeRequest, text); err != nil {
+			return err
+		}
+		return recordStatusChange(ctx, tx, order, &from, &text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commentDto := dto.ToOrderCommentDto(comment)
+	return &dto.OrderReviewResponseDto{
+		OrderID: order.ID.String(),
+		Status:  string(order.Status),
+		Comment: &commentDto,
+	}, nil
+}
+
+// ResubmitOrder sends an order the doctor asked changes to back for review, with the
+// patient's revised request when they give one. The wait for a review, and so the
+// order's expiry, starts again from the resubmission.
+func (s *OrderService) ResubmitOrder(ctx context.Context, body dto.ResubmitOrderRequestDto) (*dto.OrderReviewResponseDto, error) {
+	userID := contextUtils.GetUserId(ctx)
+	role := contextUtils.GetRole(ctx)
+
+	if role != "patient" {
+		return nil, apperr.New(apperr.CodeForbidden, "only patients can resubmit orders", nil)
+	}
+	parsedOrderID, err := uuid.Parse(body.OrderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+	patientID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	text := trimmedOrNil(body.Comment)
+	submittedAt := time.Now()
+	_, requestedUnits, err := s.resolveRequestedItems(ctx, body.RequestedItems, submittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var order *models.Order
+	var comment *models.OrderComment
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.PatientID != patientID {
+			return apperr.New(apperr.CodeForbidden, "patient can only resubmit their own orders", nil)
+		}
+		if order.Status != models.OrderStatusChangesRequested {
+			return apperr.New(apperr.CodeConflict, "only orders the doctor asked changes to can be resubmitted", nil)
+		}
+		if err := checkTransition(order, models.OrderStatusPending); err != nil {
+			return err
+		}
+
+		if len(body.RequestedItems) > 0 {
+			if err := replaceRequestedItems(ctx, tx, order, body.RequestedItems, requestedUnits); err != nil {
+				return err
+			}
+		}
+
+		from := order.Status
+		order.Status = models.OrderStatusPending
+		order.SubmittedAt = &submittedAt
+		if note := trimmedOrNil(body.Note); note != nil {
+			order.Note = note
+		}
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to resubmit order", err)
+		}
+		if text != nil {
+			if comment, err = addOrderComment(ctx, tx, order, models.OrderCommentKindResubmission, *text); err != nil {
+				return err
+			}
+		}
+		return recordStatusChange(ctx, tx, order, &from, text)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.OrderReviewResponseDto{
+		OrderID: order.ID.String(),
+		Status:  string(order.Status),
+	}
+	if comment != nil {
+		commentDto := dto.ToOrderCommentDto(comment)
+		res.Comment = &commentDto
+	}
+	return res, nil
+}
+
+// replaceRequestedItems swaps the patient's requested items for a revised request. The
+// order items the doctor made from the old request are dropped with it; items the doctor
+// added of their own accord stay.
+func replaceRequestedItems(ctx context.Context, tx *gorm.DB, order *models.Order, items []dto.RequestedItemInput, units map[uuid.UUID]*models.MedicineUnit) error {
+	fromRequest := make(map[uuid.UUID]bool, len(order.RequestedItems))
+	for _, requested := range order.RequestedItems {
+		if requested.ApprovedQuantity != nil {
+			fromRequest[requested.MedicineID] = true
+		}
+	}
+
+	orderItemRepository := repository.NewOrderItemRepository(tx)
+	var kept []models.OrderItem
+	var subtotal float64
+	for _, item := range order.OrderItems {
+		if fromRequest[item.MedicineID] {
+			if err := orderItemRepository.Delete(ctx, item.ID); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to remove order item", err)
+			}
+			continue
+		}
+		kept = append(kept, item)
+		subtotal += item.LineTotal()
+	}
+	order.OrderItems = kept
+	order.SubtotalAmount = subtotal
+	order.TotalAmount = subtotal
+	if err := repository.NewOrderRepository(tx).UpdateTotals(ctx, order); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to update order total amount", err)
+	}
+
+	requestedItemRepository := repository.NewOrderRequestedItemRepository(tx)
+	if err := requestedItemRepository.DeleteByOrderID(ctx, order.ID); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to remove requested items", err)
+	}
+	order.RequestedItems = nil
+	for _, item := range items {
+		unit := units[item.MedicineID]
+		requested := &models.OrderRequestedItem{
+			ID:         utils.GenerateUUIDv7(),
+			OrderID:    order.ID,
+			MedicineID: item.MedicineID,
+			Quantity:   item.Quantity,
+			UnitID:     &unit.ID,
+			UnitFactor: unit.Factor,
+			Reason:     item.Reason,
+			Status:     models.RequestedItemStatusRequested,
+		}
+		if err := requestedItemRepository.Create(ctx, requested); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to create requested item", err)
+		}
+		order.RequestedItems = append(order.RequestedItems, *requested)
+	}
+	return nil
+}
+
+// GetOrderComments returns the comment thread on an order, oldest first.
+func (s *OrderService) GetOrderComments(ctx context.Context, orderID string) (*dto.GetOrderCommentsResponseDto, error) {
+	order, err := s.findReadableOrder(ctx, orderID, "comments")
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := repository.NewOrderCommentRepository(s.db).FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve order comments", err)
+	}
+	res := &dto.GetOrderCommentsResponseDto{
+		OrderID:  order.ID.String(),
+		Status:   string(order.Status),
+		Comments: make([]dto.OrderCommentDto, len(comments)),
+	}
+	for i := range comments {
+		res.Comments[i] = dto.ToOrderCommentDto(&comments[i])
+	}
+	return res, nil
+}
+
+// AddOrderComment adds a comment to an order's thread. Only the patient and the doctor
+// on the order can comment; admins can read the thread but not take part in it.
+func (s *OrderService) AddOrderComment(ctx context.Context, orderID string, body dto.AddOrderCommentRequestDto) (*dto.OrderCommentDto, error) {
+	role := contextUtils.GetRole(ctx)
+	if role != "patient" && role != "doctor" {
+		return nil, apperr.New(apperr.CodeForbidden, "only the patient and the doctor can comment on orders", nil)
+	}
+	text := strings.TrimSpace(body.Body)
+	if text == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "comment body is required", nil)
+	}
+	order, err := s.findReadableOrder(ctx, orderID, "comments")
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := addOrderComment(ctx, s.db, order, models.OrderCommentKindComment, text)
+	if err != nil {
+		return nil, err
+	}
+	res := dto.ToOrderCommentDto(comment)
+	return &res, nil
+}
@@ -2,67 +2,440 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"order-service/pkg/apperr"
 	"order-service/pkg/clients"
+	client_dto "order-service/pkg/clients/dto"
+	"order-service/pkg/clinical"
 	contextUtils "order-service/pkg/context"
 	"order-service/pkg/dto"
+	"order-service/pkg/inventory"
 	"order-service/pkg/models"
+	"order-service/pkg/payments"
+	"order-service/pkg/prescription"
 	"order-service/pkg/repository"
+	"order-service/pkg/tax"
 	"order-service/pkg/utils"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	appointmentStatusCompleted = "completed"
+
+	requestedItemActionAccept = "accept"
+	requestedItemActionModify = "modify"
+	requestedItemActionStrike = "strike"
+)
+
 type OrderService struct {
-	db                     *gorm.DB
-	orderRepository        *repository.OrderRepository
-	orderItemRepository    *repository.OrderItemRepository
-	medicineRepository     *repository.MedicineRepository
-	deliveryRepository     *repository.DeliveryRepository
-	deliveryInfoRepository *repository.DeliveryInformationRepository
-	userClient             *clients.UserClient
-	appointmentClient      *clients.AppointmentClient
+	db                      *gorm.DB
+	orderRepository         *repository.OrderRepository
+	orderItemRepository     *repository.OrderItemRepository
+	requestedItemRepository *repository.OrderRequestedItemRepository
+	medicineRepository      *repository.MedicineRepository
+	medicineUnitRepository  *repository.MedicineUnitRepository
+	medicinePriceRepository *repository.MedicinePriceRepository
+	orderDiscountRepository *repository.OrderDiscountRepository
+	taxInvoiceRepository    *repository.TaxInvoiceRepository
+	deliveryRepository      *repository.DeliveryRepository
+	deliveryInfoRepository  *repository.DeliveryInformationRepository
+	userClient              *clients.CachedUserClient
+	appointmentClient       *clients.AppointmentClient
+	clinicalChecker         *clinical.Checker
+	seller                  tax.Seller
+	receipts                ReceiptConfig
+	paymentProvider         payments.Provider
 }
 
 func NewOrderService(
 	db *gorm.DB,
 	orderRepo *repository.OrderRepository,
 	orderItemRepo *repository.OrderItemRepository,
+	requestedItemRepo *repository.OrderRequestedItemRepository,
 	medicineRepo *repository.MedicineRepository,
+	medicineUnitRepo *repository.MedicineUnitRepository,
+	medicinePriceRepo *repository.MedicinePriceRepository,
+	orderDiscountRepo *repository.OrderDiscountRepository,
+	taxInvoiceRepo *repository.TaxInvoiceRepository,
 	deliveryRepo *repository.DeliveryRepository,
 	deliveryInfoRepo *repository.DeliveryInformationRepository,
-	userClient *clients.UserClient,
+	userClient *clients.CachedUserClient,
 	appointmentClient *clients.AppointmentClient,
+	clinicalChecker *clinical.Checker,
+	seller tax.Seller,
+	receipts ReceiptConfig,
+	paymentProvider payments.Provider,
 ) *OrderService {
 	return &OrderService{
-		db:                     db,
-		orderRepository:        orderRepo,
-		orderItemRepository:    orderItemRepo,
-		medicineRepository:     medicineRepo,
-		deliveryRepository:     deliveryRepo,
-		deliveryInfoRepository: deliveryInfoRepo,
-		userClient:             userClient,
-		appointmentClient:      appointmentClient,
+		db:                      db,
+		orderRepository:         orderRepo,
+		orderItemRepository:     orderItemRepo,
+		requestedItemRepository: requestedItemRepo,
+		medicineRepository:      medicineRepo,
+		medicineUnitRepository:  medicineUnitRepo,
+		medicinePriceRepository: medicinePriceRepo,
+		orderDiscountRepository: orderDiscountRepo,
+		taxInvoiceRepository:    taxInvoiceRepo,
+		deliveryRepository:      deliveryRepo,
+		deliveryInfoRepository:  deliveryInfoRepo,
+		userClient:              userClient,
+		appointmentClient:       appointmentClient,
+		clinicalChecker:         clinicalChecker,
+		seller:                  seller,
+		receipts:                receipts,
+		paymentProvider:         paymentProvider,
 	}
 }
 
-// calculateOrderTotal calculates the total amount for an order based on its items
-func (s *OrderService) calculateOrderTotal(ctx context.Context, orderID uuid.UUID) (float64, error) {
+// orderTotals is the breakdown of an order's total.
+type orderTotals struct {
+	Subtotal float64
+	Discount float64
+	Total    float64
+}
+
+// calculateOrderTotal calculates the subtotal of an order from its items and the total
+// after the discounts recorded on it
+func (s *OrderService) calculateOrderTotal(ctx context.Context, orderID uuid.UUID) (orderTotals, error) {
 	orderItems, err := s.orderItemRepository.FindByOrderID(ctx, orderID)
 	if err != nil {
-		return 0, err
+		return orderTotals{}, err
+	}
+	discounts, err := s.orderDiscountRepository.FindByOrderID(ctx, orderID)
+	if err != nil {
+		return orderTotals{}, err
 	}
 
-	var totalAmount float64
-	for _, item := range orderItems {
-		if item.Medicine != nil {
-			totalAmount += item.Medicine.Price * item.Quantity
+	var totals orderTotals
+	for i := range orderItems {
+		totals.Subtotal += orderItems[i].LineTotal()
+	}
+	for _, discount := range discounts {
+		totals.Discount += discount.Amount
+	}
+	totals.Total = totals.Subtotal - totals.Discount
+
+	return totals, nil
+}
+
+// resolveUnit returns the sellable unit an item is ordered in, priced at the given time.
+// Items without a unit are counted in the medicine's base unit.
+func (s *OrderService) resolveUnit(ctx context.Context, medicine *models.Medicine, unitID *uuid.UUID, at time.Time) (*models.MedicineUnit, error) {
+	var unit *models.MedicineUnit
+	var err error
+	if unitID == nil {
+		unit, err = s.medicineUnitRepository.FindBaseByMedicineID(ctx, medicine.ID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeInternal, medicine.Name+" has no base unit", err)
+		}
+	} else {
+		unit, err = s.medicineUnitRepository.FindByID(ctx, *unitID)
+		if err != nil || unit.MedicineID != medicine.ID {
+			return nil, apperr.New(apperr.CodeBadRequest, "unit not found for "+medicine.Name, err)
+		}
+	}
+	if err := applyEffectivePrices(ctx, s.medicinePriceRepository, []*models.MedicineUnit{unit}, at); err != nil {
+		return nil, err
+	}
+	return unit, nil
+}
+
+// getPatientInfos resolves patient profiles for order listings. Profiles that cannot be
+// resolved are left out; profiles served from an expired cache entry are marked stale.
+func (s *OrderService) getPatientInfos(ctx context.Context, patientIDs []string) map[string]*dto.PatientInfo {
+	patientInfos := make(map[string]*dto.PatientInfo)
+	if len(patientIDs) == 0 {
+		return patientInfos
+	}
+
+	profiles, err := s.userClient.GetPatientProfiles(ctx, patientIDs)
+	if err != nil {
+		log.Printf("failed to fetch patient profiles, serving %d of %d from cache: %v", len(profiles), len(patientIDs), err)
+	}
+	for id, cached := range profiles {
+		patientInfos[id] = &dto.PatientInfo{
+			PatientID:   cached.Profile.ID,
+			FirstName:   cached.Profile.FirstName,
+			LastName:    cached.Profile.LastName,
+			Gender:      cached.Profile.Gender,
+			PhoneNumber: cached.Profile.PhoneNumber,
+			Stale:       cached.Stale,
+		}
+	}
+	return patientInfos
+}
+
+// resolveAppointment picks the completed appointment an order is issued under: the one the
+// patient selected, otherwise their most recent completed appointment (with the selected
+// doctor, if one was given).
+func (s *OrderService) resolveAppointment(ctx context.Context, patientID uuid.UUID, body dto.CreateOrderRequestDto) (*client_dto.GetAppointmentResponseDto, error) {
+	if body.AppointmentID != nil {
+		appointmentID, err := uuid.Parse(*body.AppointmentID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid appointment ID", err)
+		}
+		appointment, err := s.appointmentClient.GetAppointmentByID(ctx, appointmentID)
+		if errors.Is(err, clients.ErrNotFound) {
+			return nil, apperr.New(apperr.CodeNotFound, "appointment not found", err)
+		}
+		if err != nil {
+			return nil, apperr.New(apperr.CodeInternal, "failed to get appointment", err)
+		}
+		if !sameUUID(appointment.PatientID, patientID.String()) {
+			return nil, apperr.New(apperr.CodeForbidden, "appointment does not belong to patient", nil)
+		}
+		if appointment.Status != appointmentStatusCompleted {
+			return nil, apperr.New(apperr.CodeBadRequest, "appointment is not completed", nil)
+		}
+		if body.DoctorID != nil && !sameUUID(appointment.DoctorID, *body.DoctorID) {
+			return nil, apperr.New(apperr.CodeBadRequest, "doctor does not match the selected appointment", nil)
+		}
+		return appointment, nil
+	}
+
+	latest, err := s.appointmentClient.GetLatestAppointmentByPatientID(ctx, patientID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to get latest appointment", err)
+	}
+	if latest != nil && latest.Status == appointmentStatusCompleted && (body.DoctorID == nil || sameUUID(latest.DoctorID, *body.DoctorID)) {
+		return &client_dto.GetAppointmentResponseDto{
+			ID:              latest.ID,
+			PatientID:       latest.PatientID,
+			DoctorID:        latest.DoctorID,
+			DoctorFirstName: latest.DoctorFirstName,
+			DoctorLastName:  latest.DoctorLastName,
+			Specialty:       latest.Specialty,
+			StartTime:       latest.StartTime,
+			EndTime:         latest.EndTime,
+			Status:          latest.Status,
+		}, nil
+	}
+
+	// the latest appointment is not usable (cancelled, upcoming or with another doctor),
+	// so fall back to the most recent completed one in the patient's history
+	history, err := s.appointmentClient.GetMyAppointmentHistory(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to get appointment history", err)
+	}
+	var selected *client_dto.GetAppointmentResponseDto
+	var selectedStart time.Time
+	for i := range history {
+		appointment := &history[i]
+		if appointment.Status != appointmentStatusCompleted {
+			continue
+		}
+		if appointment.PatientID != "" && !sameUUID(appointment.PatientID, patientID.String()) {
+			continue
+		}
+		if body.DoctorID != nil && !sameUUID(appointment.DoctorID, *body.DoctorID) {
+			continue
+		}
+		startTime, _ := time.Parse(time.RFC3339, appointment.StartTime)
+		if selected == nil || startTime.After(selectedStart) {
+			selected = appointment
+			selectedStart = startTime
+		}
+	}
+	if selected == nil {
+		if body.DoctorID != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "patient has no completed appointment with the selected doctor", nil)
+		}
+		return nil, apperr.New(apperr.CodeBadRequest, "patient has no completed appointment", nil)
+	}
+	return selected, nil
+}
+
+// runClinicalChecks checks the medicines against each other and against the patient's
+// recorded allergies. A failed allergy lookup is reported as a finding, not an error.
+func (s *OrderService) runClinicalChecks(ctx context.Context, patientID uuid.UUID, medicines []*models.Medicine) []clinical.Finding {
+	names := make([]string, len(medicines))
+	for i, medicine := range medicines {
+		names[i] = medicine.ClinicalName()
+	}
+
+	var allergies *string
+	record, err := s.userClient.GetPatientAllergies(ctx, patientID.String())
+	if err != nil {
+		log.Printf("failed to retrieve allergies for patient %s: %v", patientID, err)
+	} else {
+		none := ""
+		allergies = &none
+		if record.Allergies != nil {
+			allergies = record.Allergies
+		}
+	}
+	return s.clinicalChecker.Check(names, allergies)
+}
+
+// blockingClinicalError returns an error when any finding prevents the order from
+// going ahead. Findings that need an override are only accepted with a reason.
+func blockingClinicalError(findings []clinical.Finding, overrideReason *string) error {
+	needsOverride := false
+	for _, finding := range findings {
+		if finding.Blocking() {
+			return apperr.New(apperr.CodeConflict, "order contains a contraindicated combination of medicines", nil).
+				WithFields(map[string]any{"clinical_findings": dto.ToClinicalFindingDtoList(findings)})
+		}
+		if finding.RequiresOverride() {
+			needsOverride = true
+		}
+	}
+	if needsOverride && (overrideReason == nil || strings.TrimSpace(*overrideReason) == "") {
+		return apperr.New(apperr.CodeBadRequest, "an override reason is required to approve past a major interaction or allergy", nil).
+			WithFields(map[string]any{"clinical_findings": dto.ToClinicalFindingDtoList(findings)})
+	}
+	return nil
+}
+
+func requiresClinicalOverride(findings []clinical.Finding) bool {
+	for _, finding := range findings {
+		if finding.RequiresOverride() {
+			return true
+		}
+	}
+	return false
+}
+
+// dispenseOrderItems takes the stock for every order item from the medicine's batches,
+// first expiry first out, and records which batches each item came from.
+func (s *OrderService) dispenseOrderItems(ctx context.Context, tx *gorm.DB, order *models.Order) error {
+	batchRepository := repository.NewMedicineBatchRepository(tx)
+	itemBatchRepository := repository.NewOrderItemBatchRepository(tx)
+	now := time.Now()
+
+	for _, item := range order.OrderItems {
+		batches, err := batchRepository.FindAvailableByMedicineIDForUpdate(ctx, item.MedicineID)
+		if err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to retrieve medicine batches", err)
+		}
+		allocations, err := inventory.AllocateFEFO(batches, item.BaseQuantity(), now)
+		if err != nil {
+			name := item.MedicineID.String()
+			if item.Medicine != nil {
+				name = item.Medicine.Name
+			}
+			return apperr.New(apperr.CodeConflict, "insufficient unexpired stock for "+name, err)
+		}
+		for _, allocation := range allocations {
+			if err := batchRepository.AddQuantity(ctx, allocation.BatchID, -allocation.Quantity); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to update medicine batch", err)
+			}
+			batchID, orderID := allocation.BatchID, order.ID
+			// the stock leaves the shelf and the hold placed at approval is used up
+			if err := recordStockMovement(ctx, tx, &models.StockMovement{
+				MedicineID:    item.MedicineID,
+				BatchID:       &batchID,
+				OrderID:       &orderID,
+				Type:          models.StockMovementDispense,
+				StockDelta:    -allocation.Quantity,
+				ReservedDelta: -allocation.Quantity,
+				Reason:        "order paid",
+			}); err != nil {
+				return err
+			}
+			if err := itemBatchRepository.Create(ctx, &models.OrderItemBatch{
+				ID:          utils.GenerateUUIDv7(),
+				OrderItemID: item.ID,
+				BatchID:     allocation.BatchID,
+				Quantity:    allocation.Quantity,
+			}); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to record batch allocation", err)
+			}
 		}
 	}
+	return nil
+}
 
-	return totalAmount, nil
+// checkTransition enforces the order state machine.
+func checkTransition(order *models.Order, next models.OrderStatus) error {
+	if !order.Status.CanTransitionTo(next) {
+		return apperr.New(apperr.CodeConflict, fmt.Sprintf("order cannot move from %s to %s", order.Status, next), nil)
+	}
+	return nil
+}
+
+func uuidPtrToString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// sameUUID compares two UUID strings regardless of formatting; invalid or nil IDs never match.
+func sameUUID(a, b string) bool {
+	parsedA, err := uuid.Parse(a)
+	if err != nil || parsedA == uuid.Nil {
+		return false
+	}
+	parsedB, err := uuid.Parse(b)
+	if err != nil {
+		return false
+	}
+	return parsedA == parsedB
+}
+
+// findReadableOrder loads an order the current user may read the given details of: the
+// patient who owns it, the assigned doctor or an admin.
+func (s *OrderService) findReadableOrder(ctx context.Context, orderID string, details string) (*models.Order, error) {
+	userID := contextUtils.GetUserId(ctx)
+	role := contextUtils.GetRole(ctx)
+
+	parsedOrderID, err := uuid.Parse(orderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+
+	order, err := s.orderRepository.FindByID(ctx, parsedOrderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "order not found", err)
+	}
+
+	switch role {
+	case "admin":
+	case "patient":
+		if order.PatientID.String() != userID {
+			return nil, apperr.New(apperr.CodeForbidden, "patient can only read "+details+" for their own orders", nil)
+		}
+	case "doctor":
+		if order.DoctorID == nil || order.DoctorID.String() != userID {
+			return nil, apperr.New(apperr.CodeForbidden, "doctor can only read "+details+" for their own orders", nil)
+		}
+	default:
+		return nil, apperr.New(apperr.CodeForbidden, "not allowed to read "+details, nil)
+	}
+	return order, nil
+}
+
+// resolveRequestedItems looks up the medicine and unit of each item a patient requests,
+// keyed by medicine, and checks the quantities. Each medicine may be requested once.
+func (s *OrderService) resolveRequestedItems(ctx context.Context, items []dto.RequestedItemInput, at time.Time) (map[uuid.UUID]*models.Medicine, map[uuid.UUID]*models.MedicineUnit, error) {
+	medicines := make(map[uuid.UUID]*models.Medicine, len(items))
+	units := make(map[uuid.UUID]*models.MedicineUnit, len(items))
+	for _, item := range items {
+		if _, ok := medicines[item.MedicineID]; ok {
+			return nil, nil, apperr.New(apperr.CodeBadRequest, "medicine is requested more than once", nil)
+		}
+		medicine, err := s.medicineRepository.FindByID(ctx, item.MedicineID)
+		if err != nil {
+			return nil, nil, apperr.New(apperr.CodeBadRequest, "requested medicine not found", err)
+		}
+		unit, err := s.resolveUnit(ctx, medicine, item.UnitID, at)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := medicine.CheckQuantity(item.Quantity * unit.Factor); err != nil {
+			return nil, nil, apperr.New(apperr.CodeBadRequest, err.Error(), nil)
+		}
+		medicines[item.MedicineID] = medicine
+		units[item.MedicineID] = unit
+	}
+	return medicines, units, nil
 }
 
 func (s *OrderService) CreateOrder(ctx context.Context, body dto.CreateOrderRequestDto) (*dto.CreateOrderResponseDto, error) {
@@ -76,31 +449,144 @@ func (s *OrderService) CreateOrder(ctx context.Context, body dto.CreateOrderRequ
 	if err != nil {
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
 	}
-	appointment, err := s.appointmentClient.GetLatestAppointmentByPatientID(ctx, patientID)
+	// validate the requested items before resolving the appointment
+	submittedAt := time.Now()
+	requestedMedicines, requestedUnits, err := s.resolveRequestedItems(ctx, body.RequestedItems, submittedAt)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to get latest appointment", err)
+		return nil, err
 	}
-	if appointment == nil {
-		return nil, apperr.New(apperr.CodeBadRequest, "patient has no appointment history", nil)
+	requiresDoctorApproval := len(body.RequestedItems) == 0
+	for _, medicine := range requestedMedicines {
+		if medicine.Classification.Rule().RequiresDoctorApproval {
+			requiresDoctorApproval = true
+		}
+	}
+
+	// over-the-counter carts skip doctor review unless they contain a major interaction
+	// or allergy, which a doctor has to look at
+	if !requiresDoctorApproval {
+		medicines := make([]*models.Medicine, 0, len(body.RequestedItems))
+		for _, item := range body.RequestedItems {
+			medicines = append(medicines, requestedMedicines[item.MedicineID])
+		}
+		for _, finding := range s.runClinicalChecks(ctx, patientID, medicines) {
+			if finding.Blocking() || finding.RequiresOverride() {
+				requiresDoctorApproval = true
+				break
+			}
+		}
+	}
+
+	// a doctor is only assigned when one is needed or the patient picked one explicitly
+	var doctorID, appointmentID *uuid.UUID
+	if requiresDoctorApproval || body.AppointmentID != nil || body.DoctorID != nil {
+		appointment, err := s.resolveAppointment(ctx, patientID, body)
+		if err != nil {
+			return nil, err
+		}
+
+		parsedDoctorID, err := uuid.Parse(appointment.DoctorID)
+		if err != nil || parsedDoctorID == uuid.Nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "appointment has no valid doctor", err)
+		}
+		doctorID = &parsedDoctorID
+		parsedAppointmentID, err := uuid.Parse(appointment.ID)
+		if err != nil || parsedAppointmentID == uuid.Nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "appointment has no valid ID", err)
+		}
+		appointmentID = &parsedAppointmentID
 	}
 
-	did := utils.StringToUUIDv7(appointment.DoctorID)
-	submittedAt := time.Now()
 	order := &models.Order{
-		ID:          utils.GenerateUUIDv7(),
-		PatientID:   patientID,
-		DoctorID:    &did,
-		Note:        body.Note,
-		Status:      models.OrderStatusPending,
-		SubmittedAt: &submittedAt,
+		ID:            utils.GenerateUUIDv7(),
+		PatientID:     patientID,
+		DoctorID:      doctorID,
+		AppointmentID: appointmentID,
+		Note:          body.Note,
+		Status:        models.OrderStatusPending,
+		SubmittedAt:   &submittedAt,
+	}
+	if !requiresDoctorApproval {
+		order.Status = models.OrderStatusApproved
+		order.ReviewedAt = &submittedAt
+		for _, item := range body.RequestedItems {
+			order.SubtotalAmount += requestedUnits[item.MedicineID].Price * item.Quantity
+		}
+		order.TotalAmount = order.SubtotalAmount
+	}
+
+	var entitlements []string
+	if order.Status == models.OrderStatusApproved {
+		entitlements = s.patientEntitlements(ctx, patientID)
 	}
 
-	if err := s.orderRepository.Create(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to create order", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewOrderRepository(tx).Create(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to create order", err)
+		}
+		if err := recordStatusChange(ctx, tx, order, nil, nil); err != nil {
+			return err
+		}
+		requestedItemRepository := repository.NewOrderRequestedItemRepository(tx)
+		orderItemRepository := repository.NewOrderItemRepository(tx)
+		for _, item := range body.RequestedItems {
+			unit := requestedUnits[item.MedicineID]
+			requestedItem := &models.OrderRequestedItem{
+				ID:         utils.GenerateUUIDv7(),
+				OrderID:    order.ID,
+				MedicineID: item.MedicineID,
+				Quantity:   item.Quantity,
+				UnitID:     &unit.ID,
+				UnitFactor: unit.Factor,
+				Reason:     item.Reason,
+				Status:     models.RequestedItemStatusRequested,
+			}
+			if order.Status == models.OrderStatusApproved {
+				// auto-approved over-the-counter cart: the request becomes the order as-is
+				quantity := item.Quantity
+				requestedItem.Status = models.RequestedItemStatusAccepted
+				requestedItem.ApprovedQuantity = &quantity
+				orderItem := &models.OrderItem{
+					ID:         utils.GenerateUUIDv7(),
+					OrderID:    order.ID,
+					MedicineID: item.MedicineID,
+					Quantity:   item.Quantity,
+					UnitID:     &unit.ID,
+					UnitFactor: unit.Factor,
+					UnitPrice:  unit.Price,
+				}
+				if err := orderItemRepository.Create(ctx, orderItem); err != nil {
+					return apperr.New(apperr.CodeInternal, "failed to create order item", err)
+				}
+				order.OrderItems = append(order.OrderItems, *orderItem)
+			}
+			if err := requestedItemRepository.Create(ctx, requestedItem); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to create requested item", err)
+			}
+		}
+		if order.Status == models.OrderStatusApproved {
+			if _, err := applyDiscounts(ctx, tx, order, submittedAt); err != nil {
+				return err
+			}
+			if err := applyTax(ctx, tx, order); err != nil {
+				return err
+			}
+			if err := applyCoverage(ctx, tx, order, entitlements, submittedAt); err != nil {
+				return err
+			}
+			return reserveOrderItems(ctx, tx, order)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.CreateOrderResponseDto{
-		OrderID: order.ID.String(),
+		OrderID:       order.ID.String(),
+		DoctorID:      uuidPtrToString(doctorID),
+		AppointmentID: uuidPtrToString(appointmentID),
+		Status:        string(order.Status),
 	}, nil
 }
 
@@ -130,43 +616,200 @@ func (s *OrderService) UpdateOrder(ctx context.Context, body dto.UpdateOrderRequ
 	if order.DoctorID == nil || *order.DoctorID != doctorID {
 		return nil, apperr.New(apperr.CodeForbidden, "doctor can only edit their own orders", nil)
 	}
+	if order.Status != models.OrderStatusPending {
+		return nil, apperr.New(apperr.CodeConflict, "only pending orders can be edited", nil)
+	}
 
-	// main logic begins here
-	// delete existing order items
-	if err := s.orderItemRepository.DeleteByOrderID(ctx, order.ID); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to delete existing order items", err)
+	// resolve the doctor's decisions on the patient's requested items
+	requestedItems, err := s.requestedItemRepository.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve requested items", err)
+	}
+	requestedByID := make(map[uuid.UUID]*models.OrderRequestedItem, len(requestedItems))
+	for i := range requestedItems {
+		requestedByID[requestedItems[i].ID] = &requestedItems[i]
 	}
-	// add new order items
-	var totalAmount float64
+
+	finalItems := []dto.OrderItemInput{}
+	decidedItems := []*models.OrderRequestedItem{}
+	for _, decision := range body.RequestedItemDecisions {
+		requested, ok := requestedByID[decision.RequestedItemID]
+		if !ok {
+			return nil, apperr.New(apperr.CodeBadRequest, "requested item not found on this order", nil)
+		}
+		delete(requestedByID, decision.RequestedItemID)
+
+		switch decision.Action {
+		case requestedItemActionAccept:
+			quantity := requested.Quantity
+			requested.Status = models.RequestedItemStatusAccepted
+			requested.ApprovedQuantity = &quantity
+		case requestedItemActionModify:
+			if decision.Quantity == nil {
+				return nil, apperr.New(apperr.CodeBadRequest, "quantity is required to modify a requested item", nil)
+			}
+			quantity := *decision.Quantity
+			requested.Status = models.RequestedItemStatusModified
+			requested.ApprovedQuantity = &quantity
+		case requestedItemActionStrike:
+			requested.Status = models.RequestedItemStatusStruck
+			requested.ApprovedQuantity = nil
+		default:
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid requested item action", nil)
+		}
+		requested.DoctorNote = decision.Note
+		decidedItems = append(decidedItems, requested)
+
+		if requested.ApprovedQuantity != nil {
+			finalItems = append(finalItems, dto.OrderItemInput{
+				MedicineID: requested.MedicineID,
+				Quantity:   *requested.ApprovedQuantity,
+				UnitID:     requested.UnitID,
+				Dosage:     decision.Dosage,
+			})
+		}
+	}
+
+	// requested items decided in an earlier edit keep their order items, and the dosage
+	// given with them, unless the doctor lists the medicine in order_items again
+	listedMedicines := make(map[uuid.UUID]bool, len(body.OrderItems))
 	for _, item := range body.OrderItems {
+		listedMedicines[item.MedicineID] = true
+	}
+	existingItems := make(map[uuid.UUID]*models.OrderItem, len(order.OrderItems))
+	for i := range order.OrderItems {
+		existingItems[order.OrderItems[i].MedicineID] = &order.OrderItems[i]
+	}
+	for i := range requestedItems {
+		requested := &requestedItems[i]
+		if _, undecided := requestedByID[requested.ID]; !undecided || requested.ApprovedQuantity == nil || listedMedicines[requested.MedicineID] {
+			continue
+		}
+		carried := dto.OrderItemInput{
+			MedicineID: requested.MedicineID,
+			Quantity:   *requested.ApprovedQuantity,
+			UnitID:     requested.UnitID,
+		}
+		if existing, ok := existingItems[requested.MedicineID]; ok {
+			carried.Dosage = dosageInput(existing)
+		}
+		finalItems = append(finalItems, carried)
+	}
+	finalItems = append(finalItems, body.OrderItems...)
+
+	seenMedicines := make(map[uuid.UUID]bool, len(finalItems))
+	for _, item := range finalItems {
+		if seenMedicines[item.MedicineID] {
+			return nil, apperr.New(apperr.CodeBadRequest, "medicine is listed more than once", nil)
+		}
+		seenMedicines[item.MedicineID] = true
+	}
+
+	medicines := make([]*models.Medicine, len(finalItems))
+	units := make([]*models.MedicineUnit, len(finalItems))
+	pricedAt := time.Now()
+	for i, item := range finalItems {
 		medicine, err := s.medicineRepository.FindByID(ctx, item.MedicineID)
 		if err != nil {
 			return nil, apperr.New(apperr.CodeBadRequest, "medicine not found", err)
 		}
-		orderItem := &models.OrderItem{
-			ID:         utils.GenerateUUIDv7(),
-			OrderID:    order.ID,
-			MedicineID: medicine.ID,
-			Quantity:   item.Quantity,
+		unit, err := s.resolveUnit(ctx, medicine, item.UnitID, pricedAt)
+		if err != nil {
+			return nil, err
 		}
-		if err := s.orderItemRepository.Create(ctx, orderItem); err != nil {
-			return nil, apperr.New(apperr.CodeInternal, "failed to create order item", err)
+		medicines[i] = medicine
+		units[i] = unit
+	}
+
+	// contraindications block the edit; everything else is reported back to the doctor
+	findings := s.runClinicalChecks(ctx, order.PatientID, medicines)
+	for _, finding := range findings {
+		if finding.Blocking() {
+			return nil, blockingClinicalError(findings, nil)
 		}
-		// Calculate total amount
-		totalAmount += medicine.Price * item.Quantity
 	}
 
-	// Update order with calculated total amount
-	order.TotalAmount = totalAmount
-	if err := s.orderRepository.Update(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to update order total amount", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		orderItemRepository := repository.NewOrderItemRepository(tx)
+		requestedItemRepository := repository.NewOrderRequestedItemRepository(tx)
+
+		// replace existing order items
+		if err := orderItemRepository.DeleteByOrderID(ctx, order.ID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to delete existing order items", err)
+		}
+		var totalAmount float64
+		for i, item := range finalItems {
+			medicine, unit := medicines[i], units[i]
+			orderItem := &models.OrderItem{
+				ID:         utils.GenerateUUIDv7(),
+				OrderID:    order.ID,
+				MedicineID: medicine.ID,
+				Quantity:   item.Quantity,
+				UnitID:     &unit.ID,
+				UnitFactor: unit.Factor,
+				UnitPrice:  unit.Price,
+			}
+			if err := medicine.CheckQuantity(orderItem.BaseQuantity()); err != nil {
+				return apperr.New(apperr.CodeBadRequest, err.Error(), nil)
+			}
+			if item.Dosage != nil {
+				if err := prescription.ValidateQuantity(orderItem.BaseQuantity(), item.Dosage.Dose, item.Dosage.FrequencyPerDay, item.Dosage.DurationDays); err != nil {
+					return apperr.New(apperr.CodeBadRequest, medicine.Name+": "+err.Error(), nil)
+				}
+				route := models.DosageRoute(item.Dosage.Route)
+				durationDays := item.Dosage.DurationDays
+				orderItem.Dose = &item.Dosage.Dose
+				orderItem.FrequencyPerDay = &item.Dosage.FrequencyPerDay
+				orderItem.Route = &route
+				orderItem.DurationDays = &durationDays
+				orderItem.Instructions = item.Dosage.Instructions
+			}
+			if err := orderItemRepository.Create(ctx, orderItem); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to create order item", err)
+			}
+			// Calculate total amount
+			totalAmount += orderItem.LineTotal()
+		}
+
+		for _, requested := range decidedItems {
+			if err := requestedItemRepository.Update(ctx, requested); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to update requested item", err)
+			}
+		}
+
+		// Update order with calculated total amount
+		order.SubtotalAmount = totalAmount
+		order.TotalAmount = totalAmount
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to update order total amount", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.UpdateOrderResponseDto{
-		OrderID: order.ID.String(),
+		OrderID:          order.ID.String(),
+		ClinicalFindings: dto.ToClinicalFindingDtoList(findings),
 	}, nil
 }
 
+// dosageInput returns the dosage recorded on an order item as it would be given in an
+// edit, or nil when the item has none.
+func dosageInput(item *models.OrderItem) *dto.DosageInput {
+	if item.Dose == nil || item.FrequencyPerDay == nil || item.Route == nil || item.DurationDays == nil {
+		return nil
+	}
+	return &dto.DosageInput{
+		Dose:            *item.Dose,
+		FrequencyPerDay: *item.FrequencyPerDay,
+		Route:           string(*item.Route),
+		DurationDays:    *item.DurationDays,
+		Instructions:    item.Instructions,
+	}
+}
+
 func (s *OrderService) GetOrderByID(ctx context.Context, orderID string) (*dto.GetOrderByIDResponseDto, error) {
 	parsedOrderID, err := uuid.Parse(orderID)
 	if err != nil {
@@ -179,18 +822,7 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderID string) (*dto.G
 	}
 
 	// Convert order items to response format
-	orderItems := make([]dto.OrderItem, len(order.OrderItems))
-	for i, item := range order.OrderItems {
-		medicineName := ""
-		if item.Medicine != nil {
-			medicineName = item.Medicine.Name
-		}
-		orderItems[i] = dto.OrderItem{
-			MedicineID:   item.MedicineID.String(),
-			MedicineName: medicineName,
-			Quantity:     item.Quantity,
-		}
-	}
+	orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 	// Format timestamps
 	var submittedAt, reviewedAt *string
@@ -218,8 +850,15 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderID string) (*dto.G
 	return &dto.GetOrderByIDResponseDto{
 		OrderID:        order.ID.String(),
 		PatientID:      order.PatientID.String(),
-		DoctorID:       order.DoctorID.String(),
+		DoctorID:       uuidPtrToString(order.DoctorID),
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		CouponCode:     order.CouponCode,
 		TotalAmount:    order.TotalAmount,
+		VATAmount:      order.VATAmount,
+		Entitlement:    order.Entitlement,
+		InsurerAmount:  order.InsurerAmount,
+		PatientAmount:  order.PatientAmount,
 		Note:           order.Note,
 		SubmittedAt:    submittedAt,
 		ReviewedAt:     reviewedAt,
@@ -227,9 +866,33 @@ func (s *OrderService) GetOrderByID(ctx context.Context, orderID string) (*dto.G
 		DeliveryStatus: deliveryStatus,
 		DeliveryAt:     deliveryAt,
 		OrderItems:     orderItems,
+		RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
+		Discounts:      dto.ToOrderDiscountDtoList(order.Discounts),
+		Rejection:      dto.ToOrderRejectionDto(order),
 	}, nil
 }
 
+// GetOrderLabels renders printable dispensing labels for an order. The patient who owns
+// the order, the assigned doctor and admins may print them.
+func (s *OrderService) GetOrderLabels(ctx context.Context, orderID string) (string, error) {
+	order, err := s.findReadableOrder(ctx, orderID, "labels")
+	if err != nil {
+		return "", err
+	}
+
+	switch order.Status {
+	case models.OrderStatusPending, models.OrderStatusChangesRequested, models.OrderStatusRejected, models.OrderStatusCancelled, models.OrderStatusExpired:
+		return "", apperr.New(apperr.CodeConflict, "labels are only available for approved orders", nil)
+	}
+
+	patientName := order.PatientID.String()
+	if patientInfo, ok := s.getPatientInfos(ctx, []string{order.PatientID.String()})[order.PatientID.String()]; ok {
+		patientName = patientInfo.FirstName + " " + patientInfo.LastName
+	}
+
+	return prescription.RenderLabels(order, patientName, time.Now()), nil
+}
+
 func (s *OrderService) GetAllOrdersHistoryByPatientID(ctx context.Context) (*dto.GetAllOrdersHistoryListDto, error) {
 	userID := contextUtils.GetUserId(ctx)
 
@@ -247,18 +910,7 @@ func (s *OrderService) GetAllOrdersHistoryByPatientID(ctx context.Context) (*dto
 
 	for idx, order := range orders {
 		// Convert order items to response format
-		orderItems := make([]dto.OrderItem, len(order.OrderItems))
-		for i, item := range order.OrderItems {
-			medicineName := ""
-			if item.Medicine != nil {
-				medicineName = item.Medicine.Name
-			}
-			orderItems[i] = dto.OrderItem{
-				MedicineID:   item.MedicineID.String(),
-				MedicineName: medicineName,
-				Quantity:     item.Quantity,
-			}
-		}
+		orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 		// Format timestamps
 		var submittedAt, reviewedAt *string
@@ -293,7 +945,14 @@ func (s *OrderService) GetAllOrdersHistoryByPatientID(ctx context.Context) (*dto
 			OrderID:        order.ID.String(),
 			PatientID:      order.PatientID.String(),
 			DoctorID:       doctorID,
+			SubtotalAmount: order.SubtotalAmount,
+			DiscountAmount: order.DiscountAmount,
+			CouponCode:     order.CouponCode,
 			TotalAmount:    order.TotalAmount,
+			VATAmount:      order.VATAmount,
+			Entitlement:    order.Entitlement,
+			InsurerAmount:  order.InsurerAmount,
+			PatientAmount:  order.PatientAmount,
 			Note:           order.Note,
 			SubmittedAt:    submittedAt,
 			ReviewedAt:     reviewedAt,
@@ -303,6 +962,7 @@ func (s *OrderService) GetAllOrdersHistoryByPatientID(ctx context.Context) (*dto
 			CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			OrderItems:     orderItems,
+			RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
 		}
 	}
 
@@ -329,18 +989,7 @@ func (s *OrderService) GetLatestOrderByPatientID(ctx context.Context) (*dto.GetO
 	}
 
 	// Convert order items to response format
-	orderItems := make([]dto.OrderItem, len(order.OrderItems))
-	for i, item := range order.OrderItems {
-		medicineName := ""
-		if item.Medicine != nil {
-			medicineName = item.Medicine.Name
-		}
-		orderItems[i] = dto.OrderItem{
-			MedicineID:   item.MedicineID.String(),
-			MedicineName: medicineName,
-			Quantity:     item.Quantity,
-		}
-	}
+	orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 	// Format timestamps
 	var submittedAt, reviewedAt *string
@@ -368,8 +1017,15 @@ func (s *OrderService) GetLatestOrderByPatientID(ctx context.Context) (*dto.GetO
 	return &dto.GetOrderByIDResponseDto{
 		OrderID:        order.ID.String(),
 		PatientID:      order.PatientID.String(),
-		DoctorID:       order.DoctorID.String(),
+		DoctorID:       uuidPtrToString(order.DoctorID),
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		CouponCode:     order.CouponCode,
 		TotalAmount:    order.TotalAmount,
+		VATAmount:      order.VATAmount,
+		Entitlement:    order.Entitlement,
+		InsurerAmount:  order.InsurerAmount,
+		PatientAmount:  order.PatientAmount,
 		Note:           order.Note,
 		SubmittedAt:    submittedAt,
 		ReviewedAt:     reviewedAt,
@@ -377,6 +1033,9 @@ func (s *OrderService) GetLatestOrderByPatientID(ctx context.Context) (*dto.GetO
 		DeliveryStatus: deliveryStatus,
 		DeliveryAt:     deliveryAt,
 		OrderItems:     orderItems,
+		RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
+		Discounts:      dto.ToOrderDiscountDtoList(order.Discounts),
+		Rejection:      dto.ToOrderRejectionDto(order),
 	}, nil
 }
 
@@ -411,18 +1070,7 @@ func (s *OrderService) GetLatestOrderByPatientIDForDoctor(ctx context.Context, p
 	}
 
 	// Convert order items to response format
-	orderItems := make([]dto.OrderItem, len(order.OrderItems))
-	for i, item := range order.OrderItems {
-		medicineName := ""
-		if item.Medicine != nil {
-			medicineName = item.Medicine.Name
-		}
-		orderItems[i] = dto.OrderItem{
-			MedicineID:   item.MedicineID.String(),
-			MedicineName: medicineName,
-			Quantity:     item.Quantity,
-		}
-	}
+	orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 	// Format timestamps
 	var submittedAt, reviewedAt *string
@@ -450,8 +1098,15 @@ func (s *OrderService) GetLatestOrderByPatientIDForDoctor(ctx context.Context, p
 	return &dto.GetOrderByIDResponseDto{
 		OrderID:        order.ID.String(),
 		PatientID:      order.PatientID.String(),
-		DoctorID:       order.DoctorID.String(),
+		DoctorID:       uuidPtrToString(order.DoctorID),
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		CouponCode:     order.CouponCode,
 		TotalAmount:    order.TotalAmount,
+		VATAmount:      order.VATAmount,
+		Entitlement:    order.Entitlement,
+		InsurerAmount:  order.InsurerAmount,
+		PatientAmount:  order.PatientAmount,
 		Note:           order.Note,
 		SubmittedAt:    submittedAt,
 		ReviewedAt:     reviewedAt,
@@ -459,15 +1114,22 @@ func (s *OrderService) GetLatestOrderByPatientIDForDoctor(ctx context.Context, p
 		DeliveryStatus: deliveryStatus,
 		DeliveryAt:     deliveryAt,
 		OrderItems:     orderItems,
+		RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
+		Discounts:      dto.ToOrderDiscountDtoList(order.Discounts),
+		Rejection:      dto.ToOrderRejectionDto(order),
 	}, nil
 }
 
+// CancelOrder cancels an order. Doctors may cancel their own orders until they are paid.
+// Patients may cancel their own orders within the cancellation policy: pending and approved
+// orders free of charge, paid orders until they are dispatched, when the payment is
+// refunded automatically less the cancellation fee.
 func (s *OrderService) CancelOrder(ctx context.Context, body dto.CancelOrderRequestDto) (*dto.CancelOrderResponseDto, error) {
 	userID := contextUtils.GetUserId(ctx)
 	role := contextUtils.GetRole(ctx)
 
-	if role != "doctor" {
-		return nil, apperr.New(apperr.CodeForbidden, "only doctors can cancel orders", nil)
+	if role != "doctor" && role != "patient" {
+		return nil, apperr.New(apperr.CodeForbidden, "only doctors and patients can cancel orders", nil)
 	}
 
 	parsedOrderID, err := uuid.Parse(body.OrderID)
@@ -475,29 +1137,101 @@ func (s *OrderService) CancelOrder(ctx context.Context, body dto.CancelOrderRequ
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
 	}
 
-	order, err := s.orderRepository.FindByID(ctx, parsedOrderID)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "order not found", err)
-	}
-
-	doctorID, err := uuid.Parse(userID)
+	actorID, err := uuid.Parse(userID)
 	if err != nil {
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
 	}
 
-	if order.DoctorID == nil || *order.DoctorID != doctorID {
-		return nil, apperr.New(apperr.CodeForbidden, "doctor can only cancel their own orders", nil)
+	var reason *string
+	if body.Reason != nil && strings.TrimSpace(*body.Reason) != "" {
+		trimmed := strings.TrimSpace(*body.Reason)
+		reason = &trimmed
+	}
+	if role == "patient" && reason == nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "cancellation reason is required", nil)
 	}
 
-	order.Status = models.OrderStatusCancelled
-	if err := s.orderRepository.Update(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to cancel order", err)
+	var refund *models.Refund
+	var order *models.Order
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+
+		switch role {
+		case "doctor":
+			if order.DoctorID == nil || *order.DoctorID != actorID {
+				return apperr.New(apperr.CodeForbidden, "doctor can only cancel their own orders", nil)
+			}
+			switch order.Status {
+			case models.OrderStatusPending, models.OrderStatusChangesRequested, models.OrderStatusApproved:
+			default:
+				return apperr.New(apperr.CodeConflict, "doctors can only cancel orders that have not been paid", nil)
+			}
+		case "patient":
+			if order.PatientID != actorID {
+				return apperr.New(apperr.CodeForbidden, "patient can only cancel their own orders", nil)
+			}
+			if _, ok := cancellableStatuses[order.Status]; !ok {
+				return apperr.New(apperr.CodeConflict, fmt.Sprintf("orders that are %s cannot be cancelled", order.Status), nil)
+			}
+		}
+		if err := checkTransition(order, models.OrderStatusCancelled); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var fee float64
+		if role == "patient" {
+			if fee, err = s.checkCancellationPolicy(ctx, tx, order, now); err != nil {
+				return err
+			}
+		}
+
+		switch order.Status {
+		case models.OrderStatusApproved:
+			if err := releaseOrderItems(ctx, tx, order, "order cancelled"); err != nil {
+				return err
+			}
+		case models.OrderStatusPaid, models.OrderStatusProcessing:
+			refundReason := "order cancelled"
+			if reason != nil {
+				refundReason += ": " + *reason
+			}
+			if refund, _, _, err = s.refundOrder(ctx, tx, order, nil, refundReason, fee); err != nil {
+				return err
+			}
+			order.CancellationFee = roundMoney(fee)
+		}
+
+		from := order.Status
+		order.Status = models.OrderStatusCancelled
+		order.CancellationReason = reason
+		order.CancelledBy = &actorID
+		order.CancelledByRole = &role
+		order.CancelledAt = &now
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to cancel order", err)
+		}
+		return recordStatusChange(ctx, tx, order, &from, reason)
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.payOutNewRefund(ctx, refund)
 
-	return &dto.CancelOrderResponseDto{
-		OrderID: order.ID.String(),
-		Status:  string(order.Status),
-	}, nil
+	res := &dto.CancelOrderResponseDto{
+		OrderID:         order.ID.String(),
+		Status:          string(order.Status),
+		CancellationFee: order.CancellationFee,
+	}
+	if refund != nil {
+		refundDto := dto.ToRefundDto(refund)
+		res.Refund = &refundDto
+	}
+	return res, nil
 }
 
 func (s *OrderService) ApproveOrder(ctx context.Context, body dto.ApproveOrderRequestDto) (*dto.ApproveOrderResponseDto, error) {
@@ -526,24 +1260,74 @@ func (s *OrderService) ApproveOrder(ctx context.Context, body dto.ApproveOrderRe
 	if order.DoctorID == nil || *order.DoctorID != doctorID {
 		return nil, apperr.New(apperr.CodeForbidden, "doctor can only approve their own orders", nil)
 	}
+	if err := checkTransition(order, models.OrderStatusApproved); err != nil {
+		return nil, err
+	}
 
-	// Calculate total amount before approving
-	totalAmount, err := s.calculateOrderTotal(ctx, order.ID)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to calculate order total", err)
+	medicines := make([]*models.Medicine, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.Medicine != nil {
+			medicines = append(medicines, item.Medicine)
+		}
+	}
+	findings := s.runClinicalChecks(ctx, order.PatientID, medicines)
+	if err := blockingClinicalError(findings, body.OverrideReason); err != nil {
+		return nil, err
 	}
 
-	order.Status = models.OrderStatusApproved
-	order.TotalAmount = totalAmount
+	entitlements := s.patientEntitlements(ctx, order.PatientID)
 	reviewedAt := time.Now()
-	order.ReviewedAt = &reviewedAt
-	if err := s.orderRepository.Update(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to approve order", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the checks above ran on an unlocked copy; lock the order and check again so a
+		// concurrent approval cannot reserve the stock twice
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.DoctorID == nil || *order.DoctorID != doctorID {
+			return apperr.New(apperr.CodeForbidden, "doctor can only approve their own orders", nil)
+		}
+		if err := checkTransition(order, models.OrderStatusApproved); err != nil {
+			return err
+		}
+
+		from := order.Status
+		order.Status = models.OrderStatusApproved
+		order.ReviewedAt = &reviewedAt
+		if requiresClinicalOverride(findings) {
+			reason := strings.TrimSpace(*body.OverrideReason)
+			order.ClinicalOverrideReason = &reason
+			order.ClinicalOverriddenAt = &reviewedAt
+		}
+		if err := reserveOrderItems(ctx, tx, order); err != nil {
+			return err
+		}
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to approve order", err)
+		}
+		if err := recordStatusChange(ctx, tx, order, &from, order.ClinicalOverrideReason); err != nil {
+			return err
+		}
+		if _, err := applyDiscounts(ctx, tx, order, reviewedAt); err != nil {
+			return err
+		}
+		if err := applyTax(ctx, tx, order); err != nil {
+			return err
+		}
+		if err := applyCoverage(ctx, tx, order, entitlements, reviewedAt); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.ApproveOrderResponseDto{
-		OrderID: order.ID.String(),
-		Status:  string(order.Status),
+		OrderID:          order.ID.String(),
+		Status:           string(order.Status),
+		ClinicalFindings: dto.ToClinicalFindingDtoList(findings),
 	}, nil
 }
 
@@ -560,40 +1344,267 @@ func (s *OrderService) RejectOrder(ctx context.Context, body dto.RejectOrderRequ
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
 	}
 
-	order, err := s.orderRepository.FindByID(ctx, parsedOrderID)
+	doctorID, err := uuid.Parse(userID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "order not found", err)
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
 	}
 
-	doctorID, err := uuid.Parse(userID)
+	reasonCode := models.RejectionReasonCode(body.ReasonCode)
+	if !reasonCode.IsValid() {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid rejection reason code", nil)
+	}
+	reason := trimmedOrNil(body.Reason)
+	if reasonCode == models.RejectionReasonOther && reason == nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "a reason is required when the reason code is other", nil)
+	}
+
+	var order *models.Order
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the row stays locked so an approval racing with this rejection waits for it
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.DoctorID == nil || *order.DoctorID != doctorID {
+			return apperr.New(apperr.CodeForbidden, "doctor can only reject their own orders", nil)
+		}
+		if err := checkTransition(order, models.OrderStatusRejected); err != nil {
+			return err
+		}
+
+		// Calculate total amount before rejecting
+		totals, err := s.calculateOrderTotal(ctx, order.ID)
+		if err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to calculate order total", err)
+		}
+
+		from := order.Status
+		order.Status = models.OrderStatusRejected
+		order.SubtotalAmount = totals.Subtotal
+		order.TotalAmount = totals.Total
+		order.RejectionReasonCode = &reasonCode
+		order.RejectionReason = reason
+		reviewedAt := time.Now()
+		order.ReviewedAt = &reviewedAt
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to reject order", err)
+		}
+		if reason != nil {
+			if _, err := addOrderComment(ctx, tx, order, models.OrderCommentKindRejection, *reason); err != nil {
+				return err
+			}
+		}
+		return recordStatusChange(ctx, tx, order, &from, reason)
+	})
 	if err != nil {
-		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+		return nil, err
 	}
 
-	if order.DoctorID == nil || *order.DoctorID != doctorID {
-		return nil, apperr.New(apperr.CodeForbidden, "doctor can only reject their own orders", nil)
+	return &dto.RejectOrderResponseDto{
+		OrderID:   order.ID.String(),
+		Status:    string(order.Status),
+		Rejection: dto.ToOrderRejectionDto(order),
+	}, nil
+}
+
+// ApproveControlledOrder records the admin co-sign required before an approved order
+// containing controlled medicines can be paid.
+func (s *OrderService) ApproveControlledOrder(ctx context.Context, body dto.ApproveControlledOrderRequestDto) (*dto.ApproveControlledOrderResponseDto, error) {
+	userID := contextUtils.GetUserId(ctx)
+	role := contextUtils.GetRole(ctx)
+
+	if role != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can co-sign controlled medicine orders", nil)
 	}
 
-	// Calculate total amount before rejecting
-	totalAmount, err := s.calculateOrderTotal(ctx, order.ID)
+	parsedOrderID, err := uuid.Parse(body.OrderID)
 	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to calculate order total", err)
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
 	}
 
-	order.Status = models.OrderStatusRejected
-	order.TotalAmount = totalAmount
-	reviewedAt := time.Now()
-	order.ReviewedAt = &reviewedAt
-	if err := s.orderRepository.Update(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to reject order", err)
+	adminID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
 	}
 
-	return &dto.RejectOrderResponseDto{
-		OrderID: order.ID.String(),
-		Status:  string(order.Status),
+	approvedAt := time.Now()
+	var order *models.Order
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the row stays locked so a payment, cancellation or expiry cannot move the order
+		// on between the checks and the co-sign
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.Status != models.OrderStatusApproved {
+			return apperr.New(apperr.CodeConflict, "order must be approved by the doctor first", nil)
+		}
+		if !order.RequiresSecondaryApproval() {
+			return apperr.New(apperr.CodeBadRequest, "order has no controlled medicines", nil)
+		}
+		if order.ControlledApprovedAt != nil {
+			return apperr.New(apperr.CodeConflict, "order already co-signed", nil)
+		}
+
+		order.ControlledApprovedBy = &adminID
+		order.ControlledApprovedAt = &approvedAt
+		if err := repository.NewOrderRepository(tx).UpdateControlledApproval(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to co-sign order", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ApproveControlledOrderResponseDto{
+		OrderID:              order.ID.String(),
+		Status:               string(order.Status),
+		ControlledApprovedAt: approvedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}, nil
 }
 
+// ApplyCoupon applies a coupon to an approved order before it is paid. The order's
+// discounts and coverage are worked out again with the coupon; a coupon replaces any
+// coupon applied before.
+func (s *OrderService) ApplyCoupon(ctx context.Context, body dto.ApplyCouponRequestDto) (*dto.OrderTotalsResponseDto, error) {
+	patientID, orderID, err := parseCouponRequest(ctx, body.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(body.CouponCode)
+	coupon, err := repository.NewPromotionRepository(s.db).FindByCouponCode(ctx, code)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "coupon not found", err)
+	}
+	now := time.Now()
+	if !coupon.IsRunningAt(now) {
+		return nil, apperr.New(apperr.CodeBadRequest, "coupon is not valid at this time", nil)
+	}
+
+	entitlements := s.patientEntitlements(ctx, patientID)
+	var order *models.Order
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = findOrderForCoupon(ctx, tx, patientID, orderID)
+		if err != nil {
+			return err
+		}
+		order.CouponCode = coupon.CouponCode
+		result, err := applyDiscounts(ctx, tx, order, now)
+		if err != nil {
+			return err
+		}
+		applied := false
+		for _, line := range result.Lines {
+			if line.Promotion.ID == coupon.ID {
+				applied = true
+				break
+			}
+		}
+		if !applied {
+			if coupon.MinOrderAmount != nil && result.Subtotal < *coupon.MinOrderAmount {
+				return apperr.New(apperr.CodeBadRequest, fmt.Sprintf("coupon requires an order of at least %.2f", *coupon.MinOrderAmount), nil)
+			}
+			return apperr.New(apperr.CodeBadRequest, "coupon does not apply to this order or cannot be combined with its promotions", nil)
+		}
+		if err := applyTax(ctx, tx, order); err != nil {
+			return err
+		}
+		return applyCoverage(ctx, tx, order, entitlements, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toOrderTotalsDto(order), nil
+}
+
+// RemoveCoupon takes the coupon off an approved order that has not been paid yet.
+func (s *OrderService) RemoveCoupon(ctx context.Context, body dto.RemoveCouponRequestDto) (*dto.OrderTotalsResponseDto, error) {
+	patientID, orderID, err := parseCouponRequest(ctx, body.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entitlements := s.patientEntitlements(ctx, patientID)
+	var order *models.Order
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = findOrderForCoupon(ctx, tx, patientID, orderID)
+		if err != nil {
+			return err
+		}
+		if order.CouponCode == nil {
+			return apperr.New(apperr.CodeBadRequest, "order has no coupon", nil)
+		}
+		order.CouponCode = nil
+		if _, err := applyDiscounts(ctx, tx, order, now); err != nil {
+			return err
+		}
+		if err := applyTax(ctx, tx, order); err != nil {
+			return err
+		}
+		return applyCoverage(ctx, tx, order, entitlements, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toOrderTotalsDto(order), nil
+}
+
+// parseCouponRequest checks that the current user is a patient and returns their ID and
+// the ID of the order they are changing the coupon of.
+func parseCouponRequest(ctx context.Context, orderID string) (uuid.UUID, uuid.UUID, error) {
+	if contextUtils.GetRole(ctx) != "patient" {
+		return uuid.Nil, uuid.Nil, apperr.New(apperr.CodeForbidden, "only patients can apply coupons", nil)
+	}
+	patientID, err := uuid.Parse(contextUtils.GetUserId(ctx))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
+	}
+	parsedOrderID, err := uuid.Parse(orderID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+	return patientID, parsedOrderID, nil
+}
+
+// findOrderForCoupon loads and locks an order the patient may change the coupon of. The
+// lock keeps a payment from capturing the order while its totals are worked out again.
+func findOrderForCoupon(ctx context.Context, tx *gorm.DB, patientID, orderID uuid.UUID) (*models.Order, error) {
+	order, err := repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, orderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "order not found", err)
+	}
+	if order.PatientID != patientID {
+		return nil, apperr.New(apperr.CodeForbidden, "patient can only change coupons on their own orders", nil)
+	}
+	if order.Status != models.OrderStatusApproved {
+		return nil, apperr.New(apperr.CodeConflict, "coupons can only be changed on approved orders before payment", nil)
+	}
+	return order, nil
+}
+
+func toOrderTotalsDto(order *models.Order) *dto.OrderTotalsResponseDto {
+	return &dto.OrderTotalsResponseDto{
+		OrderID:        order.ID.String(),
+		CouponCode:     order.CouponCode,
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		TotalAmount:    order.TotalAmount,
+		VATAmount:      order.VATAmount,
+		InsurerAmount:  order.InsurerAmount,
+		PatientAmount:  order.PatientAmount,
+		Discounts:      dto.ToOrderDiscountDtoList(order.Discounts),
+	}
+}
+
 func (s *OrderService) PayOrder(ctx context.Context, body dto.PayOrderRequestDto) (*dto.PayOrderResponseDto, error) {
 	userID := contextUtils.GetUserId(ctx)
 	role := contextUtils.GetRole(ctx)
@@ -612,48 +1623,103 @@ func (s *OrderService) PayOrder(ctx context.Context, body dto.PayOrderRequestDto
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
 	}
 
-	// โหลดออเดอร์
-	order, err := s.orderRepository.FindByID(ctx, parsedOrderID)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeNotFound, "order not found", err)
-	}
-
 	patientID, err := uuid.Parse(userID)
 	if err != nil {
 		return nil, apperr.New(apperr.CodeBadRequest, "invalid user ID", err)
 	}
-	if order.PatientID != patientID {
-		return nil, apperr.New(apperr.CodeForbidden, "patient can only pay their own orders", nil)
-	}
-
-	// ตรวจสถานะที่อนุญาตให้จ่ายเงิน
-	switch order.Status {
-	case models.OrderStatusPaid, models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusDelivered:
-		return nil, apperr.New(apperr.CodeConflict, "order already paid or processed", nil)
-	case models.OrderStatusCancelled, models.OrderStatusRejected:
-		return nil, apperr.New(apperr.CodeForbidden, "order cannot be paid in current state", nil)
-	case models.OrderStatusPending, models.OrderStatusApproved:
-		// allowed
-	default:
-		return nil, apperr.New(apperr.CodeBadRequest, "unknown order state", nil)
+	if body.TaxInvoice != nil && !tax.ValidTaxID(body.TaxInvoice.TaxID) {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid buyer tax ID", nil)
 	}
 
-	// คำนวณยอดรวมล่าสุด (กันกรณีมีส่วนลด/ราคาเปลี่ยน)
-	totalAmount, err := s.calculateOrderTotal(ctx, order.ID)
-	if err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to calculate order total", err)
-	}
+	paidAt := time.Now()
+	var order *models.Order
+	var invoice *models.TaxInvoice
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// โหลดออเดอร์; the row stays locked so concurrent payments and expiry wait for this one
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		if order.PatientID != patientID {
+			return apperr.New(apperr.CodeForbidden, "patient can only pay their own orders", nil)
+		}
 
-	order.Status = models.OrderStatusPaid
-	order.TotalAmount = totalAmount
+		// ตรวจสถานะที่อนุญาตให้จ่ายเงิน
+		switch order.Status {
+		case models.OrderStatusPaid, models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusDelivered,
+			models.OrderStatusPartiallyRefunded, models.OrderStatusRefunded:
+			return apperr.New(apperr.CodeConflict, "order already paid or processed", nil)
+		case models.OrderStatusPending, models.OrderStatusChangesRequested:
+			return apperr.New(apperr.CodeForbidden, "order must be approved before payment", nil)
+		case models.OrderStatusCancelled, models.OrderStatusRejected, models.OrderStatusExpired:
+			return apperr.New(apperr.CodeForbidden, "order cannot be paid in current state", nil)
+		case models.OrderStatusApproved:
+			// allowed
+		default:
+			return apperr.New(apperr.CodeBadRequest, "unknown order state", nil)
+		}
+		if order.RequiresSecondaryApproval() && order.ControlledApprovedAt == nil {
+			return apperr.New(apperr.CodeForbidden, "controlled medicines require secondary approval before payment", nil)
+		}
 
-	if err := s.orderRepository.Update(ctx, order); err != nil {
-		return nil, apperr.New(apperr.CodeInternal, "failed to mark order paid", err)
+		// the total and its split were fixed at approval; the patient is only charged their
+		// portion and the delivery fee, and the rest is left as a claim against the entitlement
+		from := order.Status
+		order.Status = models.OrderStatusPaid
+		if err := s.dispenseOrderItems(ctx, tx, order); err != nil {
+			return err
+		}
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to mark order paid", err)
+		}
+		if err := recordStatusChange(ctx, tx, order, &from, nil); err != nil {
+			return err
+		}
+		paymentRepository := repository.NewPaymentRepository(tx)
+		payment := &models.Payment{
+			ID:      utils.GenerateUUIDv7(),
+			OrderID: order.ID,
+			Payer:   models.PaymentPayerPatient,
+			Method:  (*models.PaymentMethod)(body.PaymentMethod),
+			Amount:  order.PatientAmount + order.DeliveryFee,
+			Status:  models.PaymentStatusCaptured,
+		}
+		if err := paymentRepository.Create(ctx, payment); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to record payment", err)
+		}
+		if order.InsurerAmount > 0 {
+			if err := paymentRepository.Create(ctx, &models.Payment{
+				ID:          utils.GenerateUUIDv7(),
+				OrderID:     order.ID,
+				Payer:       models.PaymentPayerInsurer,
+				Entitlement: order.Entitlement,
+				Amount:      order.InsurerAmount,
+				Status:      models.PaymentStatusPending,
+			}); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to record insurer claim", err)
+			}
+		}
+		// VAT is due when the order is paid, so it is worked out again from the medicines'
+		// current treatment before the invoice is issued
+		if err := applyTax(ctx, tx, order); err != nil {
+			return err
+		}
+		invoice, err = s.issueTaxInvoice(ctx, tx, order, payment, body.TaxInvoice, paidAt)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &dto.PayOrderResponseDto{
-		OrderID: order.ID.String(),
-		Status:  string(order.Status),
+		OrderID:       order.ID.String(),
+		Status:        string(order.Status),
+		AmountCharged: order.PatientAmount + order.DeliveryFee,
+		InsurerAmount: order.InsurerAmount,
+		Entitlement:   order.Entitlement,
+		VATAmount:     order.VATAmount,
+		TaxInvoice:    dto.ToTaxInvoiceDto(invoice),
 	}, nil
 }
 
@@ -686,39 +1752,14 @@ func (s *OrderService) GetAllOrdersByDoctorID(ctx context.Context) (*dto.GetAllO
 		}
 	}
 
-	// Fetch patient profiles from user client
-	patientProfiles := make(map[string]*dto.PatientInfo)
-	if len(patientIDs) > 0 {
-		profiles, err := s.userClient.GetPatientByIds(ctx, patientIDs)
-		if err == nil && profiles != nil {
-			for _, profile := range *profiles {
-				patientProfiles[profile.ID] = &dto.PatientInfo{
-					PatientID:   profile.ID,
-					FirstName:   profile.FirstName,
-					LastName:    profile.LastName,
-					Gender:      profile.Gender,
-					PhoneNumber: profile.PhoneNumber,
-				}
-			}
-		}
-	}
+	// Fetch patient profiles through the profile cache
+	patientProfiles := s.getPatientInfos(ctx, patientIDs)
 
 	orderHistoryList := make([]dto.GetAllOrdersForDoctorResponseDto, len(orders))
 
 	for idx, order := range orders {
 		// Convert order items to response format
-		orderItems := make([]dto.OrderItem, len(order.OrderItems))
-		for i, item := range order.OrderItems {
-			medicineName := ""
-			if item.Medicine != nil {
-				medicineName = item.Medicine.Name
-			}
-			orderItems[i] = dto.OrderItem{
-				MedicineID:   item.MedicineID.String(),
-				MedicineName: medicineName,
-				Quantity:     item.Quantity,
-			}
-		}
+		orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 		// Format timestamps
 		var submittedAt, reviewedAt *string
@@ -757,7 +1798,14 @@ func (s *OrderService) GetAllOrdersByDoctorID(ctx context.Context) (*dto.GetAllO
 			PatientID:      order.PatientID.String(),
 			PatientInfo:    patientInfo,
 			DoctorID:       doctorIDStr,
+			SubtotalAmount: order.SubtotalAmount,
+			DiscountAmount: order.DiscountAmount,
+			CouponCode:     order.CouponCode,
 			TotalAmount:    order.TotalAmount,
+			VATAmount:      order.VATAmount,
+			Entitlement:    order.Entitlement,
+			InsurerAmount:  order.InsurerAmount,
+			PatientAmount:  order.PatientAmount,
 			Note:           order.Note,
 			SubmittedAt:    submittedAt,
 			ReviewedAt:     reviewedAt,
@@ -767,6 +1815,7 @@ func (s *OrderService) GetAllOrdersByDoctorID(ctx context.Context) (*dto.GetAllO
 			CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			OrderItems:     orderItems,
+			RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
 		}
 	}
 
@@ -799,8 +1848,8 @@ func (s *OrderService) GetAllOrdersHistoryForDoctor(ctx context.Context, statusF
 			return nil, apperr.New(apperr.CodeInternal, "failed to retrieve orders", err)
 		}
 	} else {
-		// If no filter, get all non-pending orders (approved or rejected)
-		allOrders, err := s.orderRepository.FindByDoctorIDAndStatuses(ctx, doctorID, []models.OrderStatus{models.OrderStatusApproved, models.OrderStatusRejected})
+		// If no filter, get all reviewed orders (approved, rejected or sent back to the patient)
+		allOrders, err := s.orderRepository.FindByDoctorIDAndStatuses(ctx, doctorID, []models.OrderStatus{models.OrderStatusApproved, models.OrderStatusRejected, models.OrderStatusChangesRequested})
 		if err != nil {
 			return nil, apperr.New(apperr.CodeInternal, "failed to retrieve orders", err)
 		}
@@ -819,39 +1868,14 @@ func (s *OrderService) GetAllOrdersHistoryForDoctor(ctx context.Context, statusF
 		}
 	}
 
-	// Fetch patient profiles from user client
-	patientProfiles := make(map[string]*dto.PatientInfo)
-	if len(patientIDs) > 0 {
-		profiles, err := s.userClient.GetPatientByIds(ctx, patientIDs)
-		if err == nil && profiles != nil {
-			for _, profile := range *profiles {
-				patientProfiles[profile.ID] = &dto.PatientInfo{
-					PatientID:   profile.ID,
-					FirstName:   profile.FirstName,
-					LastName:    profile.LastName,
-					Gender:      profile.Gender,
-					PhoneNumber: profile.PhoneNumber,
-				}
-			}
-		}
-	}
+	// Fetch patient profiles through the profile cache
+	patientProfiles := s.getPatientInfos(ctx, patientIDs)
 
 	orderHistoryList := make([]dto.GetAllOrdersForDoctorResponseDto, len(orders))
 
 	for idx, order := range orders {
 		// Convert order items to response format
-		orderItems := make([]dto.OrderItem, len(order.OrderItems))
-		for i, item := range order.OrderItems {
-			medicineName := ""
-			if item.Medicine != nil {
-				medicineName = item.Medicine.Name
-			}
-			orderItems[i] = dto.OrderItem{
-				MedicineID:   item.MedicineID.String(),
-				MedicineName: medicineName,
-				Quantity:     item.Quantity,
-			}
-		}
+		orderItems := dto.ToOrderItemDtoList(order.OrderItems)
 
 		// Format timestamps
 		var submittedAt, reviewedAt *string
@@ -890,7 +1914,14 @@ func (s *OrderService) GetAllOrdersHistoryForDoctor(ctx context.Context, statusF
 			PatientID:      order.PatientID.String(),
 			PatientInfo:    patientInfo,
 			DoctorID:       doctorIDStr,
+			SubtotalAmount: order.SubtotalAmount,
+			DiscountAmount: order.DiscountAmount,
+			CouponCode:     order.CouponCode,
 			TotalAmount:    order.TotalAmount,
+			VATAmount:      order.VATAmount,
+			Entitlement:    order.Entitlement,
+			InsurerAmount:  order.InsurerAmount,
+			PatientAmount:  order.PatientAmount,
 			Note:           order.Note,
 			SubmittedAt:    submittedAt,
 			ReviewedAt:     reviewedAt,
@@ -900,6 +1931,7 @@ func (s *OrderService) GetAllOrdersHistoryForDoctor(ctx context.Context, statusF
 			CreatedAt:      order.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:      order.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			OrderItems:     orderItems,
+			RequestedItems: dto.ToRequestedItemDtoList(order.RequestedItems),
 		}
 	}
 
@@ -908,3 +1940,16 @@ func (s *OrderService) GetAllOrdersHistoryForDoctor(ctx context.Context, statusF
 		Total:  len(orderHistoryList),
 	}, nil
 }
+
+func (s *OrderService) ReloadClinicalTable(ctx context.Context) (*dto.ReloadClinicalTableResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can reload the interaction table", nil)
+	}
+	if err := s.clinicalChecker.Reload(); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to reload interaction table", err)
+	}
+
+	return &dto.ReloadClinicalTableResponseDto{
+		ReloadedAt: time.Now().Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/events"
+	"order-service/pkg/models"
+	"order-service/pkg/realtime"
+	"order-service/pkg/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// events are picked up again for this long after they are written, so an event
+	// whose transaction commits after a later one's is still streamed
+	orderStreamLookback = 10 * time.Second
+	orderStreamPollSize = 1000
+	// most events replayed to a client resuming after Last-Event-ID
+	orderStreamReplayLimit = 500
+)
+
+// OrderStreamService streams order status changes to the patient and doctor of each
+// order. Every replica tails the outbox for events written by any replica and pushes
+// them to its own connections; a client that reconnects resumes after the last event
+// it saw.
+type OrderStreamService struct {
+	outboxEventRepository *repository.OutboxEventRepository
+	hub                   *realtime.Hub
+	pollInterval          time.Duration
+	heartbeatInterval     time.Duration
+}
+
+func NewOrderStreamService(outboxEventRepo *repository.OutboxEventRepository, hub *realtime.Hub, pollInterval, heartbeatInterval time.Duration) *OrderStreamService {
+	return &OrderStreamService{
+		outboxEventRepository: outboxEventRepo,
+		hub:                   hub,
+		pollInterval:          pollInterval,
+		heartbeatInterval:     heartbeatInterval,
+	}
+}
+
+// HeartbeatInterval is how often idle connections are sent a keep-alive.
+func (s *OrderStreamService) HeartbeatInterval() time.Duration {
+	return s.heartbeatInterval
+}
+
+// OrderStream is one client's stream: the events it missed since Last-Event-ID,
+// followed by live events from Live. Close must be called when the client goes away.
+type OrderStream struct {
+	Missed []events.Event
+	Live   <-chan events.Event
+	sub    *realtime.Subscription
+	sent   map[string]bool
+}
+
+// Skip reports whether a live event was already sent among the missed ones.
+func (s *OrderStream) Skip(event events.Event) bool {
+	return s.sent[event.ID]
+}
+
+func (s *OrderStream) Close() {
+	s.sub.Close()
+}
+
+// OpenOrderStream subscribes the user to the events of their orders. With lastEventID,
+// the events written after it are returned to be sent first.
+func (s *OrderStreamService) OpenOrderStream(ctx context.Context, lastEventID string) (*OrderStream, error) {
+	userID := contextUtils.GetUserId(ctx)
+	var after uuid.UUID
+	if lastEventID != "" {
+		id, err := uuid.Parse(lastEventID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid Last-Event-ID", err)
+		}
+		after = id
+	}
+
+	// subscribe before reading what was missed so nothing falls in between
+	sub := s.hub.Subscribe(userID)
+	stream := &OrderStream{Live: sub.C, sub: sub, sent: make(map[string]bool)}
+	if lastEventID == "" {
+		return stream, nil
+	}
+	missed, err := s.outboxEventRepository.FindOrderEventsForUserAfter(ctx, userID, after, orderStreamReplayLimit)
+	if err != nil {
+		sub.Close()
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve missed order events", err)
+	}
+	stream.Missed = make([]events.Event, len(missed))
+	for i := range missed {
+		stream.Missed[i] = missed[i].ToEvent()
+		stream.sent[stream.Missed[i].ID] = true
+	}
+	return stream, nil
+}
+
+// Start tails the outbox and pushes new events to connected users until ctx is done.
+func (s *OrderStreamService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	since := time.Now()
+	seen := make(map[uuid.UUID]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		if s.hub.Empty() {
+			since = now
+			clear(seen)
+			continue
+		}
+
+		written, err := s.outboxEventRepository.FindCreatedSince(ctx, since.Add(-orderStreamLookback), orderStreamPollSize)
+		if err != nil {
+			log.Printf("order stream: failed to read outbox: %v", err)
+			continue
+		}
+		if len(written) == orderStreamPollSize {
+			log.Printf("order stream: read the maximum of %d events in one poll; some may be streamed late", orderStreamPollSize)
+		}
+		for i := range written {
+			if _, ok := seen[written[i].ID]; ok {
+				continue
+			}
+			seen[written[i].ID] = written[i].CreatedAt
+			if recipients := orderEventRecipients(&written[i]); len(recipients) > 0 {
+				s.hub.Publish(recipients, written[i].ToEvent())
+			}
+		}
+		since = now
+		for id, createdAt := range seen {
+			if createdAt.Before(now.Add(-2 * orderStreamLookback)) {
+				delete(seen, id)
+			}
+		}
+	}
+}
+
+// orderEventRecipients returns the patient and doctor of the order an event is about.
+func orderEventRecipients(event *models.OutboxEvent) []string {
+	if event.AggregateType != outboxAggregateOrder {
+		return nil
+	}
+	var payload events.OrderPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		log.Printf("order stream: failed to decode event %s: %v", event.ID, err)
+		return nil
+	}
+	recipients := []string{payload.PatientID}
+	if payload.DoctorID != nil {
+		recipients = append(recipients, *payload.DoctorID)
+	}
+	return recipients
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"order-service/pkg/apperr"
+	"order-service/pkg/events"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const outboxAggregateOrder = "order"
+
+// orderEventTypes returns the events a status change publishes: order.<status>, and
+// order.created first when the order has just been created. Orders created already
+// approved publish both, so consumers of order.approved see them too.
+func orderEventTypes(change *models.OrderStatusChange) []string {
+	status := "order." + string(change.ToStatus)
+	if change.FromStatus != nil {
+		return []string{status}
+	}
+	if change.ToStatus == models.OrderStatusPending {
+		return []string{events.OrderCreated}
+	}
+	return []string{events.OrderCreated, status}
+}
+
+// enqueueOrderEvents writes the events for a status change to the outbox in the
+// transaction that made it; the relay publishes them once it commits.
+func enqueueOrderEvents(ctx context.Context, tx *gorm.DB, order *models.Order, change *models.OrderStatusChange) error {
+	payload := events.OrderPayload{
+		OrderID:       order.ID.String(),
+		PatientID:     order.PatientID.String(),
+		Status:        string(order.Status),
+		TotalAmount:   order.TotalAmount,
+		PatientAmount: order.PatientAmount,
+		InsurerAmount: order.InsurerAmount,
+		DeliveryFee:   order.DeliveryFee,
+		ActorRole:     change.ActorRole,
+		Reason:        change.Reason,
+	}
+	if order.DoctorID != nil {
+		doctorID := order.DoctorID.String()
+		payload.DoctorID = &doctorID
+	}
+	if order.AppointmentID != nil {
+		appointmentID := order.AppointmentID.String()
+		payload.AppointmentID = &appointmentID
+	}
+	if change.FromStatus != nil {
+		from := string(*change.FromStatus)
+		payload.PreviousStatus = &from
+	}
+	if change.ActorID != nil {
+		actorID := change.ActorID.String()
+		payload.ActorID = &actorID
+	}
+	if order.Status == models.OrderStatusRejected && order.RejectionReasonCode != nil {
+		reasonCode := string(*order.RejectionReasonCode)
+		payload.ReasonCode = &reasonCode
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to encode order event", err)
+	}
+
+	outboxRepo := repository.NewOutboxEventRepository(tx)
+	now := time.Now()
+	for _, eventType := range orderEventTypes(change) {
+		event := &models.OutboxEvent{
+			ID:            utils.GenerateUUIDv7(),
+			AggregateType: outboxAggregateOrder,
+			AggregateID:   order.ID,
+			EventType:     eventType,
+			Payload:       data,
+			Status:        models.OutboxEventStatusPending,
+			NextAttemptAt: now,
+		}
+		if err := outboxRepo.Create(ctx, event); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to record order event", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// applyEffectivePrices sets the price of each unit to the price in effect at the given
+// time. Units without a price at that time keep their listed price.
+func applyEffectivePrices(ctx context.Context, priceRepository *repository.MedicinePriceRepository, units []*models.MedicineUnit, at time.Time) error {
+	unitIDs := make([]uuid.UUID, len(units))
+	for i, unit := range units {
+		unitIDs[i] = unit.ID
+	}
+	prices, err := priceRepository.FindEffective(ctx, unitIDs, at)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve medicine prices", err)
+	}
+	priceByUnit := make(map[uuid.UUID]float64, len(prices))
+	for _, price := range prices {
+		priceByUnit[price.UnitID] = price.Price
+	}
+	for _, unit := range units {
+		if price, ok := priceByUnit[unit.ID]; ok {
+			unit.Price = price
+		}
+	}
+	return nil
+}
+
+// applyEffectiveMedicinePrices does the same for the loaded units of each medicine and
+// sets the medicine's price to the price of its base unit.
+func applyEffectiveMedicinePrices(ctx context.Context, priceRepository *repository.MedicinePriceRepository, medicines []*models.Medicine, at time.Time) error {
+	var units []*models.MedicineUnit
+	for _, medicine := range medicines {
+		for i := range medicine.Units {
+			units = append(units, &medicine.Units[i])
+		}
+	}
+	if err := applyEffectivePrices(ctx, priceRepository, units, at); err != nil {
+		return err
+	}
+	for _, medicine := range medicines {
+		for _, unit := range medicine.Units {
+			if unit.IsBase {
+				medicine.Price = unit.Price
+			}
+		}
+	}
+	return nil
+}
+
+// recordPriceChange starts a new price range for the unit at the given time, splitting
+// the range it falls into. The unit must be locked by the caller. Changes that take
+// effect immediately also update the unit's listed price, and the medicine's price for
+// the base unit. The change is attributed to the user making the request.
+func recordPriceChange(ctx context.Context, tx *gorm.DB, unit *models.MedicineUnit, change *models.MedicinePrice, now time.Time) error {
+	priceRepository := repository.NewMedicinePriceRepository(tx)
+	ranges, err := priceRepository.FindByUnitID(ctx, unit.ID)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve medicine prices", err)
+	}
+
+	change.ID = utils.GenerateUUIDv7()
+	change.MedicineID = unit.MedicineID
+	change.UnitID = unit.ID
+	if actorID, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+		change.CreatedBy = &actorID
+	}
+	// postgres keeps microseconds; truncate so range boundaries compare equal after a round trip
+	change.EffectiveFrom = change.EffectiveFrom.Truncate(time.Microsecond)
+	from := change.EffectiveFrom
+	for i := range ranges {
+		current := &ranges[i]
+		if current.EffectiveFrom.Equal(from) {
+			return apperr.New(apperr.CodeConflict, "a price change already starts at that time", nil)
+		}
+		if current.IsEffectiveAt(from) {
+			change.EffectiveTo = current.EffectiveTo
+			if err := priceRepository.UpdateEffectiveTo(ctx, current.ID, &from); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to close the current price", err)
+			}
+			break
+		}
+		if current.EffectiveFrom.After(from) {
+			// the change predates the unit's first price
+			effectiveTo := current.EffectiveFrom
+			change.EffectiveTo = &effectiveTo
+			break
+		}
+	}
+	if err := priceRepository.Create(ctx, change); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to record price change", err)
+	}
+
+	if change.IsEffectiveAt(now) {
+		return applyListedPrice(ctx, tx, unit, change.Price)
+	}
+	return nil
+}
+
+// cancelPriceChange removes a price change that has not taken effect yet and extends the
+// range before it to cover the gap. The unit must be locked by the caller.
+func cancelPriceChange(ctx context.Context, tx *gorm.DB, unit *models.MedicineUnit, priceID uuid.UUID, now time.Time) error {
+	priceRepository := repository.NewMedicinePriceRepository(tx)
+	ranges, err := priceRepository.FindByUnitID(ctx, unit.ID)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve medicine prices", err)
+	}
+
+	for i := range ranges {
+		if ranges[i].ID != priceID {
+			continue
+		}
+		cancelled := &ranges[i]
+		if !cancelled.EffectiveFrom.After(now) {
+			return apperr.New(apperr.CodeConflict, "only price changes that have not taken effect can be cancelled", nil)
+		}
+		if err := priceRepository.Delete(ctx, cancelled.ID); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to cancel price change", err)
+		}
+		if i > 0 {
+			if err := priceRepository.UpdateEffectiveTo(ctx, ranges[i-1].ID, cancelled.EffectiveTo); err != nil {
+				return apperr.New(apperr.CodeInternal, "failed to extend the previous price", err)
+			}
+		}
+		return nil
+	}
+	return apperr.New(apperr.CodeNotFound, "price change not found", nil)
+}
+
+func applyListedPrice(ctx context.Context, tx *gorm.DB, unit *models.MedicineUnit, price float64) error {
+	if err := repository.NewMedicineUnitRepository(tx).UpdatePrice(ctx, unit.ID, price); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to update unit price", err)
+	}
+	if unit.IsBase {
+		if err := repository.NewMedicineRepository(tx).UpdatePrice(ctx, unit.MedicineID, price); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to update medicine price", err)
+		}
+	}
+	unit.Price = price
+	return nil
+}
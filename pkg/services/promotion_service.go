@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PromotionService manages promotions and coupons. Changes apply to orders approved or
+// given a coupon afterwards; discounts already recorded on orders are kept.
+type PromotionService struct {
+	promotionRepository *repository.PromotionRepository
+	medicineRepository  *repository.MedicineRepository
+}
+
+func NewPromotionService(promotionRepo *repository.PromotionRepository, medicineRepo *repository.MedicineRepository) *PromotionService {
+	return &PromotionService{
+		promotionRepository: promotionRepo,
+		medicineRepository:  medicineRepo,
+	}
+}
+
+func (s *PromotionService) GetPromotions(ctx context.Context) (*dto.GetPromotionsResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view promotions", nil)
+	}
+	promotions, err := s.promotionRepository.FindAll(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve promotions", err)
+	}
+	res := &dto.GetPromotionsResponseDto{Promotions: make([]dto.PromotionDto, len(promotions))}
+	for i := range promotions {
+		res.Promotions[i] = dto.ToPromotionDto(&promotions[i])
+	}
+	res.Total = len(res.Promotions)
+	return res, nil
+}
+
+func (s *PromotionService) CreatePromotion(ctx context.Context, body dto.PromotionRequestDto) (*dto.PromotionDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change promotions", nil)
+	}
+	promotion := &models.Promotion{ID: utils.GenerateUUIDv7(), Active: true}
+	if err := s.fillPromotion(ctx, promotion, body); err != nil {
+		return nil, err
+	}
+	if err := s.promotionRepository.Create(ctx, promotion); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to create promotion", err)
+	}
+	return s.getPromotion(ctx, promotion.ID)
+}
+
+func (s *PromotionService) UpdatePromotion(ctx context.Context, promotionID string, body dto.PromotionRequestDto) (*dto.PromotionDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change promotions", nil)
+	}
+	id, err := uuid.Parse(promotionID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid promotion ID", err)
+	}
+	promotion, err := s.promotionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "promotion not found", err)
+	}
+	if err := s.fillPromotion(ctx, promotion, body); err != nil {
+		return nil, err
+	}
+	if err := s.promotionRepository.Update(ctx, promotion); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to update promotion", err)
+	}
+	return s.getPromotion(ctx, promotion.ID)
+}
+
+// DeletePromotion removes a promotion. Discounts it gave keep its name and coupon code.
+func (s *PromotionService) DeletePromotion(ctx context.Context, promotionID string) (*dto.DeletePromotionResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change promotions", nil)
+	}
+	id, err := uuid.Parse(promotionID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid promotion ID", err)
+	}
+	if _, err := s.promotionRepository.FindByID(ctx, id); err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "promotion not found", err)
+	}
+	if err := s.promotionRepository.Delete(ctx, id); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to delete promotion", err)
+	}
+	return &dto.DeletePromotionResponseDto{
+		PromotionID: id.String(),
+		Status:      "deleted",
+	}, nil
+}
+
+// fillPromotion validates the request and copies it onto the promotion.
+func (s *PromotionService) fillPromotion(ctx context.Context, promotion *models.Promotion, body dto.PromotionRequestDto) error {
+	name := strings.TrimSpace(body.Name)
+	if name == "" {
+		return apperr.New(apperr.CodeBadRequest, "name is required", nil)
+	}
+	kind := models.PromotionKind(body.Kind)
+	if kind == models.PromotionKindPercentage && body.Value > 100 {
+		return apperr.New(apperr.CodeBadRequest, "a percentage discount cannot exceed 100", nil)
+	}
+	scope := models.PromotionScope(body.Scope)
+	switch {
+	case scope == models.PromotionScopeMedicine && body.MedicineID == nil:
+		return apperr.New(apperr.CodeBadRequest, "medicine_id is required for medicine promotions", nil)
+	case scope == models.PromotionScopeOrder && body.MedicineID != nil:
+		return apperr.New(apperr.CodeBadRequest, "order promotions cannot have a medicine_id", nil)
+	}
+	if body.MedicineID != nil {
+		if _, err := s.medicineRepository.FindByID(ctx, *body.MedicineID); err != nil {
+			return apperr.New(apperr.CodeNotFound, "medicine not found", err)
+		}
+	}
+
+	var couponCode *string
+	if body.CouponCode != nil {
+		code := strings.ToUpper(strings.TrimSpace(*body.CouponCode))
+		if code == "" || strings.ContainsAny(code, " \t\n") {
+			return apperr.New(apperr.CodeBadRequest, "coupon code must be a single word", nil)
+		}
+		existing, err := s.promotionRepository.FindByCouponCode(ctx, code)
+		if err == nil && existing.ID != promotion.ID {
+			return apperr.New(apperr.CodeConflict, "coupon code is already in use", nil)
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return apperr.New(apperr.CodeInternal, "failed to check coupon code", err)
+		}
+		couponCode = &code
+	}
+
+	startsAt := time.Now()
+	if body.StartsAt != nil {
+		startsAt, _ = time.Parse("2006-01-02T15:04:05Z07:00", *body.StartsAt)
+	} else if !promotion.StartsAt.IsZero() {
+		startsAt = promotion.StartsAt
+	}
+	var endsAt *time.Time
+	if body.EndsAt != nil {
+		t, _ := time.Parse("2006-01-02T15:04:05Z07:00", *body.EndsAt)
+		if !t.After(startsAt) {
+			return apperr.New(apperr.CodeBadRequest, "ends_at must be after starts_at", nil)
+		}
+		endsAt = &t
+	}
+
+	promotion.Name = name
+	promotion.Kind = kind
+	promotion.Value = body.Value
+	promotion.Scope = scope
+	promotion.MedicineID = body.MedicineID
+	promotion.CouponCode = couponCode
+	promotion.MinOrderAmount = body.MinOrderAmount
+	promotion.MaxDiscount = body.MaxDiscount
+	promotion.UsageLimit = body.UsageLimit
+	promotion.PerPatientLimit = body.PerPatientLimit
+	promotion.Stackable = body.Stackable
+	if body.Active != nil {
+		promotion.Active = *body.Active
+	}
+	promotion.StartsAt = startsAt
+	promotion.EndsAt = endsAt
+	return nil
+}
+
+func (s *PromotionService) getPromotion(ctx context.Context, id uuid.UUID) (*dto.PromotionDto, error) {
+	promotion, err := s.promotionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve promotion", err)
+	}
+	res := dto.ToPromotionDto(promotion)
+	return &res, nil
+}
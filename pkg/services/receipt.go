@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"order-service/pkg/apperr"
+	"order-service/pkg/cache"
+	"order-service/pkg/models"
+	"order-service/pkg/receipt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReceiptConfig sets up PDF receipts. Receipts cannot be rendered while Renderer is nil;
+// documents rendered before are still served.
+type ReceiptConfig struct {
+	Renderer *receipt.Renderer
+	Cache    cache.Store
+	CacheTTL time.Duration
+}
+
+// Receipt is a rendered receipt ready to download.
+type Receipt struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// GetReceiptPDF returns the PDF receipt of the latest tax invoice issued for an order.
+// An invoice is rendered the first time it is downloaded and the document is kept, so
+// every later download is the same file even after the layout or fonts change.
+func (s *OrderService) GetReceiptPDF(ctx context.Context, orderID string) (*Receipt, error) {
+	order, err := s.findReadableOrder(ctx, orderID, "invoices")
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.taxInvoiceRepository.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve tax invoices", err)
+	}
+	if len(invoices) == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, "no receipt has been issued for this order", nil)
+	}
+	invoice := &invoices[len(invoices)-1]
+	res := &Receipt{Filename: invoice.Number + ".pdf", ContentType: receipt.ContentType}
+
+	cacheKey := "receipt:" + invoice.ID.String()
+	if s.receipts.Cache != nil {
+		if entry, ok := s.receipts.Cache.Get(cacheKey); ok {
+			res.Content = entry.Value
+			return res, nil
+		}
+	}
+
+	document, err := s.taxInvoiceRepository.FindDocument(ctx, invoice.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if document, err = s.renderReceipt(ctx, invoice); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve receipt", err)
+	}
+
+	if s.receipts.Cache != nil {
+		expiresAt := time.Now().Add(s.receipts.CacheTTL)
+		s.receipts.Cache.Set(cacheKey, cache.Entry{Value: document.Content, ExpiresAt: expiresAt, StaleUntil: expiresAt})
+	}
+	res.Content = document.Content
+	return res, nil
+}
+
+// renderReceipt renders an invoice and keeps the document.
+func (s *OrderService) renderReceipt(ctx context.Context, invoice *models.TaxInvoice) (*models.TaxInvoiceDocument, error) {
+	if s.receipts.Renderer == nil {
+		return nil, apperr.New(apperr.CodeInternal, "receipt fonts are not configured", nil)
+	}
+	content, err := s.receipts.Renderer.Render(invoice)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to render receipt", err)
+	}
+	sum := sha256.Sum256(content)
+	document, err := s.taxInvoiceRepository.CreateDocument(ctx, &models.TaxInvoiceDocument{
+		TaxInvoiceID: invoice.ID,
+		ContentType:  receipt.ContentType,
+		Content:      content,
+		SHA256:       hex.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to keep receipt", err)
+	}
+	return document, nil
+}
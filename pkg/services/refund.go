@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"order-service/pkg/apperr"
+	"order-service/pkg/constants"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/payments"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefundOrder gives back part or all of a paid order. Staff and admins list the items
+// and quantities that come back, or none to refund everything not refunded yet. Once
+// every item has been refunded the order is refunded; until then it is partially refunded.
+func (s *OrderService) RefundOrder(ctx context.Context, body dto.RefundOrderRequestDto) (*dto.RefundOrderResponseDto, error) {
+	role := contextUtils.GetRole(ctx)
+	if role != constants.RoleAdmin && role != constants.RoleStaff {
+		return nil, apperr.New(apperr.CodeForbidden, "only staff and admins can refund orders", nil)
+	}
+
+	parsedOrderID, err := uuid.Parse(body.OrderID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid order ID", err)
+	}
+	reason := strings.TrimSpace(body.Reason)
+	if reason == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "refund reason is required", nil)
+	}
+	requested := make(map[uuid.UUID]float64, len(body.Items))
+	for _, item := range body.Items {
+		orderItemID, err := uuid.Parse(item.OrderItemID)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeBadRequest, "invalid order item ID", err)
+		}
+		if item.Quantity <= 0 {
+			return nil, apperr.New(apperr.CodeBadRequest, "refund quantity must be positive", nil)
+		}
+		requested[orderItemID] += item.Quantity
+	}
+
+	var (
+		order          *models.Order
+		refund         *models.Refund
+		refundedAmount float64
+	)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		order, err = repository.NewOrderRepository(tx).FindByIDForUpdate(ctx, parsedOrderID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "order not found", err)
+		}
+		var full bool
+		refund, full, refundedAmount, err = s.refundOrder(ctx, tx, order, requested, reason, 0)
+		if err != nil {
+			return err
+		}
+
+		next := models.OrderStatusPartiallyRefunded
+		if full {
+			next = models.OrderStatusRefunded
+		}
+		if err := checkTransition(order, next); err != nil {
+			return err
+		}
+		from := order.Status
+		order.Status = next
+		if err := repository.NewOrderRepository(tx).Update(ctx, order); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to mark order refunded", err)
+		}
+		return recordStatusChange(ctx, tx, order, &from, &reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.payOutNewRefund(ctx, refund)
+
+	return &dto.RefundOrderResponseDto{
+		OrderID:        order.ID.String(),
+		Status:         string(order.Status),
+		Refund:         dto.ToRefundDto(refund),
+		RefundedAmount: refundedAmount,
+	}, nil
+}
+
+// refundOrder refunds the requested quantity of each order item, or everything not
+// refunded yet when requested is empty, and reports whether the whole order has now been
+// refunded along with everything paid back on it so far. A fee, if any, is kept from the
+// patient's payment when the whole order is refunded. The items are put back into the
+// batches they were dispensed from, the patient's share of them is recorded as a pending
+// refund and the insurer's share is taken off its pending claim. When the last items come
+// back the rest of the payment, delivery fee included, is refunded with them and the
+// insurer claim is voided. The order must be locked by tx. The payment provider is not
+// called here: the caller pays the refund out with payOutRefund once tx has committed,
+// so a failed commit never leaves money paid back without a refund on record.
+func (s *OrderService) refundOrder(ctx context.Context, tx *gorm.DB, order *models.Order, requested map[uuid.UUID]float64, reason string, fee float64) (*models.Refund, bool, float64, error) {
+	switch order.Status {
+	case models.OrderStatusPaid, models.OrderStatusProcessing, models.OrderStatusShipped, models.OrderStatusDelivered, models.OrderStatusPartiallyRefunded:
+	default:
+		return nil, false, 0, apperr.New(apperr.CodeConflict, "only paid orders can be refunded", nil)
+	}
+
+	paymentRepository := repository.NewPaymentRepository(tx)
+	orderPayments, err := paymentRepository.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, false, 0, apperr.New(apperr.CodeInternal, "failed to retrieve payments", err)
+	}
+	var patientPayment, insurerClaim *models.Payment
+	for i := range orderPayments {
+		switch {
+		case orderPayments[i].Payer == models.PaymentPayerPatient && orderPayments[i].Status == models.PaymentStatusCaptured:
+			patientPayment = &orderPayments[i]
+		case orderPayments[i].Payer == models.PaymentPayerInsurer && orderPayments[i].Status == models.PaymentStatusPending:
+			insurerClaim = &orderPayments[i]
+		}
+	}
+	if patientPayment == nil {
+		return nil, false, 0, apperr.New(apperr.CodeConflict, "order has no captured payment to refund", nil)
+	}
+
+	refundRepository := repository.NewRefundRepository(tx)
+	previous, err := refundRepository.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, false, 0, apperr.New(apperr.CodeInternal, "failed to retrieve refunds", err)
+	}
+	var refundedAmount float64
+	refundedQuantity := make(map[uuid.UUID]float64)
+	for _, earlier := range previous {
+		refundedAmount += earlier.Amount
+		for _, item := range earlier.Items {
+			refundedQuantity[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	shares, err := orderItemShares(ctx, tx, order)
+	if err != nil {
+		return nil, false, 0, err
+	}
+
+	itemsByID := make(map[uuid.UUID]*models.OrderItem, len(order.OrderItems))
+	for i := range order.OrderItems {
+		itemsByID[order.OrderItems[i].ID] = &order.OrderItems[i]
+	}
+	for orderItemID := range requested {
+		if _, ok := itemsByID[orderItemID]; !ok {
+			return nil, false, 0, apperr.New(apperr.CodeBadRequest, "order item "+orderItemID.String()+" is not part of the order", nil)
+		}
+	}
+
+	refund := &models.Refund{
+		ID:        utils.GenerateUUIDv7(),
+		OrderID:   order.ID,
+		PaymentID: patientPayment.ID,
+		Reason:    reason,
+		ActorRole: stockActorSystem,
+	}
+	if role := contextUtils.GetRole(ctx); role != "" {
+		refund.ActorRole = role
+	}
+	if actorID, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+		refund.ActorID = &actorID
+	}
+
+	full := true
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		left := item.Quantity - refundedQuantity[item.ID]
+		quantity := left
+		if len(requested) > 0 {
+			quantity = requested[item.ID]
+		}
+		if quantity > left+stockEpsilon {
+			return nil, false, 0, apperr.New(apperr.CodeConflict, fmt.Sprintf("only %s of order item %s is left to refund", strconv.FormatFloat(left, 'f', -1, 64), item.ID), nil)
+		}
+		if left-quantity > stockEpsilon {
+			full = false
+		}
+		if quantity <= stockEpsilon {
+			continue
+		}
+
+		share := shares[item.ID]
+		refundItem := models.RefundItem{
+			ID:          utils.GenerateUUIDv7(),
+			RefundID:    refund.ID,
+			OrderItemID: item.ID,
+			Quantity:    quantity,
+			Amount:      roundMoney(share.patient * quantity / item.Quantity),
+		}
+		if insurerClaim != nil {
+			refundItem.InsurerAmount = roundMoney(share.insurer * quantity / item.Quantity)
+		}
+		refund.Items = append(refund.Items, refundItem)
+		refund.Amount += refundItem.Amount
+		refund.InsurerAmount += refundItem.InsurerAmount
+
+		unitFactor := item.BaseQuantity() / item.Quantity
+		if err := returnOrderItemStock(ctx, tx, order, item, quantity*unitFactor, refundedQuantity[item.ID]*unitFactor, "order refunded: "+reason); err != nil {
+			return nil, false, 0, err
+		}
+	}
+
+	// rounding leftovers and the delivery fee go back with the last items
+	paidLeft := roundMoney(patientPayment.Amount - refundedAmount)
+	refund.Amount = math.Min(roundMoney(refund.Amount), paidLeft)
+	if full {
+		refund.Amount = math.Max(roundMoney(paidLeft-fee), 0)
+	}
+	if insurerClaim != nil {
+		refund.InsurerAmount = math.Min(roundMoney(refund.InsurerAmount), insurerClaim.Amount)
+		if full {
+			refund.InsurerAmount = insurerClaim.Amount
+		}
+	}
+	if len(refund.Items) == 0 && refund.Amount <= 0 {
+		return nil, false, 0, apperr.New(apperr.CodeConflict, "nothing is left to refund on this order", nil)
+	}
+	refund.Status = models.RefundStatusCompleted
+	if refund.Amount > 0 {
+		refund.Status = models.RefundStatusPending
+	}
+
+	if err := refundRepository.Create(ctx, refund); err != nil {
+		return nil, false, 0, apperr.New(apperr.CodeInternal, "failed to record refund", err)
+	}
+	if full {
+		patientPayment.Status = models.PaymentStatusRefunded
+		if err := paymentRepository.UpdateRefund(ctx, patientPayment); err != nil {
+			return nil, false, 0, apperr.New(apperr.CodeInternal, "failed to update payment", err)
+		}
+	}
+	if insurerClaim != nil && refund.InsurerAmount > 0 {
+		insurerClaim.Amount = roundMoney(insurerClaim.Amount - refund.InsurerAmount)
+		if full {
+			insurerClaim.Status = models.PaymentStatusVoided
+		}
+		if err := paymentRepository.UpdateRefund(ctx, insurerClaim); err != nil {
+			return nil, false, 0, apperr.New(apperr.CodeInternal, "failed to update insurer claim", err)
+		}
+	}
+
+	return refund, full, roundMoney(refundedAmount + refund.Amount), nil
+}
+
+// payOutRefund pays a committed refund back to the patient through the payment provider.
+// The refund ID is the provider's idempotency key, so paying out a refund again after a
+// failure or a crash never pays it out twice.
+func (s *OrderService) payOutRefund(ctx context.Context, refund *models.Refund) error {
+	refundRepository := repository.NewRefundRepository(s.db)
+	payment, err := repository.NewPaymentRepository(s.db).FindByID(ctx, refund.PaymentID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve payment %s: %w", refund.PaymentID, err)
+	}
+	req := payments.RefundRequest{
+		RefundID:  refund.ID.String(),
+		PaymentID: payment.ID.String(),
+		OrderID:   refund.OrderID.String(),
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+	}
+	if payment.Method != nil {
+		req.Method = string(*payment.Method)
+	}
+	result, err := s.paymentProvider.Refund(ctx, req)
+	if err != nil {
+		lastError := err.Error()
+		refund.LastError = &lastError
+		if saveErr := refundRepository.SavePayout(ctx, refund); saveErr != nil {
+			log.Printf("failed to record payout failure of refund %s: %v", refund.ID, saveErr)
+		}
+		return fmt.Errorf("payment provider failed to refund: %w", err)
+	}
+	refund.Status = models.RefundStatusCompleted
+	refund.ProviderReference = &result.Reference
+	refund.LastError = nil
+	if err := refundRepository.SavePayout(ctx, refund); err != nil {
+		return fmt.Errorf("failed to record payout of refund %s: %w", refund.ID, err)
+	}
+	return nil
+}
+
+// payOutNewRefund pays out a refund just committed, if it has anything to pay back. A
+// refund that fails to pay out stays pending for PayOutPendingRefunds to retry, so the
+// request that made it still succeeds.
+func (s *OrderService) payOutNewRefund(ctx context.Context, refund *models.Refund) {
+	if refund == nil || refund.Status != models.RefundStatusPending {
+		return
+	}
+	if err := s.payOutRefund(ctx, refund); err != nil {
+		log.Printf("refund %s is pending payout: %v", refund.ID, err)
+	}
+}
+
+// PayOutPendingRefunds retries the payout of up to limit refunds recorded before the
+// given time that are still pending. It returns how many it found; refunds that fail
+// again are logged and retried on the next run.
+func (s *OrderService) PayOutPendingRefunds(ctx context.Context, before time.Time, limit int) (int, error) {
+	refunds, err := repository.NewRefundRepository(s.db).FindPendingBefore(ctx, before, limit)
+	if err != nil {
+		return 0, apperr.New(apperr.CodeInternal, "failed to retrieve pending refunds", err)
+	}
+	for i := range refunds {
+		if err := s.payOutRefund(ctx, &refunds[i]); err != nil {
+			log.Printf("failed to pay out refund %s: %v", refunds[i].ID, err)
+		}
+	}
+	return len(refunds), nil
+}
+
+// itemShare is how an order item's price after discounts was split between the patient
+// and the insurer.
+type itemShare struct {
+	patient float64
+	insurer float64
+}
+
+// orderItemShares returns the split of each order item recorded when the order was
+// approved. Items without coverage were paid by the patient in full.
+func orderItemShares(ctx context.Context, tx *gorm.DB, order *models.Order) (map[uuid.UUID]itemShare, error) {
+	coverageLines, err := repository.NewOrderCoverageLineRepository(tx).FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve order coverage", err)
+	}
+	taxLines, err := repository.NewOrderTaxLineRepository(tx).FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve order tax", err)
+	}
+
+	shares := make(map[uuid.UUID]itemShare, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		shares[item.ID] = itemShare{patient: item.LineTotal()}
+	}
+	for _, line := range taxLines {
+		shares[line.OrderItemID] = itemShare{patient: line.Amount}
+	}
+	for _, line := range coverageLines {
+		shares[line.OrderItemID] = itemShare{patient: line.PatientAmount, insurer: line.InsurerAmount}
+	}
+	return shares, nil
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order-service/pkg/apperr"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// beginTestTx opens a transaction that is rolled back when the test ends. The stock
+// ledger is append-only, so refund tests cannot clean up after themselves otherwise.
+func beginTestTx(t *testing.T, db *gorm.DB) *gorm.DB {
+	t.Helper()
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// refundFixture is a paid order of two items: 10 tablets at 10 baht, dispensed 6 from
+// the first batch and 4 from the second, and 2 bottles at 25 baht from a third batch,
+// with a 40 baht delivery fee. The patient paid 190.
+type refundFixture struct {
+	order          *models.Order
+	tablets        *models.OrderItem
+	bottles        *models.OrderItem
+	tabletMedicine uuid.UUID
+	batches        [3]uuid.UUID
+	patientPayment *models.Payment
+}
+
+func createRefundFixture(t *testing.T, tx *gorm.DB) *refundFixture {
+	t.Helper()
+	create := func(value any) {
+		t.Helper()
+		if err := tx.Create(value).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", value, err)
+		}
+	}
+
+	f := &refundFixture{}
+	medicines := [2]uuid.UUID{utils.GenerateUUIDv7(), utils.GenerateUUIDv7()}
+	for i, name := range []string{"Test tablets", "Test syrup"} {
+		create(&models.Medicine{ID: medicines[i], SKU: "TEST-" + medicines[i].String(), Name: name, Price: 10, Unit: "unit", Classification: models.DrugClassificationOTC})
+	}
+	f.tabletMedicine = medicines[0]
+
+	expiry := time.Now().AddDate(1, 0, 0)
+	for i, medicineID := range []uuid.UUID{medicines[0], medicines[0], medicines[1]} {
+		f.batches[i] = utils.GenerateUUIDv7()
+		create(&models.MedicineBatch{ID: f.batches[i], MedicineID: medicineID, LotNumber: f.batches[i].String(), ExpiryDate: &expiry, ReceivedAt: time.Now()})
+	}
+
+	orderID := utils.GenerateUUIDv7()
+	create(&models.Order{
+		ID:             orderID,
+		PatientID:      utils.GenerateUUIDv7(),
+		SubtotalAmount: 150,
+		TotalAmount:    150,
+		PatientAmount:  150,
+		DeliveryFee:    40,
+		Status:         models.OrderStatusPaid,
+	})
+	items := []models.OrderItem{
+		{ID: utils.GenerateUUIDv7(), OrderID: orderID, MedicineID: medicines[0], Quantity: 10, UnitFactor: 1, UnitPrice: 10},
+		{ID: utils.GenerateUUIDv7(), OrderID: orderID, MedicineID: medicines[1], Quantity: 2, UnitFactor: 1, UnitPrice: 25},
+	}
+	create(&items)
+	dispensedAt := time.Now()
+	for i, dispensed := range []struct {
+		item     uuid.UUID
+		quantity float64
+	}{{items[0].ID, 6}, {items[0].ID, 4}, {items[1].ID, 2}} {
+		create(&models.OrderItemBatch{ID: utils.GenerateUUIDv7(), OrderItemID: dispensed.item, BatchID: f.batches[i], Quantity: dispensed.quantity, CreatedAt: dispensedAt.Add(time.Duration(i) * time.Second)})
+	}
+	method := models.PaymentMethodCard
+	f.patientPayment = &models.Payment{ID: utils.GenerateUUIDv7(), OrderID: orderID, Payer: models.PaymentPayerPatient, Method: &method, Amount: 190, Status: models.PaymentStatusCaptured}
+	create(f.patientPayment)
+
+	f.reload(t, tx)
+	if f.tablets == nil || f.bottles == nil {
+		t.Fatalf("fixture order has %d items", len(f.order.OrderItems))
+	}
+	return f
+}
+
+// reload locks and reloads the order, as the refund's caller does.
+func (f *refundFixture) reload(t *testing.T, tx *gorm.DB) {
+	t.Helper()
+	id := f.patientPayment.OrderID
+	order, err := repository.NewOrderRepository(tx).FindByIDForUpdate(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load order: %v", err)
+	}
+	f.order = order
+	for i := range order.OrderItems {
+		switch order.OrderItems[i].MedicineID {
+		case f.tabletMedicine:
+			f.tablets = &order.OrderItems[i]
+		default:
+			f.bottles = &order.OrderItems[i]
+		}
+	}
+}
+
+// addInsurerClaim splits the tablets 80/20 between the insurer and the patient, leaving
+// the patient 110 to pay with the delivery fee.
+func (f *refundFixture) addInsurerClaim(t *testing.T, tx *gorm.DB, claimAmount float64) *models.Payment {
+	t.Helper()
+	lines := []models.OrderCoverageLine{
+		{ID: utils.GenerateUUIDv7(), OrderID: f.order.ID, OrderItemID: f.tablets.ID, LineTotal: 100, InsurerAmount: 80, PatientAmount: 20},
+		{ID: utils.GenerateUUIDv7(), OrderID: f.order.ID, OrderItemID: f.bottles.ID, LineTotal: 50, InsurerAmount: 0, PatientAmount: 50},
+	}
+	if err := tx.Create(&lines).Error; err != nil {
+		t.Fatalf("failed to create coverage lines: %v", err)
+	}
+	claim := &models.Payment{ID: utils.GenerateUUIDv7(), OrderID: f.order.ID, Payer: models.PaymentPayerInsurer, Amount: claimAmount, Status: models.PaymentStatusPending}
+	if err := tx.Create(claim).Error; err != nil {
+		t.Fatalf("failed to create insurer claim: %v", err)
+	}
+	if err := tx.Model(f.patientPayment).Update("amount", 110).Error; err != nil {
+		t.Fatalf("failed to update patient payment: %v", err)
+	}
+	return claim
+}
+
+func batchQuantities(t *testing.T, tx *gorm.DB, ids [3]uuid.UUID) [3]float64 {
+	t.Helper()
+	var quantities [3]float64
+	for i, id := range ids {
+		var batch models.MedicineBatch
+		if err := tx.First(&batch, "id = ?", id).Error; err != nil {
+			t.Fatalf("failed to load batch: %v", err)
+		}
+		quantities[i] = batch.Quantity
+	}
+	return quantities
+}
+
+func findPayment(t *testing.T, tx *gorm.DB, id uuid.UUID) *models.Payment {
+	t.Helper()
+	payment, err := repository.NewPaymentRepository(tx).FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to load payment: %v", err)
+	}
+	return payment
+}
+
+func TestRefundOrderPartialThenFull(t *testing.T) {
+	tx := beginTestTx(t, openTestDB(t))
+	ctx := context.Background()
+	s := &OrderService{}
+	f := createRefundFixture(t, tx)
+
+	// 5 of the 10 tablets come back: the 4 from the batch dispensed last, then 1 more
+	refund, full, refunded, err := s.refundOrder(ctx, tx, f.order, map[uuid.UUID]float64{f.tablets.ID: 5}, "damaged", 0)
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if full || refund.Amount != 50 || refunded != 50 || len(refund.Items) != 1 {
+		t.Errorf("partial refund: full %t, amount %v, refunded %v, %d items; want partial, 50, 50, 1 item", full, refund.Amount, refunded, len(refund.Items))
+	}
+	if refund.Status != models.RefundStatusPending {
+		t.Errorf("partial refund status = %s, want pending", refund.Status)
+	}
+	if got := batchQuantities(t, tx, f.batches); got != [3]float64{1, 4, 0} {
+		t.Errorf("batches after the partial refund = %v, want [1 4 0]", got)
+	}
+	if payment := findPayment(t, tx, f.patientPayment.ID); payment.Status != models.PaymentStatusCaptured {
+		t.Errorf("payment status after the partial refund = %s, want captured", payment.Status)
+	}
+
+	// asking for more than is left is refused
+	f.reload(t, tx)
+	_, _, _, err = s.refundOrder(ctx, tx, f.order, map[uuid.UUID]float64{f.tablets.ID: 6}, "too many", 0)
+	if !apperr.IsCode(err, apperr.CodeConflict) {
+		t.Errorf("refunding 6 of 5 tablets left: err = %v, want conflict", err)
+	}
+
+	// the rest: 5 tablets and 2 bottles, plus the delivery fee
+	f.reload(t, tx)
+	refund, full, refunded, err = s.refundOrder(ctx, tx, f.order, nil, "cancelled delivery", 0)
+	if err != nil {
+		t.Fatalf("full refund: %v", err)
+	}
+	if !full || refund.Amount != 140 || refunded != 190 || len(refund.Items) != 2 {
+		t.Errorf("full refund: full %t, amount %v, refunded %v, %d items; want full, 140, 190, 2 items", full, refund.Amount, refunded, len(refund.Items))
+	}
+	// the walk-back skips what the first refund returned to the last batch
+	if got := batchQuantities(t, tx, f.batches); got != [3]float64{6, 4, 2} {
+		t.Errorf("batches after the full refund = %v, want [6 4 2]", got)
+	}
+	var stock float64
+	if err := tx.Model(&models.Medicine{}).Where("id = ?", f.tabletMedicine).Pluck("stock", &stock).Error; err != nil {
+		t.Fatalf("failed to load stock: %v", err)
+	}
+	if stock != 10 {
+		t.Errorf("tablet stock = %v, want 10", stock)
+	}
+	if payment := findPayment(t, tx, f.patientPayment.ID); payment.Status != models.PaymentStatusRefunded {
+		t.Errorf("payment status after the full refund = %s, want refunded", payment.Status)
+	}
+
+	f.reload(t, tx)
+	if _, _, _, err := s.refundOrder(ctx, tx, f.order, nil, "again", 0); !apperr.IsCode(err, apperr.CodeConflict) {
+		t.Errorf("refunding a refunded order: err = %v, want conflict", err)
+	}
+}
+
+func TestRefundOrderKeepsFee(t *testing.T) {
+	tx := beginTestTx(t, openTestDB(t))
+	ctx := context.Background()
+	s := &OrderService{}
+
+	tests := []struct {
+		name      string
+		requested func(f *refundFixture) map[uuid.UUID]float64
+		fee       float64
+		want      float64
+		wantFull  bool
+	}{
+		{"full refund less the fee", func(*refundFixture) map[uuid.UUID]float64 { return nil }, 20, 170, true},
+		{"fee larger than the payment", func(*refundFixture) map[uuid.UUID]float64 { return nil }, 500, 0, true},
+		// the fee is only kept from a refund that closes the order
+		{"partial refund keeps no fee", func(f *refundFixture) map[uuid.UUID]float64 {
+			return map[uuid.UUID]float64{f.bottles.ID: 1}
+		}, 20, 25, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := createRefundFixture(t, tx)
+			refund, full, _, err := s.refundOrder(ctx, tx, f.order, tt.requested(f), "cancelled", tt.fee)
+			if err != nil {
+				t.Fatalf("refund: %v", err)
+			}
+			if full != tt.wantFull || refund.Amount != tt.want {
+				t.Errorf("full %t, amount %v; want %t, %v", full, refund.Amount, tt.wantFull, tt.want)
+			}
+			wantStatus := models.RefundStatusPending
+			if tt.want == 0 {
+				wantStatus = models.RefundStatusCompleted
+			}
+			if refund.Status != wantStatus {
+				t.Errorf("status = %s, want %s", refund.Status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestRefundOrderInsurerShare(t *testing.T) {
+	tx := beginTestTx(t, openTestDB(t))
+	ctx := context.Background()
+	s := &OrderService{}
+
+	t.Run("taken off the claim and voided with the last items", func(t *testing.T) {
+		f := createRefundFixture(t, tx)
+		claim := f.addInsurerClaim(t, tx, 80)
+
+		refund, _, _, err := s.refundOrder(ctx, tx, f.order, map[uuid.UUID]float64{f.tablets.ID: 5}, "damaged", 0)
+		if err != nil {
+			t.Fatalf("partial refund: %v", err)
+		}
+		if refund.Amount != 10 || refund.InsurerAmount != 40 {
+			t.Errorf("partial refund: patient %v, insurer %v; want 10, 40", refund.Amount, refund.InsurerAmount)
+		}
+		if got := findPayment(t, tx, claim.ID); got.Amount != 40 || got.Status != models.PaymentStatusPending {
+			t.Errorf("claim after the partial refund: %v %s, want 40 pending", got.Amount, got.Status)
+		}
+
+		f.reload(t, tx)
+		refund, full, refunded, err := s.refundOrder(ctx, tx, f.order, nil, "cancelled", 0)
+		if err != nil {
+			t.Fatalf("full refund: %v", err)
+		}
+		if !full || refund.Amount != 100 || refunded != 110 || refund.InsurerAmount != 40 {
+			t.Errorf("full refund: full %t, patient %v, refunded %v, insurer %v; want full, 100, 110, 40", full, refund.Amount, refunded, refund.InsurerAmount)
+		}
+		if got := findPayment(t, tx, claim.ID); got.Amount != 0 || got.Status != models.PaymentStatusVoided {
+			t.Errorf("claim after the full refund: %v %s, want 0 voided", got.Amount, got.Status)
+		}
+	})
+
+	t.Run("capped at what is left of the claim", func(t *testing.T) {
+		f := createRefundFixture(t, tx)
+		claim := f.addInsurerClaim(t, tx, 30)
+
+		refund, _, _, err := s.refundOrder(ctx, tx, f.order, map[uuid.UUID]float64{f.tablets.ID: 5}, "damaged", 0)
+		if err != nil {
+			t.Fatalf("refund: %v", err)
+		}
+		if refund.InsurerAmount != 30 {
+			t.Errorf("insurer amount = %v, want 30", refund.InsurerAmount)
+		}
+		if got := findPayment(t, tx, claim.ID); got.Amount != 0 {
+			t.Errorf("claim amount = %v, want 0", got.Amount)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"math"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	stockActorSystem = "system"
+	// stockEpsilon absorbs float error in quantities, which are stored to two decimals
+	stockEpsilon = 0.005
+)
+
+// recordStockMovement appends the movement to the ledger and applies its deltas to the
+// medicine. Every change to medicines.stock and medicines.reserved goes through here, and
+// tx must be the transaction that makes the change so the two can never disagree.
+// The actor is taken from the request context; calls without a user are recorded as system.
+func recordStockMovement(ctx context.Context, tx *gorm.DB, movement *models.StockMovement) error {
+	movement.ID = utils.GenerateUUIDv7()
+	if movement.ActorRole == "" {
+		movement.ActorRole = stockActorSystem
+		if role := contextUtils.GetRole(ctx); role != "" {
+			movement.ActorRole = role
+		}
+		if actorID, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+			movement.ActorID = &actorID
+		}
+	}
+
+	if err := repository.NewStockMovementRepository(tx).Create(ctx, movement); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to record stock movement", err)
+	}
+	if err := repository.NewMedicineRepository(tx).ApplyStockDelta(ctx, movement.MedicineID, movement.StockDelta, movement.ReservedDelta); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to update medicine stock", err)
+	}
+	return nil
+}
+
+// reserveOrderItems holds stock for every item of an order that has just been approved.
+func reserveOrderItems(ctx context.Context, tx *gorm.DB, order *models.Order) error {
+	medicineRepository := repository.NewMedicineRepository(tx)
+	for _, item := range order.OrderItems {
+		medicine, err := medicineRepository.FindByIDForUpdate(ctx, item.MedicineID)
+		if err != nil {
+			return apperr.New(apperr.CodeNotFound, "medicine not found", err)
+		}
+		if medicine.Available() < item.BaseQuantity() {
+			return apperr.New(apperr.CodeConflict, "insufficient stock to reserve "+medicine.Name, nil)
+		}
+		orderID := order.ID
+		if err := recordStockMovement(ctx, tx, &models.StockMovement{
+			MedicineID:    item.MedicineID,
+			OrderID:       &orderID,
+			Type:          models.StockMovementReserve,
+			ReservedDelta: item.BaseQuantity(),
+			Reason:        "order approved",
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseOrderItems drops the hold placed by reserveOrderItems.
+func releaseOrderItems(ctx context.Context, tx *gorm.DB, order *models.Order, reason string) error {
+	for _, item := range order.OrderItems {
+		orderID := order.ID
+		if err := recordStockMovement(ctx, tx, &models.StockMovement{
+			MedicineID:    item.MedicineID,
+			OrderID:       &orderID,
+			Type:          models.StockMovementRelease,
+			ReservedDelta: -item.BaseQuantity(),
+			Reason:        reason,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// returnOrderItemStock puts quantity, in the medicine's base unit, of a dispensed order
+// item back into the batches it was taken from, the batch dispensed last first.
+// alreadyReturned is how much of the item was put back before, so repeated returns never
+// put more into a batch than was taken from it.
+func returnOrderItemStock(ctx context.Context, tx *gorm.DB, order *models.Order, item *models.OrderItem, quantity, alreadyReturned float64, reason string) error {
+	itemBatches, err := repository.NewOrderItemBatchRepository(tx).FindByOrderItemID(ctx, item.ID)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve batch allocations", err)
+	}
+	batchRepository := repository.NewMedicineBatchRepository(tx)
+	for i := len(itemBatches) - 1; i >= 0 && quantity > stockEpsilon; i-- {
+		left := itemBatches[i].Quantity - alreadyReturned
+		if left <= 0 {
+			alreadyReturned -= itemBatches[i].Quantity
+			continue
+		}
+		alreadyReturned = 0
+		back := math.Min(left, quantity)
+		quantity -= back
+
+		if err := batchRepository.AddQuantity(ctx, itemBatches[i].BatchID, back); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to update medicine batch", err)
+		}
+		batchID, orderID := itemBatches[i].BatchID, order.ID
+		if err := recordStockMovement(ctx, tx, &models.StockMovement{
+			MedicineID: item.MedicineID,
+			BatchID:    &batchID,
+			OrderID:    &orderID,
+			Type:       models.StockMovementReturn,
+			StockDelta: back,
+			Reason:     reason,
+		}); err != nil {
+			return err
+		}
+	}
+	if quantity > stockEpsilon {
+		return apperr.New(apperr.CodeConflict, "cannot return more stock than was dispensed", nil)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"math"
+	"order-service/pkg/apperr"
+	"order-service/pkg/dto"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/tax"
+	"order-service/pkg/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// applyTax works out the VAT included in an order's items after its discounts, replaces
+// the tax lines recorded on it and saves its VAT amount. It runs after applyDiscounts.
+func applyTax(ctx context.Context, tx *gorm.DB, order *models.Order) error {
+	medicineIDs := make([]uuid.UUID, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		medicineIDs = append(medicineIDs, item.MedicineID)
+	}
+	medicines, err := repository.NewMedicineRepository(tx).FindByIDs(ctx, medicineIDs)
+	if err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to retrieve medicines", err)
+	}
+	treatments := make(map[uuid.UUID]models.VATTreatment, len(medicines))
+	for _, medicine := range medicines {
+		treatments[medicine.ID] = medicine.VATTreatment
+	}
+
+	result := tax.Calculate(tax.Items(order.OrderItems, order.Discounts, treatments))
+
+	taxLineRepository := repository.NewOrderTaxLineRepository(tx)
+	if err := taxLineRepository.DeleteByOrderID(ctx, order.ID); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to clear order tax", err)
+	}
+	for _, line := range result.Lines {
+		if err := taxLineRepository.Create(ctx, &models.OrderTaxLine{
+			ID:            utils.GenerateUUIDv7(),
+			OrderID:       order.ID,
+			OrderItemID:   line.OrderItemID,
+			VATTreatment:  line.Treatment,
+			VATRate:       line.Rate,
+			Amount:        line.Amount,
+			TaxableAmount: line.TaxableAmount,
+			VATAmount:     line.VATAmount,
+		}); err != nil {
+			return apperr.New(apperr.CodeInternal, "failed to record order tax", err)
+		}
+	}
+
+	order.VATAmount = result.VATAmount
+	if err := repository.NewOrderRepository(tx).UpdateTax(ctx, order); err != nil {
+		return apperr.New(apperr.CodeInternal, "failed to save order tax", err)
+	}
+	return nil
+}
+
+// issueTaxInvoice issues the tax invoice for the patient's payment of an order, taking
+// the next running number of its document type. A full tax invoice is made out when the
+// buyer gave their tax details, an abbreviated receipt in the patient's name otherwise.
+// Order items must be loaded with their medicine and unit.
+func (s *OrderService) issueTaxInvoice(ctx context.Context, tx *gorm.DB, order *models.Order, payment *models.Payment, buyer *dto.TaxInvoiceBuyerDto, issuedAt time.Time) (*models.TaxInvoice, error) {
+	taxLines, err := repository.NewOrderTaxLineRepository(tx).FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve order tax", err)
+	}
+	taxByItem := make(map[uuid.UUID]models.OrderTaxLine, len(taxLines))
+	for _, line := range taxLines {
+		taxByItem[line.OrderItemID] = line
+	}
+	discountByItem := make(map[uuid.UUID]float64)
+	for _, discount := range order.Discounts {
+		discountByItem[discount.OrderItemID] += discount.Amount
+	}
+
+	invoice := &models.TaxInvoice{
+		ID:             utils.GenerateUUIDv7(),
+		DocumentType:   models.TaxDocumentTypeReceipt,
+		Year:           issuedAt.Year(),
+		OrderID:        order.ID,
+		PaymentID:      payment.ID,
+		SellerName:     s.seller.Name,
+		SellerTaxID:    s.seller.TaxID,
+		SellerBranch:   s.seller.Branch,
+		SellerAddress:  s.seller.Address,
+		SubtotalAmount: order.SubtotalAmount,
+		DiscountAmount: order.DiscountAmount,
+		VATRate:        models.StandardVATRate,
+		VATAmount:      order.VATAmount,
+		TotalAmount:    order.TotalAmount,
+		DeliveryFee:    order.DeliveryFee,
+		PaymentMethod:  payment.Method,
+		Entitlement:    order.Entitlement,
+		InsurerAmount:  order.InsurerAmount,
+		PatientAmount:  order.PatientAmount,
+		IssuedAt:       issuedAt,
+	}
+	if buyer != nil {
+		branch := tax.HeadOfficeBranch
+		if buyer.Branch != nil {
+			branch = *buyer.Branch
+		}
+		address := strings.TrimSpace(buyer.Address)
+		invoice.DocumentType = models.TaxDocumentTypeTaxInvoice
+		invoice.BuyerName = strings.TrimSpace(buyer.Name)
+		invoice.BuyerTaxID = &buyer.TaxID
+		invoice.BuyerBranch = &branch
+		invoice.BuyerAddress = &address
+	} else {
+		invoice.BuyerName = order.PatientID.String()
+		if patientInfo, ok := s.getPatientInfos(ctx, []string{order.PatientID.String()})[order.PatientID.String()]; ok {
+			invoice.BuyerName = patientInfo.FirstName + " " + patientInfo.LastName
+		}
+	}
+
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		taxLine, ok := taxByItem[item.ID]
+		if !ok {
+			return nil, apperr.New(apperr.CodeInternal, "order tax has not been worked out", nil)
+		}
+		description, baseUnit := item.MedicineID.String(), ""
+		if item.Medicine != nil {
+			description, baseUnit = item.Medicine.Name, item.Medicine.Unit
+			if strength := item.Medicine.Strength(); strength != "" {
+				description += " " + strength
+			}
+		}
+		unitLabel := baseUnit
+		if item.Unit != nil {
+			unitLabel = item.Unit.Label(baseUnit)
+		}
+		if taxLine.VATRate > 0 {
+			invoice.VatableAmount += taxLine.TaxableAmount
+		} else {
+			invoice.ExemptAmount += taxLine.TaxableAmount
+		}
+		invoice.Lines = append(invoice.Lines, models.TaxInvoiceLine{
+			ID:             utils.GenerateUUIDv7(),
+			TaxInvoiceID:   invoice.ID,
+			LineNo:         i + 1,
+			OrderItemID:    item.ID,
+			Description:    description,
+			Quantity:       item.Quantity,
+			UnitLabel:      unitLabel,
+			UnitPrice:      item.UnitPrice,
+			DiscountAmount: discountByItem[item.ID],
+			Amount:         taxLine.Amount,
+			VATTreatment:   taxLine.VATTreatment,
+			VATAmount:      taxLine.VATAmount,
+		})
+	}
+
+	invoice.VatableAmount = math.Round(invoice.VatableAmount*100) / 100
+	invoice.ExemptAmount = math.Round(invoice.ExemptAmount*100) / 100
+
+	taxInvoiceRepository := repository.NewTaxInvoiceRepository(tx)
+	invoice.Sequence, err = taxInvoiceRepository.NextSequence(ctx, invoice.DocumentType, invoice.Year)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to number tax invoice", err)
+	}
+	invoice.Number = invoice.DocumentType.FormatNumber(invoice.Year, invoice.Sequence)
+	if err := taxInvoiceRepository.Create(ctx, invoice); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to issue tax invoice", err)
+	}
+	return invoice, nil
+}
+
+// GetTaxInvoices returns the tax invoices issued for an order.
+func (s *OrderService) GetTaxInvoices(ctx context.Context, orderID string) (*dto.GetTaxInvoicesResponseDto, error) {
+	order, err := s.findReadableOrder(ctx, orderID, "invoices")
+	if err != nil {
+		return nil, err
+	}
+
+	invoices, err := s.taxInvoiceRepository.FindByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve tax invoices", err)
+	}
+	res := &dto.GetTaxInvoicesResponseDto{Invoices: make([]dto.TaxInvoiceDto, len(invoices))}
+	for i := range invoices {
+		res.Invoices[i] = dto.ToTaxInvoiceDto(&invoices[i])
+	}
+	return res, nil
+}
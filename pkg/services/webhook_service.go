@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"order-service/pkg/apperr"
+	contextUtils "order-service/pkg/context"
+	"order-service/pkg/dto"
+	"order-service/pkg/events"
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"order-service/pkg/webhooks"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultWebhookDeliveryLimit = 100
+	webhookSecretPrefix         = "whsec_"
+	// extra time a claimed batch of deliveries is held for on top of their send timeouts
+	webhookLeaseMargin = time.Minute
+)
+
+// WebhookConfig controls how deliveries are retried.
+type WebhookConfig struct {
+	// attempts before a delivery is marked failed
+	MaxAttempts int
+	// failed deliveries in a row before the subscription is disabled; never when 0
+	DisableAfterFailures int
+}
+
+// WebhookService manages partner webhook subscriptions and delivers events to them. It
+// is a publisher for the outbox relay: each event is queued as a delivery for every
+// active subscription whose filter matches, and deliveries are sent by DeliverDue.
+type WebhookService struct {
+	db                            *gorm.DB
+	webhookSubscriptionRepository *repository.WebhookSubscriptionRepository
+	webhookDeliveryRepository     *repository.WebhookDeliveryRepository
+	sender                        *webhooks.Sender
+	config                        WebhookConfig
+}
+
+func NewWebhookService(db *gorm.DB, webhookSubscriptionRepo *repository.WebhookSubscriptionRepository, webhookDeliveryRepo *repository.WebhookDeliveryRepository, sender *webhooks.Sender, config WebhookConfig) *WebhookService {
+	return &WebhookService{
+		db:                            db,
+		webhookSubscriptionRepository: webhookSubscriptionRepo,
+		webhookDeliveryRepository:     webhookDeliveryRepo,
+		sender:                        sender,
+		config:                        config,
+	}
+}
+
+func (s *WebhookService) GetWebhookSubscriptions(ctx context.Context) (*dto.GetWebhookSubscriptionsResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view webhook subscriptions", nil)
+	}
+	subscriptions, err := s.webhookSubscriptionRepository.FindAll(ctx)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve webhook subscriptions", err)
+	}
+	res := &dto.GetWebhookSubscriptionsResponseDto{Subscriptions: make([]dto.WebhookSubscriptionDto, len(subscriptions))}
+	for i := range subscriptions {
+		res.Subscriptions[i] = dto.ToWebhookSubscriptionDto(&subscriptions[i])
+	}
+	res.Total = len(res.Subscriptions)
+	return res, nil
+}
+
+func (s *WebhookService) CreateWebhookSubscription(ctx context.Context, body dto.WebhookSubscriptionRequestDto) (*dto.WebhookSubscriptionDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change webhook subscriptions", nil)
+	}
+	name, endpoint, filter, err := validateWebhookSubscription(body.Name, body.URL, body.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := webhookSecret(body.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.WebhookSubscription{
+		ID:         utils.GenerateUUIDv7(),
+		Name:       name,
+		URL:        endpoint,
+		Secret:     secret,
+		EventTypes: filter,
+		Active:     true,
+	}
+	if adminID, err := uuid.Parse(contextUtils.GetUserId(ctx)); err == nil {
+		subscription.CreatedBy = &adminID
+	}
+	if err := s.webhookSubscriptionRepository.Create(ctx, subscription); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to create webhook subscription", err)
+	}
+	return s.getWebhookSubscription(ctx, subscription.ID, &secret)
+}
+
+func (s *WebhookService) UpdateWebhookSubscription(ctx context.Context, subscriptionID string, body dto.UpdateWebhookSubscriptionRequestDto) (*dto.WebhookSubscriptionDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change webhook subscriptions", nil)
+	}
+	subscription, err := s.findWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	name, endpoint, filter, err := validateWebhookSubscription(body.Name, body.URL, body.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Name = name
+	subscription.URL = endpoint
+	subscription.EventTypes = filter
+	if body.Active && !subscription.Active {
+		subscription.ConsecutiveFailures = 0
+		subscription.DisabledAt = nil
+		subscription.DisabledReason = nil
+	}
+	subscription.Active = body.Active
+	var newSecret *string
+	if body.Secret != nil {
+		secret, err := webhookSecret(body.Secret)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+		newSecret = &secret
+	}
+	if err := s.webhookSubscriptionRepository.Update(ctx, subscription); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to update webhook subscription", err)
+	}
+	return s.getWebhookSubscription(ctx, subscription.ID, newSecret)
+}
+
+func (s *WebhookService) DeleteWebhookSubscription(ctx context.Context, subscriptionID string) (*dto.DeleteWebhookSubscriptionResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can change webhook subscriptions", nil)
+	}
+	subscription, err := s.findWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookSubscriptionRepository.Delete(ctx, subscription.ID); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to delete webhook subscription", err)
+	}
+	return &dto.DeleteWebhookSubscriptionResponseDto{
+		SubscriptionID: subscription.ID.String(),
+		Status:         "deleted",
+	}, nil
+}
+
+// GetWebhookDeliveries returns the delivery log of a subscription, newest first.
+func (s *WebhookService) GetWebhookDeliveries(ctx context.Context, subscriptionID, status string, limit int) (*dto.GetWebhookDeliveriesResponseDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can view webhook deliveries", nil)
+	}
+	subscription, err := s.findWebhookSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	switch models.WebhookDeliveryStatus(status) {
+	case "", models.WebhookDeliveryStatusPending, models.WebhookDeliveryStatusDelivered, models.WebhookDeliveryStatusFailed:
+	default:
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid delivery status", nil)
+	}
+	if limit <= 0 {
+		limit = defaultWebhookDeliveryLimit
+	}
+
+	deliveries, err := s.webhookDeliveryRepository.FindBySubscriptionID(ctx, subscription.ID, status, limit)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve webhook deliveries", err)
+	}
+	res := &dto.GetWebhookDeliveriesResponseDto{
+		SubscriptionID: subscription.ID.String(),
+		Deliveries:     make([]dto.WebhookDeliveryDto, len(deliveries)),
+	}
+	for i := range deliveries {
+		res.Deliveries[i] = dto.ToWebhookDeliveryDto(&deliveries[i])
+	}
+	res.Total = len(res.Deliveries)
+	return res, nil
+}
+
+// ReplayWebhookDelivery sends a delivery again with the same ID and body, whatever the
+// outcome of earlier attempts. It is sent on the next delivery run.
+func (s *WebhookService) ReplayWebhookDelivery(ctx context.Context, deliveryID string) (*dto.WebhookDeliveryDto, error) {
+	if contextUtils.GetRole(ctx) != "admin" {
+		return nil, apperr.New(apperr.CodeForbidden, "only admins can replay webhook deliveries", nil)
+	}
+	id, err := uuid.Parse(deliveryID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid webhook delivery ID", err)
+	}
+	delivery, err := s.webhookDeliveryRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "webhook delivery not found", err)
+	}
+	subscription, err := s.webhookSubscriptionRepository.FindByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve webhook subscription", err)
+	}
+	if !subscription.Active {
+		return nil, apperr.New(apperr.CodeConflict, "the webhook subscription is disabled; enable it before replaying deliveries", nil)
+	}
+
+	if err := s.webhookDeliveryRepository.Requeue(ctx, delivery.ID, time.Now()); err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to replay webhook delivery", err)
+	}
+	delivery, err = s.webhookDeliveryRepository.FindByID(ctx, delivery.ID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve webhook delivery", err)
+	}
+	res := dto.ToWebhookDeliveryDto(delivery)
+	return &res, nil
+}
+
+// Publish queues the event for every active subscription that wants it. An event the
+// relay publishes again is not queued twice.
+func (s *WebhookService) Publish(ctx context.Context, event events.Event) error {
+	eventID, err := uuid.Parse(event.ID)
+	if err != nil {
+		return fmt.Errorf("invalid event ID %q: %w", event.ID, err)
+	}
+	subscriptions, err := s.webhookSubscriptionRepository.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve webhook subscriptions: %w", err)
+	}
+	var body []byte
+	for i := range subscriptions {
+		if !subscriptions[i].EventTypes.Matches(event.Type) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode event: %w", err)
+			}
+		}
+		delivery := &models.WebhookDelivery{
+			ID:             utils.GenerateUUIDv7(),
+			SubscriptionID: subscriptions[i].ID,
+			EventID:        eventID,
+			EventType:      event.Type,
+			Body:           body,
+			Status:         models.WebhookDeliveryStatusPending,
+			NextAttemptAt:  time.Now(),
+		}
+		if err := s.webhookDeliveryRepository.CreateIfAbsent(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeliverDue sends up to limit deliveries that are due and returns how many it took.
+// The deliveries are claimed in a short transaction and sent outside it, each result
+// saved as soon as it is known. Failed deliveries are retried with backoff until they
+// run out of attempts.
+func (s *WebhookService) DeliverDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.claimDueDeliveries(ctx, limit)
+	if err != nil || len(due) == 0 {
+		return len(due), err
+	}
+
+	subscriptionIDs := make([]uuid.UUID, 0, len(due))
+	for i := range due {
+		subscriptionIDs = append(subscriptionIDs, due[i].SubscriptionID)
+	}
+	subscriptions, err := s.webhookSubscriptionRepository.FindByIDs(ctx, subscriptionIDs)
+	if err != nil {
+		return len(due), err
+	}
+	byID := make(map[uuid.UUID]*models.WebhookSubscription, len(subscriptions))
+	for i := range subscriptions {
+		byID[subscriptions[i].ID] = &subscriptions[i]
+	}
+
+	for i := range due {
+		subscription := byID[due[i].SubscriptionID]
+		// skip the rest of a batch for a subscription disabled part-way through it
+		if subscription == nil || !subscription.Active {
+			continue
+		}
+		if err := s.deliver(ctx, subscription, &due[i]); err != nil {
+			return len(due), err
+		}
+	}
+	return len(due), nil
+}
+
+// claimDueDeliveries locks up to limit due deliveries just long enough to lease them for
+// as long as sending all of them may take.
+func (s *WebhookService) claimDueDeliveries(ctx context.Context, limit int) ([]models.WebhookDelivery, error) {
+	var due []models.WebhookDelivery
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveryRepo := repository.NewWebhookDeliveryRepository(tx)
+		now := time.Now()
+		var err error
+		due, err = deliveryRepo.FindDueForUpdate(ctx, now, limit)
+		if err != nil || len(due) == 0 {
+			return err
+		}
+		ids := make([]uuid.UUID, len(due))
+		for i := range due {
+			ids[i] = due[i].ID
+		}
+		return deliveryRepo.Lease(ctx, ids, now.Add(webhookLeaseMargin+time.Duration(len(due))*webhooks.SendTimeout))
+	})
+	return due, err
+}
+
+func (s *WebhookService) deliver(ctx context.Context, subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) error {
+	statusCode, sendErr := s.sender.Send(ctx, webhooks.Delivery{
+		ID:        delivery.ID.String(),
+		EventType: delivery.EventType,
+		URL:       subscription.URL,
+		Secret:    subscription.Secret,
+		Body:      delivery.Body,
+	})
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliveryStatusDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		if err := s.webhookDeliveryRepository.SaveAttempt(ctx, delivery); err != nil {
+			return err
+		}
+		subscription.ConsecutiveFailures = 0
+		return s.webhookSubscriptionRepository.RecordSuccess(ctx, subscription.ID)
+	}
+
+	lastError := sendErr.Error()
+	delivery.LastError = &lastError
+	delivery.NextAttemptAt = now.Add(webhooks.Backoff(delivery.Attempts))
+	if delivery.Attempts >= s.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryStatusFailed
+		log.Printf("webhook delivery %s to %s failed %d times, giving up: %v", delivery.ID, subscription.URL, delivery.Attempts, sendErr)
+	}
+	if err := s.webhookDeliveryRepository.SaveAttempt(ctx, delivery); err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("disabled after %d failed deliveries in a row; last error: %s", s.config.DisableAfterFailures, lastError)
+	disabled, err := s.webhookSubscriptionRepository.RecordFailure(ctx, subscription.ID, s.config.DisableAfterFailures, reason, now)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		subscription.Active = false
+		log.Printf("webhook subscription %s (%s) disabled after %d failed deliveries", subscription.ID, subscription.URL, s.config.DisableAfterFailures)
+	}
+	return nil
+}
+
+func (s *WebhookService) findWebhookSubscription(ctx context.Context, subscriptionID string) (*models.WebhookSubscription, error) {
+	id, err := uuid.Parse(subscriptionID)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid webhook subscription ID", err)
+	}
+	subscription, err := s.webhookSubscriptionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeNotFound, "webhook subscription not found", err)
+	}
+	return subscription, nil
+}
+
+// getWebhookSubscription returns the saved subscription, with its secret when it has
+// just been set.
+func (s *WebhookService) getWebhookSubscription(ctx context.Context, id uuid.UUID, secret *string) (*dto.WebhookSubscriptionDto, error) {
+	subscription, err := s.webhookSubscriptionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeInternal, "failed to retrieve webhook subscription", err)
+	}
+	res := dto.ToWebhookSubscriptionDto(subscription)
+	res.Secret = secret
+	return &res, nil
+}
+
+func validateWebhookSubscription(name, endpoint string, eventTypes []string) (string, string, models.EventFilter, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", nil, apperr.New(apperr.CodeBadRequest, "name is required", nil)
+	}
+	endpoint = strings.TrimSpace(endpoint)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return "", "", nil, apperr.New(apperr.CodeBadRequest, "url must be an http or https URL", err)
+	}
+	filter := make(models.EventFilter, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if eventType == "" || strings.ContainsAny(eventType, " \t") {
+			return "", "", nil, apperr.New(apperr.CodeBadRequest, "invalid event type "+eventType, nil)
+		}
+		if strings.Contains(eventType, "*") && eventType != "*" && (!strings.HasSuffix(eventType, ".*") || strings.Count(eventType, "*") > 1) {
+			return "", "", nil, apperr.New(apperr.CodeBadRequest, "event type patterns must end in .*: "+eventType, nil)
+		}
+		filter = append(filter, eventType)
+	}
+	return name, endpoint, filter, nil
+}
+
+// webhookSecret returns the secret an admin chose, or a new random one.
+func webhookSecret(secret *string) (string, error) {
+	if secret != nil {
+		if s := strings.TrimSpace(*secret); s != "" {
+			return s, nil
+		}
+	}
+	b, err := utils.GenerateRandomByte(32)
+	if err != nil {
+		return "", apperr.New(apperr.CodeInternal, "failed to generate webhook secret", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"order-service/pkg/models"
+	"order-service/pkg/repository"
+	"order-service/pkg/utils"
+	"order-service/pkg/webhooks"
+
+	"github.com/pressly/goose/v3"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the database in TEST_DATABASE_URL and migrates it. Tests that
+// need Postgres are skipped when it is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get the test database handle: %v", err)
+	}
+	if err := goose.SetDialect("postgres"); err != nil {
+		t.Fatalf("goose set dialect: %v", err)
+	}
+	if err := goose.Up(sqlDB, "../db/migrations"); err != nil {
+		t.Fatalf("failed to migrate the test database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// createTestWebhook stores a subscription for url and one pending delivery to it, and
+// removes both when the test ends.
+func createTestWebhook(t *testing.T, db *gorm.DB, url string) (*models.WebhookSubscription, *models.WebhookDelivery) {
+	t.Helper()
+	ctx := context.Background()
+	subscription := &models.WebhookSubscription{
+		ID:         utils.GenerateUUIDv7(),
+		Name:       "test receiver",
+		URL:        url,
+		Secret:     "whsec_test",
+		EventTypes: models.EventFilter{"order.*"},
+		Active:     true,
+	}
+	if err := repository.NewWebhookSubscriptionRepository(db).Create(ctx, subscription); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	delivery := &models.WebhookDelivery{
+		ID:             utils.GenerateUUIDv7(),
+		SubscriptionID: subscription.ID,
+		EventID:        utils.GenerateUUIDv7(),
+		EventType:      "order.paid",
+		Body:           []byte(`{"type":"order.paid"}`),
+		Status:         models.WebhookDeliveryStatusPending,
+		NextAttemptAt:  time.Now(),
+	}
+	if err := repository.NewWebhookDeliveryRepository(db).CreateIfAbsent(ctx, delivery); err != nil {
+		t.Fatalf("failed to create delivery: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("subscription_id = ?", subscription.ID).Delete(&models.WebhookDelivery{})
+		db.Where("id = ?", subscription.ID).Delete(&models.WebhookSubscription{})
+	})
+	return subscription, delivery
+}
+
+func TestDeliverDisablesSubscriptionAfterFailures(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	var requests, badSignatures atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+		if !webhooks.Verify("whsec_test", timestamp, body, r.Header.Get(webhooks.HeaderSignature)) {
+			badSignatures.Add(1)
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	subscription, delivery := createTestWebhook(t, db, receiver.URL)
+	deliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	subscriptionRepo := repository.NewWebhookSubscriptionRepository(db)
+	s := NewWebhookService(db, subscriptionRepo, deliveryRepo, webhooks.NewSender(), WebhookConfig{MaxAttempts: 10, DisableAfterFailures: 3})
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if err := s.deliver(ctx, subscription, delivery); err != nil {
+			t.Fatalf("attempt %d: deliver: %v", attempt, err)
+		}
+
+		saved, err := deliveryRepo.FindByID(ctx, delivery.ID)
+		if err != nil {
+			t.Fatalf("attempt %d: failed to reload delivery: %v", attempt, err)
+		}
+		if saved.Attempts != attempt {
+			t.Errorf("attempt %d: attempts = %d", attempt, saved.Attempts)
+		}
+		if saved.Status != models.WebhookDeliveryStatusPending {
+			t.Errorf("attempt %d: status = %s, want pending", attempt, saved.Status)
+		}
+		if saved.LastStatusCode == nil || *saved.LastStatusCode != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: last status code = %v, want 503", attempt, saved.LastStatusCode)
+		}
+		if wait := saved.NextAttemptAt.Sub(*saved.LastAttemptAt); wait.Round(time.Second) != webhooks.Backoff(attempt) {
+			t.Errorf("attempt %d: next attempt after %s, want %s", attempt, wait, webhooks.Backoff(attempt))
+		}
+
+		stored, err := subscriptionRepo.FindByID(ctx, subscription.ID)
+		if err != nil {
+			t.Fatalf("attempt %d: failed to reload subscription: %v", attempt, err)
+		}
+		if stored.ConsecutiveFailures != attempt {
+			t.Errorf("attempt %d: consecutive failures = %d", attempt, stored.ConsecutiveFailures)
+		}
+		if wantActive := attempt < 3; stored.Active != wantActive || subscription.Active != wantActive {
+			t.Errorf("attempt %d: active = %t (in memory %t), want %t", attempt, stored.Active, subscription.Active, wantActive)
+		}
+		if attempt == 3 && (stored.DisabledAt == nil || stored.DisabledReason == nil) {
+			t.Errorf("disabled subscription has no disabled_at or disabled_reason")
+		}
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("receiver got %d requests, want 3", got)
+	}
+	if got := badSignatures.Load(); got != 0 {
+		t.Errorf("%d requests had an invalid signature", got)
+	}
+}
+
+func TestDeliverResetsFailuresOnSuccess(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	var fail atomic.Bool
+	fail.Store(true)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	subscription, delivery := createTestWebhook(t, db, receiver.URL)
+	deliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	subscriptionRepo := repository.NewWebhookSubscriptionRepository(db)
+	s := NewWebhookService(db, subscriptionRepo, deliveryRepo, webhooks.NewSender(), WebhookConfig{MaxAttempts: 10, DisableAfterFailures: 3})
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if err := s.deliver(ctx, subscription, delivery); err != nil {
+			t.Fatalf("attempt %d: deliver: %v", attempt, err)
+		}
+	}
+	fail.Store(false)
+	if err := s.deliver(ctx, subscription, delivery); err != nil {
+		t.Fatalf("deliver: %v", err)
+	}
+
+	saved, err := deliveryRepo.FindByID(ctx, delivery.ID)
+	if err != nil {
+		t.Fatalf("failed to reload delivery: %v", err)
+	}
+	if saved.Status != models.WebhookDeliveryStatusDelivered || saved.DeliveredAt == nil {
+		t.Errorf("status = %s, delivered at %v; want delivered", saved.Status, saved.DeliveredAt)
+	}
+	stored, err := subscriptionRepo.FindByID(ctx, subscription.ID)
+	if err != nil {
+		t.Fatalf("failed to reload subscription: %v", err)
+	}
+	if !stored.Active || stored.ConsecutiveFailures != 0 {
+		t.Errorf("active = %t, consecutive failures = %d; want active with none", stored.Active, stored.ConsecutiveFailures)
+	}
+}
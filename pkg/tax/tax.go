@@ -0,0 +1,127 @@
+// Package tax works out the value added tax in order totals. Medicine prices include
+// VAT, so the tax is taken out of each item's price after discounts rather than added on
+// top of it.
+package tax
+
+import (
+	"math"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+// Seller is the VAT-registered business that issues tax invoices.
+type Seller struct {
+	Name    string
+	TaxID   string
+	Branch  string
+	Address string
+}
+
+// HeadOfficeBranch is the branch number the Revenue Department gives a head office.
+const HeadOfficeBranch = "00000"
+
+// Item is an order item priced after discounts, VAT included.
+type Item struct {
+	OrderItemID uuid.UUID
+	Treatment   models.VATTreatment
+	Amount      float64
+}
+
+// Items prices the order items net of the discounts given on each of them. treatments
+// holds the VAT treatment of each medicine; medicines missing from it are taxed at the
+// standard rate.
+func Items(orderItems []models.OrderItem, discounts []models.OrderDiscount, treatments map[uuid.UUID]models.VATTreatment) []Item {
+	discountByItem := make(map[uuid.UUID]float64)
+	for _, discount := range discounts {
+		discountByItem[discount.OrderItemID] += discount.Amount
+	}
+	items := make([]Item, len(orderItems))
+	for i := range orderItems {
+		item := &orderItems[i]
+		treatment, ok := treatments[item.MedicineID]
+		if !ok {
+			treatment = models.VATTreatmentStandard
+		}
+		items[i] = Item{
+			OrderItemID: item.ID,
+			Treatment:   treatment,
+			Amount:      round(math.Max(round(item.LineTotal())-discountByItem[item.ID], 0)),
+		}
+	}
+	return items
+}
+
+// Line is the VAT included in one order item.
+type Line struct {
+	OrderItemID   uuid.UUID
+	Treatment     models.VATTreatment
+	Rate          float64
+	Amount        float64
+	TaxableAmount float64
+	VATAmount     float64
+}
+
+// Result is the VAT breakdown of an order. VatableAmount and ExemptAmount are net of VAT.
+type Result struct {
+	Lines         []Line
+	VatableAmount float64
+	ExemptAmount  float64
+	VATAmount     float64
+	Total         float64
+}
+
+// Calculate takes the VAT out of each item. The tax of a line is rounded to the satang
+// and the order's tax is the sum of its lines, so the lines always add up to the total.
+func Calculate(items []Item) Result {
+	var result Result
+	for _, item := range items {
+		rate := item.Treatment.Rate()
+		line := Line{
+			OrderItemID: item.OrderItemID,
+			Treatment:   item.Treatment,
+			Rate:        rate,
+			Amount:      item.Amount,
+		}
+		line.VATAmount = round(item.Amount * rate / (100 + rate))
+		line.TaxableAmount = round(item.Amount - line.VATAmount)
+
+		if rate > 0 {
+			result.VatableAmount += line.TaxableAmount
+		} else {
+			result.ExemptAmount += line.TaxableAmount
+		}
+		result.VATAmount += line.VATAmount
+		result.Total += line.Amount
+		result.Lines = append(result.Lines, line)
+	}
+	result.VatableAmount = round(result.VatableAmount)
+	result.ExemptAmount = round(result.ExemptAmount)
+	result.VATAmount = round(result.VATAmount)
+	result.Total = round(result.Total)
+	return result
+}
+
+// ValidTaxID reports whether id is a well-formed 13-digit Thai tax identification
+// number, including its check digit.
+func ValidTaxID(id string) bool {
+	if len(id) != 13 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 12; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return false
+		}
+		sum += int(id[i]-'0') * (13 - i)
+	}
+	if id[12] < '0' || id[12] > '9' {
+		return false
+	}
+	return (11-sum%11)%10 == int(id[12]-'0')
+}
+
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,152 @@
+package tax
+
+import (
+	"testing"
+
+	"order-service/pkg/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCalculateLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		treatment models.VATTreatment
+		amount    float64
+		taxable   float64
+		vat       float64
+	}{
+		{"whole baht of VAT", models.VATTreatmentStandard, 107, 100, 7},
+		{"VAT rounded down", models.VATTreatmentStandard, 100, 93.46, 6.54},
+		{"VAT rounded up", models.VATTreatmentStandard, 45, 42.06, 2.94},
+		{"VAT below a satang", models.VATTreatmentStandard, 0.07, 0.07, 0},
+		{"exempt", models.VATTreatmentExempt, 50, 50, 0},
+		{"free item", models.VATTreatmentStandard, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		result := Calculate([]Item{{Treatment: tt.treatment, Amount: tt.amount}})
+		if len(result.Lines) != 1 {
+			t.Fatalf("%s: got %d lines", tt.name, len(result.Lines))
+		}
+		line := result.Lines[0]
+		if line.TaxableAmount != tt.taxable || line.VATAmount != tt.vat {
+			t.Errorf("%s: %v splits into %v + %v VAT, want %v + %v", tt.name, tt.amount, line.TaxableAmount, line.VATAmount, tt.taxable, tt.vat)
+		}
+		if line.Rate != tt.treatment.Rate() {
+			t.Errorf("%s: rate = %v, want %v", tt.name, line.Rate, tt.treatment.Rate())
+		}
+		if line.TaxableAmount+line.VATAmount != line.Amount {
+			t.Errorf("%s: %v + %v does not add up to %v", tt.name, line.TaxableAmount, line.VATAmount, line.Amount)
+		}
+	}
+}
+
+func TestCalculateOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   []Item
+		vatable float64
+		exempt  float64
+		vat     float64
+		total   float64
+	}{
+		{
+			name:  "no items",
+			items: nil,
+		},
+		{
+			// 110 taxed as one amount would be 7.20 of VAT; the lines round to 6.54 and 0.65
+			name: "order VAT is the sum of rounded lines",
+			items: []Item{
+				{Treatment: models.VATTreatmentStandard, Amount: 100},
+				{Treatment: models.VATTreatmentStandard, Amount: 10},
+			},
+			vatable: 102.81, vat: 7.19, total: 110,
+		},
+		{
+			name: "all exempt",
+			items: []Item{
+				{Treatment: models.VATTreatmentExempt, Amount: 20},
+				{Treatment: models.VATTreatmentExempt, Amount: 30.5},
+			},
+			exempt: 50.5, total: 50.5,
+		},
+		{
+			name: "mixed treatments",
+			items: []Item{
+				{Treatment: models.VATTreatmentStandard, Amount: 100},
+				{Treatment: models.VATTreatmentExempt, Amount: 50},
+				{Treatment: models.VATTreatmentStandard, Amount: 10},
+			},
+			vatable: 102.81, exempt: 50, vat: 7.19, total: 160,
+		},
+	}
+	for _, tt := range tests {
+		result := Calculate(tt.items)
+		if result.VatableAmount != tt.vatable || result.ExemptAmount != tt.exempt || result.VATAmount != tt.vat || result.Total != tt.total {
+			t.Errorf("%s: vatable %v, exempt %v, VAT %v, total %v; want %v, %v, %v, %v", tt.name,
+				result.VatableAmount, result.ExemptAmount, result.VATAmount, result.Total,
+				tt.vatable, tt.exempt, tt.vat, tt.total)
+		}
+		if len(result.Lines) != len(tt.items) {
+			t.Errorf("%s: got %d lines, want %d", tt.name, len(result.Lines), len(tt.items))
+		}
+		if got := round(result.VatableAmount + result.ExemptAmount + result.VATAmount); got != result.Total {
+			t.Errorf("%s: breakdown adds up to %v, total is %v", tt.name, got, result.Total)
+		}
+	}
+}
+
+func TestItems(t *testing.T) {
+	taxed, exempt, unknown := uuid.New(), uuid.New(), uuid.New()
+	orderItems := []models.OrderItem{
+		{ID: uuid.New(), MedicineID: taxed, UnitPrice: 3.3333, Quantity: 3},
+		{ID: uuid.New(), MedicineID: exempt, UnitPrice: 40, Quantity: 2},
+		{ID: uuid.New(), MedicineID: unknown, UnitPrice: 5, Quantity: 1},
+	}
+	discounts := []models.OrderDiscount{
+		{OrderItemID: orderItems[1].ID, Amount: 15},
+		{OrderItemID: orderItems[1].ID, Amount: 5.25},
+		// a discount larger than the line leaves it free, not negative
+		{OrderItemID: orderItems[2].ID, Amount: 8},
+	}
+	treatments := map[uuid.UUID]models.VATTreatment{
+		taxed:  models.VATTreatmentStandard,
+		exempt: models.VATTreatmentExempt,
+	}
+
+	items := Items(orderItems, discounts, treatments)
+	want := []Item{
+		{OrderItemID: orderItems[0].ID, Treatment: models.VATTreatmentStandard, Amount: 10},
+		{OrderItemID: orderItems[1].ID, Treatment: models.VATTreatmentExempt, Amount: 59.75},
+		{OrderItemID: orderItems[2].ID, Treatment: models.VATTreatmentStandard, Amount: 0},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
+
+func TestValidTaxID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"0105555123450", true},
+		{"3101700123452", true},
+		{"0105555123451", false},
+		{"010555512345", false},
+		{"01055551234500", false},
+		{"01055551234a0", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidTaxID(tt.id); got != tt.valid {
+			t.Errorf("ValidTaxID(%q) = %t, want %t", tt.id, got, tt.valid)
+		}
+	}
+}
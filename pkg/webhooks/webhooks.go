@@ -0,0 +1,119 @@
+// Package webhooks sends signed event deliveries to partner endpoints.
+//
+// Every delivery is a POST of the event JSON with these headers:
+//
+//	X-Webhook-Id         the delivery ID, the same on every retry and replay
+//	X-Webhook-Event      the event type, e.g. order.paid
+//	X-Webhook-Timestamp  Unix seconds when the attempt was signed
+//	X-Webhook-Signature  v1=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret>
+//
+// Receivers should recompute the signature, compare it in constant time and reject
+// timestamps more than a few minutes old to stop replayed requests.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	// SendTimeout bounds one delivery attempt, from connecting to reading the response.
+	SendTimeout = 10 * time.Second
+
+	signatureVersion = "v1"
+	// at most this much of a failed response body is kept in the delivery log
+	maxErrorBody = 512
+)
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for body sent at timestamp.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Delivery is one signed request to an endpoint.
+type Delivery struct {
+	ID        string
+	EventType string
+	URL       string
+	Secret    string
+	Body      []byte
+}
+
+type Sender struct {
+	hc *http.Client
+}
+
+func NewSender() *Sender {
+	return &Sender{
+		hc: &http.Client{
+			Timeout: SendTimeout,
+		},
+	}
+}
+
+// Send posts the delivery and returns the response status code, or 0 when no response
+// was received. Any status outside 2xx is an error.
+func (s *Sender) Send(ctx context.Context, d Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "order-service-webhooks/1")
+	req.Header.Set(HeaderID, d.ID)
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, d.Body))
+
+	resp, err := s.hc.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("webhook returned status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff is how long to wait after the given number of failed attempts: 30 seconds,
+// doubling after each failure, up to six hours.
+func Backoff(attempts int) time.Duration {
+	const (
+		base    = 30 * time.Second
+		maxWait = 6 * time.Hour
+	)
+	wait := base
+	for i := 1; i < attempts && wait < maxWait; i++ {
+		wait *= 2
+	}
+	if wait > maxWait {
+		return maxWait
+	}
+	return wait
+}
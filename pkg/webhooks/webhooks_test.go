@@ -0,0 +1,134 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"type":"order.paid"}`)
+	timestamp := time.Now().Unix()
+
+	signature := Sign(secret, timestamp, body)
+	if !strings.HasPrefix(signature, "v1=") {
+		t.Fatalf("signature %q has no v1= prefix", signature)
+	}
+	if !Verify(secret, timestamp, body, signature) {
+		t.Fatal("valid signature rejected")
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		body      []byte
+	}{
+		{"other secret", "whsec_other", timestamp, body},
+		{"other timestamp", secret, timestamp + 1, body},
+		{"other body", secret, timestamp, []byte(`{"type":"order.refunded"}`)},
+	}
+	for _, tt := range tests {
+		if Verify(tt.secret, tt.timestamp, tt.body, signature) {
+			t.Errorf("%s: signature accepted", tt.name)
+		}
+	}
+}
+
+func TestSendSignsRequest(t *testing.T) {
+	delivery := Delivery{
+		ID:        "0192d4e0-0000-7000-8000-000000000001",
+		EventType: "order.paid",
+		Secret:    "whsec_test",
+		Body:      []byte(`{"type":"order.paid"}`),
+	}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if got := r.Header.Get(HeaderID); got != delivery.ID {
+			t.Errorf("%s = %q, want %q", HeaderID, got, delivery.ID)
+		}
+		if got := r.Header.Get(HeaderEvent); got != delivery.EventType {
+			t.Errorf("%s = %q, want %q", HeaderEvent, got, delivery.EventType)
+		}
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("invalid %s: %v", HeaderTimestamp, err)
+		}
+		if age := time.Since(time.Unix(timestamp, 0)); age < -time.Minute || age > time.Minute {
+			t.Errorf("%s is %s away from now", HeaderTimestamp, age)
+		}
+		if !Verify(delivery.Secret, timestamp, body, r.Header.Get(HeaderSignature)) {
+			t.Errorf("signature %q does not verify", r.Header.Get(HeaderSignature))
+		}
+		if string(body) != string(delivery.Body) {
+			t.Errorf("body = %s, want %s", body, delivery.Body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	delivery.URL = receiver.URL
+
+	statusCode, err := NewSender().Send(context.Background(), delivery)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Errorf("status code = %d, want %d", statusCode, http.StatusNoContent)
+	}
+}
+
+func TestSendFailsOnErrorStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	statusCode, err := NewSender().Send(context.Background(), Delivery{
+		ID:        "0192d4e0-0000-7000-8000-000000000002",
+		EventType: "order.paid",
+		URL:       receiver.URL,
+		Secret:    "whsec_test",
+		Body:      []byte(`{}`),
+	})
+	if err == nil {
+		t.Fatal("Send succeeded on a 503")
+	}
+	if statusCode != http.StatusServiceUnavailable {
+		t.Errorf("status code = %d, want %d", statusCode, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(err.Error(), "maintenance") {
+		t.Errorf("error %q does not include the response body", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{6, 16 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{100, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}